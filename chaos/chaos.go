@@ -0,0 +1,223 @@
+// Package chaos injects faults into data proxies, so migrations can be verified to survive real failure modes
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+)
+
+// Fault kinds, used to label injected faults in spans, logs, stats and events
+const (
+	FaultLatency   = "latency"
+	FaultError     = "error"
+	FaultTimeout   = "timeout"
+	FaultStoreBusy = "store_busy"
+	FaultKill      = "kill"
+	FaultPause     = "pause"
+)
+
+// AllMethods configures the faults of RPC methods without their own entry
+const AllMethods = "*"
+
+// ErrStoreBusy fails note store queries on purpose. It reads like the error of a locked SQLite
+// database, so stores retry it like a real one and only fail once their retries are exhausted.
+var ErrStoreBusy = errors.New("database is locked (SQLITE_BUSY), injected by chaos")
+
+// ErrInjected fails RPC calls on purpose
+var ErrInjected = errors.New("injected by chaos")
+
+// MethodFaults configures the faults injected into calls of an RPC method
+type MethodFaults struct {
+	Latency     telemetry.Duration `json:"latency,omitempty"`     // Added to every call
+	ErrorRate   float64            `json:"errorRate,omitempty"`   // Share of calls failed with an error
+	TimeoutRate float64            `json:"timeoutRate,omitempty"` // Share of calls that hang and are dropped without a response
+}
+
+// Config configures the faults data proxies inject. The zero value injects none.
+type Config struct {
+	Methods       map[string]MethodFaults `json:"methods,omitempty"`       // Keyed by RPC method, or AllMethods for all others
+	StoreBusyRate float64                 `json:"storeBusyRate,omitempty"` // Share of note store queries failed with SQLITE_BUSY
+}
+
+// Validate checks that all rates are shares and latencies are not negative
+func (c Config) Validate() error {
+	for method, faults := range c.Methods {
+		if faults.Latency < 0 {
+			return fmt.Errorf("latency of %s must not be negative", method)
+		}
+		if faults.ErrorRate < 0 || faults.TimeoutRate < 0 || faults.ErrorRate+faults.TimeoutRate > 1 {
+			return fmt.Errorf("error and timeout rates of %s must be between 0 and 1 combined", method)
+		}
+	}
+	if c.StoreBusyRate < 0 || c.StoreBusyRate > 1 {
+		return fmt.Errorf("store busy rate must be between 0 and 1, got %g", c.StoreBusyRate)
+	}
+	return nil
+}
+
+// Enabled returns true if the configuration injects any fault
+func (c Config) Enabled() bool {
+	for _, faults := range c.Methods {
+		if faults != (MethodFaults{}) {
+			return true
+		}
+	}
+	return c.StoreBusyRate > 0
+}
+
+// MethodFaults returns the faults configured for an RPC method, falling back to AllMethods
+func (c Config) MethodFaults(method string) MethodFaults {
+	if faults, ok := c.Methods[method]; ok {
+		return faults
+	}
+	return c.Methods[AllMethods]
+}
+
+// String summarizes the configuration, e.g. "GetNote: 5% errors, store busy 10%"
+func (c Config) String() string {
+	if !c.Enabled() {
+		return "off"
+	}
+
+	var parts []string
+	methods := make([]string, 0, len(c.Methods))
+	for method := range c.Methods {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	for _, method := range methods {
+		faults := c.Methods[method]
+		var injected []string
+		if faults.Latency > 0 {
+			injected = append(injected, fmt.Sprintf("+%s", time.Duration(faults.Latency)))
+		}
+		if faults.ErrorRate > 0 {
+			injected = append(injected, fmt.Sprintf("%g%% errors", faults.ErrorRate*100))
+		}
+		if faults.TimeoutRate > 0 {
+			injected = append(injected, fmt.Sprintf("%g%% timeouts", faults.TimeoutRate*100))
+		}
+		if len(injected) > 0 {
+			parts = append(parts, method+": "+strings.Join(injected, " "))
+		}
+	}
+	if c.StoreBusyRate > 0 {
+		parts = append(parts, fmt.Sprintf("store busy %g%%", c.StoreBusyRate*100))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Decision is the faults injected into one RPC call
+type Decision struct {
+	Latency time.Duration
+	Error   bool
+	Timeout bool
+}
+
+// Faults lists the kinds of the injected faults, empty if none were injected
+func (d Decision) Faults() []string {
+	var faults []string
+	if d.Latency > 0 {
+		faults = append(faults, FaultLatency)
+	}
+	if d.Error {
+		faults = append(faults, FaultError)
+	}
+	if d.Timeout {
+		faults = append(faults, FaultTimeout)
+	}
+	return faults
+}
+
+// Injector decides which faults a data proxy injects and counts them in its stats collector.
+// It is safe for concurrent use.
+type Injector struct {
+	proxyID        int
+	statsCollector telemetry.StatsCollector
+	rng            *util.Rand
+
+	mu     sync.RWMutex
+	config Config
+}
+
+// NewInjector creates an injector that injects no faults until configured
+func NewInjector(proxyID int, statsCollector telemetry.StatsCollector, rng *util.Rand) *Injector {
+	return &Injector{
+		proxyID:        proxyID,
+		statsCollector: statsCollector,
+		rng:            rng,
+	}
+}
+
+// Config returns the current configuration
+func (i *Injector) Config() Config {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.config
+}
+
+// SetConfig replaces the configuration after validating it
+func (i *Injector) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.config = config
+	return nil
+}
+
+// RPC decides the faults to inject into a call of an RPC method
+func (i *Injector) RPC(method string) Decision {
+	faults := i.Config().MethodFaults(method)
+
+	decision := Decision{Latency: time.Duration(faults.Latency)}
+	if faults.ErrorRate > 0 || faults.TimeoutRate > 0 {
+		draw := i.rng.Float64()
+		decision.Error = draw < faults.ErrorRate
+		decision.Timeout = !decision.Error && draw < faults.ErrorRate+faults.TimeoutRate
+	}
+
+	for _, fault := range decision.Faults() {
+		_ = i.statsCollector.TrackInjectedFault(fault, method, i.proxyID)
+	}
+	return decision
+}
+
+// StoreFault fails a query of the named note store with ErrStoreBusy at the configured rate
+func (i *Injector) StoreFault(store string) error {
+	rate := i.Config().StoreBusyRate
+	if rate == 0 || i.rng.Float64() >= rate {
+		return nil
+	}
+
+	_ = i.statsCollector.TrackInjectedFault(FaultStoreBusy, store, i.proxyID)
+	return ErrStoreBusy
+}
+
+// Preset is a named configuration, e.g. to cycle through in the TUI
+type Preset struct {
+	Name   string
+	Config Config
+}
+
+// Presets lists configurations for common failure modes, starting with injecting no faults
+var Presets = []Preset{
+	{Name: "off"},
+	{Name: "slow", Config: Config{Methods: map[string]MethodFaults{
+		AllMethods: {Latency: telemetry.Duration(50 * time.Millisecond)},
+	}}},
+	{Name: "flaky", Config: Config{Methods: map[string]MethodFaults{
+		AllMethods: {ErrorRate: 0.05, TimeoutRate: 0.01},
+	}}},
+	{Name: "busy", Config: Config{StoreBusyRate: 0.2}},
+}
@@ -0,0 +1,86 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInjector(t *testing.T, config Config) *Injector {
+	collector := telemetry.NewStatsCollector(telemetry.WithAutoStart(false))
+	t.Cleanup(collector.Stop)
+
+	injector := NewInjector(2, collector, util.NewRand(1, "chaos test"))
+	require.NoError(t, injector.SetConfig(config))
+	return injector
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+	require.NoError(t, Presets[2].Config.Validate())
+
+	require.Error(t, Config{Methods: map[string]MethodFaults{AllMethods: {Latency: -1}}}.Validate())
+	require.Error(t, Config{Methods: map[string]MethodFaults{"GetNote": {ErrorRate: 0.6, TimeoutRate: 0.5}}}.Validate())
+	require.Error(t, Config{StoreBusyRate: 1.5}.Validate())
+}
+
+func TestConfigMethodFaults(t *testing.T) {
+	config := Config{Methods: map[string]MethodFaults{
+		AllMethods: {Latency: telemetry.Duration(time.Millisecond)},
+		"GetNote":  {ErrorRate: 0.5},
+	}}
+
+	require.Equal(t, MethodFaults{ErrorRate: 0.5}, config.MethodFaults("GetNote"), "Expected the method's own faults")
+	require.Equal(t, MethodFaults{Latency: telemetry.Duration(time.Millisecond)}, config.MethodFaults("ListNotes"))
+	require.Equal(t, MethodFaults{}, Config{}.MethodFaults("ListNotes"))
+
+	require.Equal(t, "*: +1ms, GetNote: 50% errors", config.String())
+	require.Equal(t, "off", Config{}.String())
+	require.False(t, Config{Methods: map[string]MethodFaults{"GetNote": {}}}.Enabled())
+}
+
+func TestInjectorRPC(t *testing.T) {
+	injector := newTestInjector(t, Config{Methods: map[string]MethodFaults{
+		"GetNote":   {ErrorRate: 0.3, TimeoutRate: 0.2},
+		"ListNotes": {Latency: telemetry.Duration(time.Millisecond)},
+	}})
+
+	errors, timeouts := 0, 0
+	for range 1000 {
+		decision := injector.RPC("GetNote")
+		require.False(t, decision.Error && decision.Timeout, "Expected a call to either fail or time out")
+		if decision.Error {
+			errors++
+		}
+		if decision.Timeout {
+			timeouts++
+		}
+	}
+	require.InDelta(t, 300, errors, 60)
+	require.InDelta(t, 200, timeouts, 60)
+
+	decision := injector.RPC("ListNotes")
+	require.Equal(t, []string{FaultLatency}, decision.Faults())
+	require.Empty(t, injector.RPC("CreateNote").Faults(), "Expected no faults for methods without configuration")
+
+	faults := injector.statsCollector.Export().InjectedFaults
+	require.Equal(t, errors, faults["error-GetNote-2"].Count)
+	require.Equal(t, timeouts, faults["timeout-GetNote-2"].Count)
+	require.Equal(t, 1, faults["latency-ListNotes-2"].Count)
+}
+
+func TestInjectorStoreFault(t *testing.T) {
+	injector := newTestInjector(t, Config{})
+	require.NoError(t, injector.StoreFault("legacy"))
+
+	require.NoError(t, injector.SetConfig(Config{StoreBusyRate: 1}))
+	require.ErrorIs(t, injector.StoreFault("legacy"), ErrStoreBusy)
+	require.Contains(t, ErrStoreBusy.Error(), "SQLITE_BUSY", "Expected stores to retry injected errors")
+	require.Equal(t, 1, injector.statsCollector.Export().InjectedFaults["store_busy-legacy-2"].Count)
+
+	require.Error(t, injector.SetConfig(Config{StoreBusyRate: -1}))
+	require.Equal(t, 1.0, injector.Config().StoreBusyRate, "Expected invalid configurations to be rejected")
+}
@@ -0,0 +1,286 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+)
+
+// PartitionMode is how the network between the API and a data proxy misbehaves
+type PartitionMode string
+
+// Partition modes, also used to label injected faults
+const (
+	PartitionBlackhole PartitionMode = "blackhole" // Requests never reach the proxy and hang until dropped
+	PartitionDelay     PartitionMode = "delay"     // Requests reach the proxy after a fixed delay
+	PartitionReorder   PartitionMode = "reorder"   // Requests are held for a random delay, so later requests overtake earlier ones
+	PartitionDuplicate PartitionMode = "duplicate" // Requests reach the proxy a second time after they completed
+)
+
+// PartitionModes lists all partition modes
+var PartitionModes = []PartitionMode{PartitionBlackhole, PartitionDelay, PartitionReorder, PartitionDuplicate}
+
+// ErrPartitioned fails requests to a proxy the API is partitioned from
+var ErrPartitioned = errors.New("partitioned by chaos")
+
+// Partition configures how requests from the API to a data proxy are faulted
+type Partition struct {
+	Mode  PartitionMode      `json:"mode"`
+	Delay telemetry.Duration `json:"delay,omitempty"` // Delay of requests, or the longest delay when reordering
+	Rate  float64            `json:"rate,omitempty"`  // Share of requests affected, defaults to all
+}
+
+// Validate checks that the mode is known and delayed modes have a delay
+func (p Partition) Validate() error {
+	if !slices.Contains(PartitionModes, p.Mode) {
+		return fmt.Errorf("unknown partition mode %q", p.Mode)
+	}
+	if p.Delay < 0 {
+		return errors.New("delay must not be negative")
+	}
+	if (p.Mode == PartitionDelay || p.Mode == PartitionReorder) && p.Delay == 0 {
+		return fmt.Errorf("%s requires a delay", p.Mode)
+	}
+	if p.Rate < 0 || p.Rate > 1 {
+		return fmt.Errorf("rate must be between 0 and 1, got %g", p.Rate)
+	}
+	return nil
+}
+
+// String summarizes the partition, e.g. "reorder up to 100ms (50%)"
+func (p Partition) String() string {
+	var summary string
+	switch p.Mode {
+	case PartitionDelay:
+		summary = fmt.Sprintf("delay %s", time.Duration(p.Delay))
+	case PartitionReorder:
+		summary = fmt.Sprintf("reorder up to %s", time.Duration(p.Delay))
+	default:
+		summary = string(p.Mode)
+	}
+	if p.Rate > 0 && p.Rate < 1 {
+		summary += fmt.Sprintf(" (%g%%)", p.Rate*100)
+	}
+	return summary
+}
+
+// Partitions configures the network between the API and data proxies, keyed by proxy ID.
+// Proxies without an entry are reachable as usual.
+type Partitions map[int]Partition
+
+// Validate checks all partitions
+func (p Partitions) Validate() error {
+	for proxyID, partition := range p {
+		if proxyID <= 0 {
+			return fmt.Errorf("invalid proxy ID %d", proxyID)
+		}
+		if err := partition.Validate(); err != nil {
+			return fmt.Errorf("proxy v%d: %w", proxyID, err)
+		}
+	}
+	return nil
+}
+
+// String summarizes the partitions, e.g. "v1: blackhole, v2: delay 50ms"
+func (p Partitions) String() string {
+	if len(p) == 0 {
+		return "none"
+	}
+
+	parts := make([]string, 0, len(p))
+	for _, proxyID := range slices.Sorted(maps.Keys(p)) {
+		parts = append(parts, fmt.Sprintf("v%d: %s", proxyID, p[proxyID]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Selectors of the proxy to partition, resolved when the partition is configured. Proxies can also be selected by ID.
+const (
+	CurrentProxy  = "current"
+	PreviousProxy = "previous"
+)
+
+type faultTargetKey struct{}
+
+// WithFaultTarget labels the faults the network injects into requests of the context, e.g. with the RPC method
+func WithFaultTarget(ctx context.Context, target string) context.Context {
+	return context.WithValue(ctx, faultTargetKey{}, target)
+}
+
+// networkFaultTarget labels faults of requests without a fault target
+const networkFaultTarget = "network"
+
+func faultTarget(ctx context.Context) string {
+	if target, ok := ctx.Value(faultTargetKey{}).(string); ok {
+		return target
+	}
+	return networkFaultTarget
+}
+
+// Network injects partitions into the requests the API sends to data proxies and counts them in its stats collector.
+// It is safe for concurrent use.
+type Network struct {
+	statsCollector telemetry.StatsCollector
+	rng            *util.Rand
+
+	mu         sync.RWMutex
+	partitions Partitions
+}
+
+// NewNetwork creates a network without partitions
+func NewNetwork(statsCollector telemetry.StatsCollector, rng *util.Rand) *Network {
+	return &Network{
+		statsCollector: statsCollector,
+		rng:            rng,
+	}
+}
+
+// Partitions returns a copy of the current partitions
+func (n *Network) Partitions() Partitions {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return maps.Clone(n.partitions)
+}
+
+// SetPartitions replaces all partitions after validating them, an empty set heals the network
+func (n *Network) SetPartitions(partitions Partitions) error {
+	if err := partitions.Validate(); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitions = maps.Clone(partitions)
+	return nil
+}
+
+// SetPartition partitions the API from a single proxy after validating the partition, leaving other partitions in place
+func (n *Network) SetPartition(proxyID int, partition Partition) error {
+	if err := (Partitions{proxyID: partition}).Validate(); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.partitions == nil {
+		n.partitions = Partitions{}
+	}
+	n.partitions[proxyID] = partition
+	return nil
+}
+
+// Partitioned returns the partition of a proxy, if requests to it are currently faulted
+func (n *Network) Partitioned(proxyID int) (Partition, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	partition, ok := n.partitions[proxyID]
+	return partition, ok
+}
+
+// Transport wraps the transport of a proxy client, injecting the partitions configured for the proxy.
+// Partitions are looked up for every request, so they apply to clients created before they were configured.
+func (n *Network) Transport(proxyID int, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &partitionTransport{network: n, proxyID: proxyID, base: base}
+}
+
+// partitionTransport injects the partitions of one proxy into requests sent over the base transport
+type partitionTransport struct {
+	network *Network
+	proxyID int
+	base    http.RoundTripper
+}
+
+// RoundTrip sends the request unless the proxy is blackholed, delaying or duplicating it as configured
+func (t *partitionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	partition, ok := t.network.Partitioned(t.proxyID)
+	if !ok || (partition.Rate > 0 && t.network.rng.Float64() >= partition.Rate) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	_ = t.network.statsCollector.TrackInjectedFault(string(partition.Mode), faultTarget(ctx), t.proxyID)
+	telemetry.SpanFromContext(ctx).SetAttribute("chaos.partition", string(partition.Mode))
+
+	switch partition.Mode {
+	case PartitionBlackhole:
+		_ = sleep(ctx, constants.ChaosTimeout)
+		closeRequestBody(req)
+		return nil, fmt.Errorf("request to proxy v%d dropped: %w", t.proxyID, ErrPartitioned)
+	case PartitionDelay:
+		if err := sleep(ctx, time.Duration(partition.Delay)); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	case PartitionReorder:
+		if err := sleep(ctx, time.Duration(t.network.rng.Float64()*float64(partition.Delay))); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	case PartitionDuplicate:
+		resp, err := t.base.RoundTrip(req)
+		if err == nil {
+			t.duplicate(req)
+		}
+		return resp, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// closeRequestBody closes the body of a request that is not sent, as a RoundTripper must always close it
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// duplicate sends a copy of a request that completed in the background, discarding its response
+func (t *partitionTransport) duplicate(req *http.Request) {
+	if req.Body != nil && req.GetBody == nil {
+		return
+	}
+
+	clone := req.Clone(context.WithoutCancel(req.Context()))
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return
+		}
+		clone.Body = body
+	}
+
+	go func() {
+		resp, err := t.base.RoundTrip(clone)
+		if err != nil {
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+}
+
+// sleep waits for the duration unless the context is done first
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
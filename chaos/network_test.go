@@ -0,0 +1,146 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNetwork returns a network and a client of proxy 1 sending requests to a server counting the notes it received
+func newTestNetwork(t *testing.T) (*Network, *http.Client, string, *atomic.Int32) {
+	collector := telemetry.NewStatsCollector(telemetry.WithAutoStart(false))
+	t.Cleanup(collector.Stop)
+
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "note" {
+			received.Add(1)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	network := NewNetwork(collector, util.NewRand(1, "network test"))
+	client := &http.Client{Transport: network.Transport(1, nil)}
+	return network, client, server.URL, &received
+}
+
+func TestPartitionsValidate(t *testing.T) {
+	require.NoError(t, Partitions{}.Validate())
+	require.NoError(t, Partitions{1: {Mode: PartitionBlackhole}, 2: {Mode: PartitionReorder, Delay: telemetry.Duration(time.Millisecond), Rate: 0.5}}.Validate())
+
+	require.Error(t, Partitions{0: {Mode: PartitionBlackhole}}.Validate())
+	require.Error(t, Partitions{1: {Mode: "flood"}}.Validate())
+	require.Error(t, Partitions{1: {Mode: PartitionDelay}}.Validate(), "Expected delays to be required")
+	require.Error(t, Partitions{1: {Mode: PartitionDuplicate, Rate: 2}}.Validate())
+
+	require.Equal(t, "none", Partitions{}.String())
+	require.Equal(t, "v1: blackhole, v2: reorder up to 1ms (50%)", Partitions{
+		2: {Mode: PartitionReorder, Delay: telemetry.Duration(time.Millisecond), Rate: 0.5},
+		1: {Mode: PartitionBlackhole},
+	}.String())
+}
+
+func TestNetworkBlackhole(t *testing.T) {
+	network, client, url, received := newTestNetwork(t)
+	require.NoError(t, network.SetPartitions(Partitions{1: {Mode: PartitionBlackhole}}))
+
+	ctx, cancel := context.WithTimeout(WithFaultTarget(context.Background(), "GetNote"), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader("note"))
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.ErrorIs(t, err, ErrPartitioned)
+	require.Zero(t, received.Load(), "Expected blackholed requests to never reach the proxy")
+	require.Equal(t, 1, network.statsCollector.Export().InjectedFaults["blackhole-GetNote-1"].Count)
+
+	// Other proxies and healed networks are reachable
+	require.NoError(t, network.SetPartitions(Partitions{2: {Mode: PartitionBlackhole}}))
+	resp, err := client.Post(url, "text/plain", strings.NewReader("note"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, 1, received.Load())
+}
+
+func TestNetworkSetPartition(t *testing.T) {
+	network, _, _, _ := newTestNetwork(t)
+	require.NoError(t, network.SetPartition(2, Partition{Mode: PartitionBlackhole}))
+	require.NoError(t, network.SetPartition(1, Partition{Mode: PartitionDelay, Delay: telemetry.Duration(time.Millisecond)}))
+	require.Equal(t, "v1: delay 1ms, v2: blackhole", network.Partitions().String(), "Expected other partitions to stay in place")
+
+	require.Error(t, network.SetPartition(0, Partition{Mode: PartitionBlackhole}))
+	require.Error(t, network.SetPartition(3, Partition{Mode: PartitionDelay}))
+	require.Len(t, network.Partitions(), 2)
+}
+
+// closeTracker is a request body that records whether it was closed
+type closeTracker struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestNetworkClosesBodiesOfDroppedRequests(t *testing.T) {
+	partitions := []Partition{
+		{Mode: PartitionBlackhole},
+		{Mode: PartitionDelay, Delay: telemetry.Duration(time.Minute)},
+		{Mode: PartitionReorder, Delay: telemetry.Duration(time.Minute)},
+	}
+
+	for _, partition := range partitions {
+		t.Run(string(partition.Mode), func(t *testing.T) {
+			network, _, url, received := newTestNetwork(t)
+			require.NoError(t, network.SetPartition(1, partition))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			body := &closeTracker{Reader: strings.NewReader("note")}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+			require.NoError(t, err)
+
+			_, err = network.Transport(1, nil).RoundTrip(req)
+			require.Error(t, err)
+			require.True(t, body.closed.Load(), "Expected the body of a request that was not sent to be closed")
+			require.Zero(t, received.Load())
+		})
+	}
+}
+
+func TestNetworkDelayAndDuplicate(t *testing.T) {
+	network, client, url, received := newTestNetwork(t)
+	require.NoError(t, network.SetPartitions(Partitions{1: {Mode: PartitionDelay, Delay: telemetry.Duration(20 * time.Millisecond)}}))
+
+	start := time.Now()
+	resp, err := client.Post(url, "text/plain", strings.NewReader("note"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	require.NoError(t, network.SetPartitions(Partitions{1: {Mode: PartitionDuplicate}}))
+	resp, err = client.Post(url, "text/plain", strings.NewReader("note"))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, "note", string(body), "Expected the original response")
+
+	require.Eventually(t, func() bool { return received.Load() == 3 }, time.Second, 5*time.Millisecond,
+		"Expected the duplicate to reach the proxy with the same body")
+	faults := network.statsCollector.Export().InjectedFaults
+	require.Equal(t, 1, faults["delay-network-1"].Count)
+	require.Equal(t, 1, faults["duplicate-network-1"].Count)
+}
@@ -17,6 +17,9 @@ type AppConfig struct {
 
 type CLIOptions struct {
 	Theme string
+
+	// Replay plays back recorded stats instead of showing live stats
+	Replay *telemetry.ReplayStatsCollector
 }
 
 // RunCLI starts the CLI application with the given stores, telemetry, and options
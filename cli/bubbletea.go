@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
 )
 
 // Model represents the main TUI model
@@ -37,12 +39,16 @@ type Model struct {
 	apiTable       table.Model
 	dataStoreTable table.Model
 	accountsTable  table.Model
+	tracesTable    table.Model
+	missesTable    table.Model
 	logsViewport   viewport.Model
 
 	// Table column definitions for dynamic resizing
 	apiColumns       []table.Column
 	dataStoreColumns []table.Column
 	accountsColumns  []table.Column
+	tracesColumns    []table.Column
+	missesColumns    []table.Column
 
 	// Help component
 	help help.Model
@@ -60,9 +66,40 @@ type Model struct {
 	lastStatsUpdate    time.Time
 	lastShardUpdate    time.Time
 	lastAccountsUpdate time.Time
+	lastTracesUpdate   time.Time
+	lastMissesUpdate   time.Time
 
 	// Account data
 	accountsList []store.AccountStats
+
+	// Trace data
+	traceRoots      []telemetry.SpanData
+	selectedTraceID telemetry.TraceID
+	traceSpans      []telemetry.SpanData
+
+	// Consistency miss data
+	misses       []telemetry.ConsistencyMiss
+	selectedMiss telemetry.ConsistencyMiss
+
+	// Log data and filters: minimum level, source and text, e.g. a request ID
+	logChan          chan telemetry.LogEntry
+	logMinLevel      slog.Level
+	logSource        string
+	logFilter        string
+	logFilterInput   string
+	editingLogFilter bool
+	logsPaused       bool
+
+	// Whether a stats recording is being played back
+	replayPlaying bool
+
+	// Index of the chaos preset last configured from the TUI
+	chaosPreset int
+
+	// Rate windows shown by the API, data store and proxy stats tables
+	apiRateWindow       rateWindow
+	dataStoreRateWindow rateWindow
+	proxyRateWindow     rateWindow
 }
 
 // BubbleTeaTheme defines color schemes for the bubbletea interface
@@ -109,28 +146,50 @@ var (
 
 // Key bindings
 type keyMap struct {
-	page             int
-	Deploy           key.Binding
-	ToggleMigrate    key.Binding
-	ToggleMigrateAll key.Binding
-	CycleShard       key.Binding
-	Quit             key.Binding
-	ScrollUp         key.Binding
-	ScrollDown       key.Binding
-	PageUp           key.Binding
-	PageDown         key.Binding
-	NextPage         key.Binding
-	PrevPage         key.Binding
+	page              int
+	Deploy            key.Binding
+	AdvanceMigration  key.Binding
+	RollbackMigration key.Binding
+	CycleShard        key.Binding
+	Quit              key.Binding
+	ScrollUp          key.Binding
+	ScrollDown        key.Binding
+	PageUp            key.Binding
+	PageDown          key.Binding
+	NextPage          key.Binding
+	PrevPage          key.Binding
+	FilterLogs        key.Binding
+	LogLevel          key.Binding
+	LogSource         key.Binding
+	PauseLogs         key.Binding
+	ShowRequestLogs   key.Binding
+	ReplayBack        key.Binding
+	ReplayForward     key.Binding
+	ReplayPrevEvent   key.Binding
+	ReplayNextEvent   key.Binding
+	ReplayPlay        key.Binding
+	APIRateWindow     key.Binding
+	StoreRateWindow   key.Binding
+	ProxyRateWindow   key.Binding
+	CycleChaos        key.Binding
+	KillProxy         key.Binding
+	PauseProxy        key.Binding
+	replay            bool
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
 	switch k.page {
 	case 0:
-		return []key.Binding{k.PrevPage, k.NextPage, k.Deploy, k.Quit}
+		if k.replay {
+			return []key.Binding{k.PrevPage, k.NextPage, k.ReplayBack, k.ReplayForward, k.ReplayPrevEvent, k.ReplayNextEvent, k.ReplayPlay, k.APIRateWindow, k.StoreRateWindow, k.ProxyRateWindow, k.Quit}
+		}
+		return []key.Binding{k.PrevPage, k.NextPage, k.Deploy, k.CycleChaos, k.KillProxy, k.PauseProxy, k.APIRateWindow, k.StoreRateWindow, k.ProxyRateWindow, k.Quit}
 	case 1:
-		return []key.Binding{k.PrevPage, k.NextPage, k.ScrollUp, k.ScrollDown, k.ToggleMigrate, k.ToggleMigrateAll, k.CycleShard, k.Quit}
+		return []key.Binding{k.PrevPage, k.NextPage, k.ScrollUp, k.ScrollDown, k.AdvanceMigration, k.RollbackMigration, k.CycleShard, k.Quit}
 	case 2:
-		return []key.Binding{k.PrevPage, k.NextPage, k.ScrollUp, k.ScrollDown, k.PageUp, k.PageDown, k.Quit}
+		return []key.Binding{k.PrevPage, k.NextPage, k.ScrollUp, k.ScrollDown, k.PageUp, k.PageDown, k.FilterLogs, k.LogLevel, k.LogSource, k.PauseLogs, k.Quit}
+	case 3, 4:
+		return []key.Binding{k.PrevPage, k.NextPage, k.ScrollUp, k.ScrollDown, k.ShowRequestLogs, k.Quit}
 	default:
 		return []key.Binding{}
 	}
@@ -145,13 +204,13 @@ var keys = keyMap{
 		key.WithKeys("d"),
 		key.WithHelp("d", "deploy"),
 	),
-	ToggleMigrate: key.NewBinding(
+	AdvanceMigration: key.NewBinding(
 		key.WithKeys("m"),
-		key.WithHelp("m", "toggle migration"),
+		key.WithHelp("m", "advance migration"),
 	),
-	ToggleMigrateAll: key.NewBinding(
+	RollbackMigration: key.NewBinding(
 		key.WithKeys("M"),
-		key.WithHelp("M", "toggle migration on all accounts"),
+		key.WithHelp("M", "roll back migration"),
 	),
 	CycleShard: key.NewBinding(
 		key.WithKeys("s"),
@@ -181,6 +240,70 @@ var keys = keyMap{
 		key.WithKeys("h", "left"),
 		key.WithHelp("h/←", "prev page"),
 	),
+	FilterLogs: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	LogLevel: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "level"),
+	),
+	LogSource: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "source"),
+	),
+	PauseLogs: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pause/follow"),
+	),
+	ShowRequestLogs: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "request logs"),
+	),
+	ReplayBack: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "back"),
+	),
+	ReplayForward: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "forward"),
+	),
+	ReplayPrevEvent: key.NewBinding(
+		key.WithKeys("{"),
+		key.WithHelp("{", "prev event"),
+	),
+	ReplayNextEvent: key.NewBinding(
+		key.WithKeys("}"),
+		key.WithHelp("}", "next event"),
+	),
+	ReplayPlay: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "play/pause"),
+	),
+	APIRateWindow: key.NewBinding(
+		key.WithKeys("1"),
+		key.WithHelp("1", "api rate"),
+	),
+	StoreRateWindow: key.NewBinding(
+		key.WithKeys("2"),
+		key.WithHelp("2", "store rate"),
+	),
+	ProxyRateWindow: key.NewBinding(
+		key.WithKeys("3"),
+		key.WithHelp("3", "proxy rate"),
+	),
+	CycleChaos: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "chaos preset"),
+	),
+	KillProxy: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "kill proxy"),
+	),
+	PauseProxy: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "pause proxy"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c", "esc"),
 		key.WithHelp("q", "quit"),
@@ -199,8 +322,11 @@ func NewBubbleTeaModel(appConfig *AppConfig, options CLIOptions) *Model {
 		{Title: "Route", Width: 20},
 		{Title: "Status", Width: 6},
 		{Title: "Total", Width: 8},
-		{Title: "RPM", Width: 6},
-		{Title: "P95ms", Width: 8},
+		{Title: rateWindowOneMinute.columnTitle(), Width: rateColumnWidth},
+		{Title: "P50ms", Width: 6},
+		{Title: "P90ms", Width: 6},
+		{Title: "P99ms", Width: 6},
+		{Title: "Maxms", Width: 6},
 	}
 
 	// Create table styles for API table
@@ -229,8 +355,11 @@ func NewBubbleTeaModel(appConfig *AppConfig, options CLIOptions) *Model {
 		{Title: "Operation", Width: 12},
 		{Title: "Status", Width: 6},
 		{Title: "Total", Width: 8},
-		{Title: "RPM", Width: 6},
-		{Title: "P95ms", Width: 8},
+		{Title: rateWindowOneMinute.columnTitle(), Width: rateColumnWidth},
+		{Title: "P50ms", Width: 6},
+		{Title: "P90ms", Width: 6},
+		{Title: "P99ms", Width: 6},
+		{Title: "Maxms", Width: 6},
 	}
 
 	// Create table styles for data store table
@@ -266,7 +395,8 @@ func NewBubbleTeaModel(appConfig *AppConfig, options CLIOptions) *Model {
 	accountsColumns := []table.Column{
 		{Title: "ID", Width: 12},
 		{Title: "Name", Width: 20},
-		{Title: "IsMigrating", Width: 12},
+		{Title: "Migration", Width: 14},
+		{Title: "Since", Width: 8},
 		{Title: "Shard", Width: 10},
 		{Title: "Note Count", Width: 10},
 	}
@@ -291,10 +421,26 @@ func NewBubbleTeaModel(appConfig *AppConfig, options CLIOptions) *Model {
 		table.WithStyles(accountsTableStyles),
 	)
 
+	// Initialize traces table, columns are sized when the page is rendered
+	tracesTable := table.New(
+		table.WithFocused(true),
+		table.WithHeight(10),
+		table.WithStyles(accountsTableStyles),
+	)
+
+	// Initialize consistency misses table, columns are sized when the page is rendered
+	missesTable := table.New(
+		table.WithFocused(true),
+		table.WithHeight(10),
+		table.WithStyles(accountsTableStyles),
+	)
+
 	// Initialize paginator
 	p := paginator.New()
 	p.Type = paginator.Dots
-	p.SetTotalPages(3)
+	p.SetTotalPages(5)
+
+	keys.replay = options.Replay != nil
 
 	return &Model{
 		appConfig:        appConfig,
@@ -304,6 +450,8 @@ func NewBubbleTeaModel(appConfig *AppConfig, options CLIOptions) *Model {
 		apiTable:         apiTable,
 		dataStoreTable:   dataStoreTable,
 		accountsTable:    accountsTable,
+		tracesTable:      tracesTable,
+		missesTable:      missesTable,
 		help:             helpModel,
 		progressBar:      progressModel,
 		paginator:        p,
@@ -339,7 +487,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// While typing a log filter, keys edit the filter instead of triggering actions
+		if m.editingLogFilter && msg.Type != tea.KeyCtrlC {
+			m.handleLogFilterKey(msg)
+			return m, nil
+		}
+
 		switch {
+		case m.paginator.Page == 2 && m.logFilter != "" && msg.Type == tea.KeyEsc:
+			// Clear the log filter instead of quitting
+			m.setLogFilter("")
+			return m, nil
 		case key.Matches(msg, keys.Quit):
 			m.cancel()
 			return m, tea.Quit
@@ -349,6 +507,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				go m.appConfig.DeploymentController.Deploy()
 			}
 			return m, nil
+		case key.Matches(msg, keys.CycleChaos):
+			if m.paginator.Page == 0 && m.options.Replay == nil && m.appConfig.DeploymentController != nil {
+				m.cycleChaosPreset()
+			}
+			return m, nil
+		case key.Matches(msg, keys.KillProxy):
+			if m.paginator.Page == 0 && m.options.Replay == nil && m.appConfig.DeploymentController != nil {
+				m.killProxy()
+			}
+			return m, nil
+		case key.Matches(msg, keys.PauseProxy):
+			if m.paginator.Page == 0 && m.options.Replay == nil && m.appConfig.DeploymentController != nil {
+				m.pauseProxy()
+			}
+			return m, nil
 		case key.Matches(msg, keys.NextPage):
 			// Manual wrap-around: if at last page, go to first
 			if m.paginator.Page >= m.paginator.TotalPages-1 {
@@ -374,6 +547,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if m.paginator.Page == 2 {
 				// Logs page - scroll up
 				m.logsViewport.ScrollUp(1)
+			} else if m.paginator.Page == 3 {
+				// Traces page - select previous request
+				m.tracesTable.MoveUp(1)
+				m.loadSelectedTrace()
+			} else if m.paginator.Page == 4 {
+				// Consistency misses page - select previous miss
+				m.missesTable.MoveUp(1)
+				m.selectConsistencyMiss()
 			}
 			return m, nil
 		case key.Matches(msg, keys.ScrollDown):
@@ -383,55 +564,41 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else if m.paginator.Page == 2 {
 				// Logs page - scroll down
 				m.logsViewport.ScrollDown(1)
+			} else if m.paginator.Page == 3 {
+				// Traces page - select next request
+				m.tracesTable.MoveDown(1)
+				m.loadSelectedTrace()
+			} else if m.paginator.Page == 4 {
+				// Consistency misses page - select next miss
+				m.missesTable.MoveDown(1)
+				m.selectConsistencyMiss()
 			}
 			return m, nil
-		case key.Matches(msg, keys.ToggleMigrate):
-			// Toggle migration status on accounts page
-			if m.paginator.Page == 1 {
-				currentIndex := m.accountsTable.Cursor()
-				if len(m.accountsList) > 0 && currentIndex >= 0 && currentIndex < len(m.accountsList) {
-					currentAccount := m.accountsList[currentIndex].Account
-					currentAccount.IsMigrating = !currentAccount.IsMigrating
-
-					// Update the account in the store
-					if m.appConfig.AccountStore != nil {
-						ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-						defer cancel()
-						err := m.appConfig.AccountStore.UpdateAccount(ctx, currentAccount)
-						if err == nil {
-							// Force immediate refresh
-							m.updateAccountsStats()
-						}
-					}
+		case key.Matches(msg, keys.AdvanceMigration):
+			// Advance migration of the selected account by one phase
+			if m.paginator.Page == 1 && m.appConfig.DeploymentController != nil {
+				if currentAccount, ok := m.selectedAccount(); ok {
+					return m, m.migrationCmd(m.appConfig.DeploymentController.AdvanceMigration, "Could not advance migration", currentAccount)
 				}
 			}
 			return m, nil
-		case key.Matches(msg, keys.ToggleMigrateAll):
-			// Toggle migration status on accounts page
-			if m.paginator.Page == 1 {
-				if len(m.accountsList) > 0 && m.appConfig.AccountStore != nil {
-					for _, accountStats := range m.accountsList {
-						account := accountStats.Account
-						account.IsMigrating = !account.IsMigrating
-
-						ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-						defer cancel()
-						err := m.appConfig.AccountStore.UpdateAccount(ctx, account)
-						if err == nil {
-							// Force immediate refresh
-							m.updateAccountsStats()
-						}
-
-					}
+		case key.Matches(msg, keys.RollbackMigration):
+			// Roll back migration of the selected account by one phase
+			if m.paginator.Page == 1 && m.appConfig.DeploymentController != nil {
+				if currentAccount, ok := m.selectedAccount(); ok {
+					return m, m.migrationCmd(m.appConfig.DeploymentController.RollbackMigration, "Could not roll back migration", currentAccount)
 				}
 			}
 			return m, nil
 		case key.Matches(msg, keys.CycleShard):
 			// Cycle shard on accounts page
 			if m.paginator.Page == 1 {
-				currentIndex := m.accountsTable.Cursor()
-				if len(m.accountsList) > 0 && currentIndex >= 0 && currentIndex < len(m.accountsList) {
-					currentAccount := m.accountsList[currentIndex].Account
+				if currentAccount, ok := m.selectedAccount(); ok {
+					// Changing the target shard mid-migration would strand notes on the previous shard
+					if currentAccount.MigrationState.Normalize() != store.MigrationStateNotStarted {
+						m.logAccountError("Could not cycle shard", currentAccount, fmt.Errorf("migration state is %s", currentAccount.MigrationState))
+						return m, nil
+					}
 
 					// Find current shard index (-1 for null/no shard)
 					currentShardIndex := -1
@@ -449,11 +616,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if nextShardIndex >= len(constants.Shards) {
 						// Cycle back to null (no shard)
 						currentAccount.Shard = nil
-						currentAccount.IsMigrating = true
 					} else {
 						nextShard := constants.Shards[nextShardIndex]
 						currentAccount.Shard = &nextShard
-						currentAccount.IsMigrating = true
 					}
 
 					// Update the account in the store
@@ -481,12 +646,87 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.logsViewport.HalfPageDown()
 			}
 			return m, nil
+		case key.Matches(msg, keys.FilterLogs):
+			// Start typing a filter on the logs page
+			if m.paginator.Page == 2 {
+				m.editingLogFilter = true
+				m.logFilterInput = m.logFilter
+			}
+			return m, nil
+		case key.Matches(msg, keys.LogLevel):
+			// Cycle the minimum level of shown logs
+			if m.paginator.Page == 2 {
+				m.cycleLogLevel()
+			}
+			return m, nil
+		case key.Matches(msg, keys.LogSource):
+			// Cycle the source of shown logs
+			if m.paginator.Page == 2 {
+				m.cycleLogSource()
+			}
+			return m, nil
+		case key.Matches(msg, keys.PauseLogs):
+			// Stop or resume following new logs
+			if m.paginator.Page == 2 {
+				m.toggleLogsPaused()
+			}
+			return m, nil
+		case m.options.Replay != nil && key.Matches(msg, keys.ReplayBack):
+			m.replayPlaying = false
+			m.seekReplay(-1)
+			return m, nil
+		case m.options.Replay != nil && key.Matches(msg, keys.ReplayForward):
+			m.replayPlaying = false
+			m.seekReplay(1)
+			return m, nil
+		case m.options.Replay != nil && key.Matches(msg, keys.ReplayPrevEvent):
+			m.replayPlaying = false
+			m.seekReplayEvent(-1)
+			return m, nil
+		case m.options.Replay != nil && key.Matches(msg, keys.ReplayNextEvent):
+			m.replayPlaying = false
+			m.seekReplayEvent(1)
+			return m, nil
+		case m.options.Replay != nil && key.Matches(msg, keys.ReplayPlay):
+			m.replayPlaying = !m.replayPlaying
+			return m, nil
+		case key.Matches(msg, keys.APIRateWindow):
+			if m.paginator.Page == 0 {
+				m.apiRateWindow = m.apiRateWindow.next()
+				m.updateAPIStats()
+			}
+			return m, nil
+		case key.Matches(msg, keys.StoreRateWindow):
+			if m.paginator.Page == 0 {
+				m.dataStoreRateWindow = m.dataStoreRateWindow.next()
+				m.updateDataStoreStats()
+			}
+			return m, nil
+		case key.Matches(msg, keys.ProxyRateWindow):
+			// Proxy tables are rendered from the latest stats on every frame
+			if m.paginator.Page == 0 {
+				m.proxyRateWindow = m.proxyRateWindow.next()
+			}
+			return m, nil
+		case key.Matches(msg, keys.ShowRequestLogs):
+			// Show logs of the selected request on the traces page or the request that detected the selected miss
+			if m.paginator.Page == 3 {
+				m.showRequestLogs()
+			} else if m.paginator.Page == 4 {
+				m.showMissLogs()
+			}
+			return m, nil
 		}
 
 	case tickMsg:
 		// Update data based on intervals
 		now := time.Now()
 
+		// Play back one recorded frame per tick
+		if m.options.Replay != nil && m.replayPlaying {
+			m.advanceReplay()
+		}
+
 		if now.Sub(m.lastStatsUpdate) >= constants.DefaultStatsInterval {
 			m.lastStatsUpdate = now
 			m.updateAPIStats()
@@ -502,30 +742,31 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateAccountsStats()
 		}
 
-		return m, m.tickCmd()
-
-	case logMsg:
-		// Get current viewport content using a more reliable method
-		currentLines := strings.Split(strings.TrimSpace(m.logsViewport.View()), "\n")
-		if len(currentLines) == 1 && currentLines[0] == "" {
-			currentLines = []string{}
+		if now.Sub(m.lastTracesUpdate) >= time.Second {
+			m.lastTracesUpdate = now
+			m.updateTraces()
 		}
 
-		// Add new log entry
-		currentLines = append(currentLines, string(msg))
-
-		// Keep only the most recent 100 lines
-		if len(currentLines) > 100 {
-			currentLines = currentLines[len(currentLines)-100:]
+		if now.Sub(m.lastMissesUpdate) >= time.Second {
+			m.lastMissesUpdate = now
+			m.updateConsistencyMisses()
 		}
 
-		// Update viewport content and scroll to bottom
-		newContent := strings.Join(currentLines, "\n")
-		m.logsViewport.SetContent(newContent)
-		m.logsViewport.GotoBottom()
+		return m, m.tickCmd()
+
+	case logMsg:
+		m.appendLog(telemetry.LogEntry(msg))
 
 		// Continue listening for more logs
-		return m, m.setupLogCapture()
+		return m, m.waitForLog()
+
+	case migrationMsg:
+		if msg.err != nil {
+			m.logAccountError(msg.failure, msg.account, msg.err)
+		}
+		// Force immediate refresh
+		m.updateAccountsStats()
+		return m, nil
 	}
 
 	return m, tea.Batch(cmds...)
@@ -569,6 +810,9 @@ func (m *Model) renderLayout() string {
 	paginatorHeight := 2
 	availableHeight := m.height - helpHeight - paginatorHeight - 2 // Account for margins
 	availableWidth := m.width - 4                                  // Account for margins
+	if m.options.Replay != nil {
+		availableHeight-- // Account for the replay scrubber
+	}
 
 	// Render current page content
 	var content string
@@ -582,6 +826,17 @@ func (m *Model) renderLayout() string {
 	case 2:
 		// Page 3: Logs
 		content = m.renderPage3(logsPanelStyle, titleStyle, availableWidth, availableHeight)
+	case 3:
+		// Page 4: Traces
+		content = m.renderPage4(panelStyle, titleStyle, availableWidth, availableHeight)
+	case 4:
+		// Page 5: Consistency misses
+		content = m.renderPage5(panelStyle, titleStyle, availableWidth, availableHeight)
+	}
+
+	// Add scrubber when replaying a recording
+	if m.options.Replay != nil {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, " "+m.renderReplayScrubber(availableWidth))
 	}
 
 	// Add paginator
@@ -635,9 +890,14 @@ func (m *Model) renderPage1(panelStyle lipgloss.Style, titleStyle lipgloss.Style
 	// Join API and data store panels horizontally
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, apiPanel, dataStorePanel)
 
-	// Split width for shard and consistency panels
+	// Split width for shard and consistency panels, and the SLO panel if SLOs are evaluated
 	shardPanelWidth := (width - 2) / 2
 	consistencyPanelWidth := width - shardPanelWidth - 2
+	if m.hasSLOs() {
+		// Every panel takes up four more columns of border and margin
+		shardPanelWidth = (width - 8) / 3
+		consistencyPanelWidth = shardPanelWidth
+	}
 
 	// Render shard counts panel
 	shardPanel := panelStyle.Width(shardPanelWidth).Height(shardHeight).Render(
@@ -651,10 +911,16 @@ func (m *Model) renderPage1(panelStyle lipgloss.Style, titleStyle lipgloss.Style
 
 	// Join shard and consistency panels horizontally
 	middleRow := lipgloss.JoinHorizontal(lipgloss.Top, shardPanel, consistencyPanel)
+	if m.hasSLOs() {
+		sloPanel := panelStyle.Width(width - shardPanelWidth - consistencyPanelWidth - 8).Height(shardHeight).Render(
+			m.sloTitle(titleStyle) + "\n" + m.renderSLOs(shardHeight-1),
+		)
+		middleRow = lipgloss.JoinHorizontal(lipgloss.Top, shardPanel, consistencyPanel, sloPanel)
+	}
 
 	// Render deployment panel
 	deploymentPanel := panelStyle.Width(width).Height(deploymentHeight).Render(
-		titleStyle.Render("Deployments [Press 'd' to deploy]") + "\n" + m.renderDeploymentContent(),
+		m.deploymentTitle(titleStyle) + "\n" + m.renderDeploymentContent(),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left, topRow, middleRow, deploymentPanel)
@@ -697,7 +963,7 @@ func (m *Model) renderPage3(logsPanelStyle lipgloss.Style, titleStyle lipgloss.S
 
 	// Render logs panel
 	logsPanel := logsPanelStyle.Width(width).Height(height).Render(
-		titleStyle.Render("Logs") + "\n" + m.renderLogsContent(height-2),
+		m.logsTitle(titleStyle) + "\n" + m.renderLogsContent(height-2),
 	)
 
 	return logsPanel
@@ -706,9 +972,26 @@ func (m *Model) renderPage3(logsPanelStyle lipgloss.Style, titleStyle lipgloss.S
 // Message types for updates
 type (
 	tickMsg time.Time
-	logMsg  string
+	logMsg  telemetry.LogEntry
 )
 
+// migrationMsg reports a migration transition that ran in the background
+type migrationMsg struct {
+	failure string // Logged if the transition failed
+	account store.Account
+	err     error
+}
+
+// migrationCmd runs a migration transition of an account in the background, so a long backfill does not freeze the UI
+func (m *Model) migrationCmd(transition func(context.Context, uuid.UUID) (*store.Account, error), failure string, account store.Account) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+		defer cancel()
+		_, err := transition(ctx, account.ID)
+		return migrationMsg{failure: failure, account: account, err: err}
+	}
+}
+
 // tickCmd returns a command that sends a tick message every second
 func (m *Model) tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
@@ -720,51 +1003,38 @@ func (m *Model) tickCmd() tea.Cmd {
 func (m *Model) setupLogCapture() tea.Cmd {
 	if m.appConfig.Telemetry != nil && m.appConfig.Telemetry.LogCapture != nil {
 		// Load existing logs first
-		existingLogs := m.appConfig.Telemetry.LogCapture.GetAllLogs()
-		var logLines []string
-		for _, entry := range existingLogs {
-			// Convert ANSI colors to plain text for now
-			plainMessage := strings.ReplaceAll(entry.Message, "\n", "")
-			logLines = append(logLines, plainMessage)
-		}
-
-		// Keep only the most recent 100 lines
-		if len(logLines) > 100 {
-			logLines = logLines[len(logLines)-100:]
-		}
-
-		// Set initial viewport content
-		if len(logLines) > 0 {
-			m.logsViewport.SetContent(strings.Join(logLines, "\n"))
-			m.logsViewport.GotoBottom()
-		}
+		m.refreshLogs()
 
 		// Create a channel for log messages
-		logChan := make(chan string, 100)
+		logChan := make(chan telemetry.LogEntry, 100)
+		m.logChan = logChan
 
 		// Set up callback for new logs
 		m.appConfig.Telemetry.LogCapture.SetLogCallback(func(entry telemetry.LogEntry) {
-			plainMessage := strings.ReplaceAll(entry.Message, "\n", "")
 			select {
-			case logChan <- plainMessage:
+			case logChan <- entry:
 			default:
 				// Channel is full, drop the message
 			}
 		})
 
-		// Return a command that listens for log messages
-		return func() tea.Msg {
-			select {
-			case msg := <-logChan:
-				return logMsg(msg)
-			case <-m.ctx.Done():
-				return nil
-			}
-		}
+		return m.waitForLog()
 	}
 	return nil
 }
 
+// waitForLog returns a command that listens for the next log message
+func (m *Model) waitForLog() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case entry := <-m.logChan:
+			return logMsg(entry)
+		case <-m.ctx.Done():
+			return nil
+		}
+	}
+}
+
 // Data update methods
 func (m *Model) updateAPIStats() {
 	if m.appConfig.Telemetry == nil {
@@ -816,8 +1086,11 @@ func (m *Model) updateAPIStats() {
 			route,
 			fmt.Sprintf("%d", stat.Status),
 			fmt.Sprintf("%d", stat.Metrics.TotalCount),
-			fmt.Sprintf("%d", stat.Metrics.RequestsPerMin),
-			fmt.Sprintf("%d", stat.Metrics.DurationP95),
+			m.apiRateWindow.format(stat.Metrics),
+			formatLatency(stat.Metrics.Latency.P50()),
+			formatLatency(stat.Metrics.Latency.P90()),
+			formatLatency(stat.Metrics.Latency.P99()),
+			formatLatency(stat.Metrics.Latency.Max()),
 		}
 		rows = append(rows, row)
 	}
@@ -891,8 +1164,11 @@ func (m *Model) updateDataStoreStats() {
 			stat.Operation,
 			statusIcon,
 			fmt.Sprintf("%d", stat.Metrics.TotalCount),
-			fmt.Sprintf("%d", stat.Metrics.RequestsPerMin),
-			fmt.Sprintf("%d", stat.Metrics.DurationP95),
+			m.dataStoreRateWindow.format(stat.Metrics),
+			formatLatency(stat.Metrics.Latency.P50()),
+			formatLatency(stat.Metrics.Latency.P90()),
+			formatLatency(stat.Metrics.Latency.P99()),
+			formatLatency(stat.Metrics.Latency.Max()),
 		}
 		rows = append(rows, row)
 	}
@@ -953,10 +1229,11 @@ func (m *Model) updateAccountsStats() {
 		idStr := account.ID.String()
 		name := account.Name
 
-		// Migration status
-		migratingStr := "No"
-		if account.IsMigrating {
-			migratingStr = "Yes"
+		// Migration state and time spent in it
+		migrationStr := string(account.MigrationState.Normalize())
+		sinceStr := "-"
+		if account.MigrationUpdatedAt != nil {
+			sinceStr = time.Since(*account.MigrationUpdatedAt).Truncate(time.Second).String()
 		}
 
 		// No manual selection indicator needed - table handles highlighting
@@ -970,7 +1247,8 @@ func (m *Model) updateAccountsStats() {
 		row := table.Row{
 			idStr,
 			name,
-			migratingStr,
+			migrationStr,
+			sinceStr,
 			shardStr,
 			fmt.Sprintf("%d", accountStat.NoteCount),
 		}
@@ -984,14 +1262,16 @@ func (m *Model) adjustAccountsColumnWidths(tableWidth int) {
 	// Calculate column widths for accounts table
 	idWidth := 36
 	nameWidth := 25
-	migratingWidth := 12
+	migrationWidth := 14
+	sinceWidth := 8
 	shardWidth := 10
 	noteCountWidth := 12
 
 	accountsColumns := []table.Column{
 		{Title: "ID", Width: idWidth},
 		{Title: "Name", Width: nameWidth},
-		{Title: "IsMigrating", Width: migratingWidth},
+		{Title: "Migration", Width: migrationWidth},
+		{Title: "Since", Width: sinceWidth},
 		{Title: "Shard", Width: shardWidth},
 		{Title: "Note Count", Width: noteCountWidth},
 	}
@@ -1001,6 +1281,9 @@ func (m *Model) adjustAccountsColumnWidths(tableWidth int) {
 
 // Content rendering methods
 func (m *Model) renderDeploymentContent() string {
+	if m.options.Replay != nil {
+		return m.renderReplayEvents()
+	}
 	if m.appConfig.DeploymentController == nil {
 		return "No deployment controller available"
 	}
@@ -1035,6 +1318,7 @@ func (m *Model) renderDeploymentContent() string {
 		// Just show status when no active deployment
 		content.WriteString(fmt.Sprintf("Status: %s\n", statusStyle.Render(status.String())))
 	}
+	content.WriteString(m.renderChaosStatus() + "\n")
 
 	content.WriteString("\n")
 
@@ -1178,8 +1462,11 @@ func (m *Model) createSingleProxyStatsTable(deployment *proxy.DataProxyProcess,
 		{Title: "Operation", Width: 12},
 		{Title: "Status", Width: 6},
 		{Title: "Total", Width: 8},
-		{Title: "RPM", Width: 6},
-		{Title: "P95ms", Width: 8},
+		{Title: m.proxyRateWindow.columnTitle(), Width: rateColumnWidth},
+		{Title: "P50ms", Width: 6},
+		{Title: "P90ms", Width: 6},
+		{Title: "P99ms", Width: 6},
+		{Title: "Maxms", Width: 6},
 	}
 
 	// Sort proxy stats alphabetically by operation, then by status
@@ -1214,8 +1501,11 @@ func (m *Model) createSingleProxyStatsTable(deployment *proxy.DataProxyProcess,
 			stat.Operation,
 			statusIcon,
 			fmt.Sprintf("%d", stat.Metrics.TotalCount),
-			fmt.Sprintf("%d", stat.Metrics.RequestsPerMin),
-			fmt.Sprintf("%d", stat.Metrics.DurationP95),
+			m.proxyRateWindow.format(stat.Metrics),
+			formatLatency(stat.Metrics.Latency.P50()),
+			formatLatency(stat.Metrics.Latency.P90()),
+			formatLatency(stat.Metrics.Latency.P99()),
+			formatLatency(stat.Metrics.Latency.Max()),
 		}
 		rows = append(rows, row)
 	}
@@ -1313,9 +1603,38 @@ func (m *Model) renderConsistencyMisses() string {
 		labelStyle.Render("Total Misses"),
 		countStyle.Render(fmt.Sprintf("%d", missCount))))
 
+	shadowReads, shadowMismatches := 0, 0
+	for _, shadowRead := range stats.ShadowReads {
+		shadowReads += shadowRead.TotalCount
+		shadowMismatches += shadowRead.Mismatches
+	}
+	if shadowReads > 0 {
+		content.WriteString(fmt.Sprintf("\n%s: %s / %d",
+			labelStyle.Render("Shadow Read Mismatches"),
+			countStyle.Render(fmt.Sprintf("%d", shadowMismatches)),
+			shadowReads))
+	}
+
 	return content.String()
 }
 
+// selectedAccount returns the account highlighted in the accounts table
+func (m *Model) selectedAccount() (store.Account, bool) {
+	currentIndex := m.accountsTable.Cursor()
+	if len(m.accountsList) == 0 || currentIndex < 0 || currentIndex >= len(m.accountsList) {
+		return store.Account{}, false
+	}
+	return m.accountsList[currentIndex].Account, true
+}
+
+// logAccountError reports a failed account action in the logs view
+func (m *Model) logAccountError(message string, account store.Account, err error) {
+	if m.appConfig.Telemetry == nil {
+		return
+	}
+	m.appConfig.Telemetry.GetLogger().Warn(message, "accountID", account.ID, "error", err)
+}
+
 func (m *Model) renderLogsContent(maxHeight int) string {
 	// Update viewport dimensions if changed
 	if m.logsViewport.Height != maxHeight {
@@ -1326,12 +1645,14 @@ func (m *Model) renderLogsContent(maxHeight int) string {
 	content := strings.TrimSpace(m.logsViewport.View())
 	if content == "" {
 		waitingStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+		if m.logFilter != "" || m.logSource != "" || m.logMinLevel > slog.LevelDebug {
+			return waitingStyle.Render("No logs match the filters...")
+		}
 		return waitingStyle.Render("Waiting for logs...")
 	}
 
-	// Apply basic styling to the viewport content
-	logStyle := lipgloss.NewStyle().Foreground(m.theme.Primary)
-	return logStyle.Render(m.logsViewport.View())
+	// Lines are already styled by level
+	return m.logsViewport.View()
 }
 
 // adjustTableSizes recalculates table dimensions based on current screen size
@@ -1391,11 +1712,11 @@ func (m *Model) adjustColumnWidths(apiWidth, dataStoreWidth int) {
 	methodWidth := 8
 	statusWidth := 6
 	totalWidth := 8
-	rpmWidth := 6
-	p95Width := 8
+	rpmWidth := rateColumnWidth
+	latencyWidth := 6
 
 	// Calculate remaining width for Route column after fixed columns
-	fixedColumnsWidth := methodWidth + statusWidth + totalWidth + rpmWidth + p95Width
+	fixedColumnsWidth := methodWidth + statusWidth + totalWidth + rpmWidth + 4*latencyWidth
 	remainingWidth := apiWidth - fixedColumnsWidth - 10    // Account for padding/borders
 	routeWidth := max(20, min(remainingWidth, apiWidth/2)) // At least 20, at most half the table width
 
@@ -1404,8 +1725,11 @@ func (m *Model) adjustColumnWidths(apiWidth, dataStoreWidth int) {
 		{Title: "Route", Width: routeWidth},
 		{Title: "Status", Width: statusWidth},
 		{Title: "Total", Width: totalWidth},
-		{Title: "RPM", Width: rpmWidth},
-		{Title: "P95ms", Width: p95Width},
+		{Title: m.apiRateWindow.columnTitle(), Width: rpmWidth},
+		{Title: "P50ms", Width: latencyWidth},
+		{Title: "P90ms", Width: latencyWidth},
+		{Title: "P99ms", Width: latencyWidth},
+		{Title: "Maxms", Width: latencyWidth},
 	}
 
 	// Calculate column widths for data store table
@@ -1414,8 +1738,11 @@ func (m *Model) adjustColumnWidths(apiWidth, dataStoreWidth int) {
 		{Title: "Operation", Width: min(12, dataStoreWidth/5)},
 		{Title: "Status", Width: min(6, dataStoreWidth/10)},
 		{Title: "Total", Width: min(8, dataStoreWidth/8)},
-		{Title: "RPM", Width: min(6, dataStoreWidth/10)},
-		{Title: "P95ms", Width: min(8, dataStoreWidth/8)},
+		{Title: m.dataStoreRateWindow.columnTitle(), Width: min(rateColumnWidth, dataStoreWidth/10)},
+		{Title: "P50ms", Width: min(6, dataStoreWidth/10)},
+		{Title: "P90ms", Width: min(6, dataStoreWidth/10)},
+		{Title: "P99ms", Width: min(6, dataStoreWidth/10)},
+		{Title: "Maxms", Width: min(6, dataStoreWidth/10)},
 	}
 
 	// Update the table columns by recreating with new column definitions
@@ -1464,6 +1791,15 @@ func (m *Model) recreateTablesWithNewSize(apiWidth, apiHeight, dataStoreWidth, d
 	)
 }
 
+// formatLatency renders a duration in milliseconds, keeping one decimal for sub-10ms values
+func formatLatency(d time.Duration) string {
+	ms := float64(d) / float64(time.Millisecond)
+	if ms < 10 {
+		return fmt.Sprintf("%.1f", ms)
+	}
+	return fmt.Sprintf("%.0f", ms)
+}
+
 // min returns the smaller of two integers
 func min(a, b int) int {
 	if a < b {
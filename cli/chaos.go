@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cycleChaosPreset configures the next chaos preset on all proxies
+func (m *Model) cycleChaosPreset() {
+	m.chaosPreset = (m.chaosPreset + 1) % len(chaos.Presets)
+	preset := chaos.Presets[m.chaosPreset]
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.appConfig.DeploymentController.SetChaos(ctx, preset.Config); err != nil {
+			m.logChaosError("Could not configure chaos preset "+preset.Name, err)
+		}
+	}()
+}
+
+// killProxy kills a random proxy with SIGKILL
+func (m *Model) killProxy() {
+	if _, err := m.appConfig.DeploymentController.KillProxy(); err != nil {
+		m.logChaosError("Could not kill proxy", err)
+	}
+}
+
+// pauseProxy pauses a random proxy with SIGSTOP for the default pause duration
+func (m *Model) pauseProxy() {
+	if _, err := m.appConfig.DeploymentController.PauseProxy(constants.ChaosPauseDuration); err != nil {
+		m.logChaosError("Could not pause proxy", err)
+	}
+}
+
+// logChaosError reports a failed chaos experiment in the logs view
+func (m *Model) logChaosError(message string, err error) {
+	if m.appConfig.Telemetry == nil {
+		return
+	}
+	m.appConfig.Telemetry.GetLogger().Warn(message, "error", err)
+}
+
+// renderChaosStatus summarizes the faults proxies inject and how many were injected so far
+func (m *Model) renderChaosStatus() string {
+	config := m.appConfig.DeploymentController.Chaos()
+
+	injected := 0
+	for _, fault := range m.appConfig.Telemetry.GetStatsCollector().Export().InjectedFaults {
+		injected += fault.Count
+	}
+
+	style := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	if config.Enabled() {
+		style = lipgloss.NewStyle().Foreground(m.theme.Warning).Bold(true)
+	}
+
+	status := fmt.Sprintf("Chaos: %s", style.Render(config.String()))
+	if injected > 0 {
+		status += fmt.Sprintf(" (%d faults injected)", injected)
+	}
+	return status
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxLogLines limits the number of log lines kept in the logs view
+const maxLogLines = 100
+
+// logLevels are the minimum levels the logs view cycles through
+var logLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// logLine renders a captured log entry as a single line, colored by level
+func (m *Model) logLine(entry telemetry.LogEntry) string {
+	color := m.theme.Primary
+	switch {
+	case entry.Level >= slog.LevelError:
+		color = m.theme.Error
+	case entry.Level >= slog.LevelWarn:
+		color = m.theme.Warning
+	case entry.Level < slog.LevelInfo:
+		color = m.theme.Secondary
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(strings.ReplaceAll(entry.String(), "\n", " "))
+}
+
+// matchesLogFilters reports whether an entry passes the level, source and text filters
+func (m *Model) matchesLogFilters(entry telemetry.LogEntry) bool {
+	if entry.Level < m.logMinLevel {
+		return false
+	}
+	if m.logSource != "" && entry.Source != m.logSource {
+		return false
+	}
+	return m.logFilter == "" || strings.Contains(entry.String(), m.logFilter)
+}
+
+// refreshLogs rebuilds the logs view from all captured logs matching the current filters
+func (m *Model) refreshLogs() {
+	if m.appConfig.Telemetry == nil || m.appConfig.Telemetry.LogCapture == nil {
+		return
+	}
+
+	var logLines []string
+	for _, entry := range m.appConfig.Telemetry.LogCapture.GetAllLogs() {
+		if m.matchesLogFilters(entry) {
+			logLines = append(logLines, m.logLine(entry))
+		}
+	}
+
+	// Keep only the most recent lines
+	if len(logLines) > maxLogLines {
+		logLines = logLines[len(logLines)-maxLogLines:]
+	}
+
+	m.logsViewport.SetContent(strings.Join(logLines, "\n"))
+	m.logsViewport.GotoBottom()
+}
+
+// appendLog adds a new entry to the logs view unless it is filtered out or the view is paused
+func (m *Model) appendLog(entry telemetry.LogEntry) {
+	if m.logsPaused || !m.matchesLogFilters(entry) {
+		return
+	}
+
+	// Get current viewport content using a more reliable method
+	currentLines := strings.Split(strings.TrimSpace(m.logsViewport.View()), "\n")
+	if len(currentLines) == 1 && currentLines[0] == "" {
+		currentLines = []string{}
+	}
+
+	// Add new log entry
+	currentLines = append(currentLines, m.logLine(entry))
+
+	// Keep only the most recent lines
+	if len(currentLines) > maxLogLines {
+		currentLines = currentLines[len(currentLines)-maxLogLines:]
+	}
+
+	// Update viewport content and scroll to bottom
+	m.logsViewport.SetContent(strings.Join(currentLines, "\n"))
+	m.logsViewport.GotoBottom()
+}
+
+// setLogFilter shows only logs containing the filter, e.g. a request ID
+func (m *Model) setLogFilter(filter string) {
+	m.logFilter = strings.TrimSpace(filter)
+	m.refreshLogs()
+}
+
+// cycleLogLevel raises the minimum level of shown logs, wrapping around to debug
+func (m *Model) cycleLogLevel() {
+	next := (slices.Index(logLevels, m.logMinLevel) + 1) % len(logLevels)
+	m.logMinLevel = logLevels[next]
+	m.refreshLogs()
+}
+
+// cycleLogSource shows logs of the next source seen so far, wrapping around to all sources
+func (m *Model) cycleLogSource() {
+	if m.appConfig.Telemetry == nil || m.appConfig.Telemetry.LogCapture == nil {
+		return
+	}
+
+	sources := append([]string{""}, m.appConfig.Telemetry.LogCapture.Sources()...)
+	next := (slices.Index(sources, m.logSource) + 1) % len(sources)
+	m.logSource = sources[next]
+	m.refreshLogs()
+}
+
+// toggleLogsPaused stops following new logs, or catches up and follows them again
+func (m *Model) toggleLogsPaused() {
+	m.logsPaused = !m.logsPaused
+	if !m.logsPaused {
+		m.refreshLogs()
+	}
+}
+
+// handleLogFilterKey edits the log filter while it is being typed
+func (m *Model) handleLogFilterKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.editingLogFilter = false
+		m.setLogFilter(m.logFilterInput)
+	case tea.KeyEsc:
+		m.editingLogFilter = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.logFilterInput); len(runes) > 0 {
+			m.logFilterInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.logFilterInput += string(msg.Runes)
+	}
+}
+
+// showRequestLogs switches to the logs page, filtered to the request selected on the traces page
+func (m *Model) showRequestLogs() {
+	cursor := m.tracesTable.Cursor()
+	if cursor < 0 || cursor >= len(m.traceRoots) {
+		return
+	}
+
+	m.showLogsOfRequest(m.traceRoots[cursor].Attribute("http.request_id"))
+}
+
+// showLogsOfRequest switches to the logs page, filtered to a request ID
+func (m *Model) showLogsOfRequest(requestID string) {
+	if requestID == "" {
+		return
+	}
+
+	// Logs of the request come from the API and data proxies at any level
+	m.logMinLevel = slog.LevelDebug
+	m.logSource = ""
+	m.logFilterInput = requestID
+	m.setLogFilter(requestID)
+	m.paginator.Page = 2
+	keys.page = m.paginator.Page
+}
+
+// logsTitle renders the title of the logs panel, including the active filters
+func (m *Model) logsTitle(titleStyle lipgloss.Style) string {
+	filterStyle := lipgloss.NewStyle().Foreground(m.theme.Highlight)
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+
+	var filters []string
+	if m.logMinLevel > slog.LevelDebug {
+		filters = append(filters, "level ≥ "+telemetry.LevelLabel(m.logMinLevel))
+	}
+	if m.logSource != "" {
+		filters = append(filters, "source: "+m.logSource)
+	}
+	switch {
+	case m.editingLogFilter:
+		filters = append(filters, "filter: "+m.logFilterInput+"█")
+	case m.logFilter != "":
+		filters = append(filters, "filter: "+m.logFilter)
+	}
+
+	title := titleStyle.Render("Logs")
+	if len(filters) > 0 {
+		title += " " + filterStyle.Render(strings.Join(filters, " · "))
+	}
+	if m.logFilter != "" && !m.editingLogFilter {
+		title += subtleStyle.Render(" (esc to clear)")
+	}
+	if m.logsPaused {
+		title += " " + lipgloss.NewStyle().Foreground(m.theme.Warning).Render("PAUSED")
+	}
+	return title
+}
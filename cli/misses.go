@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateConsistencyMisses refreshes the list of recent consistency misses, keeping the selected miss highlighted
+func (m *Model) updateConsistencyMisses() {
+	if m.appConfig.Telemetry == nil {
+		return
+	}
+
+	m.misses = m.appConfig.Telemetry.GetStatsCollector().RecentConsistencyMisses()
+
+	var rows []table.Row
+	cursor := 0
+	for i, miss := range m.misses {
+		if miss.Time.Equal(m.selectedMiss.Time) && miss.NoteID == m.selectedMiss.NoteID {
+			cursor = i
+		}
+
+		rows = append(rows, table.Row{
+			miss.Time.Format("15:04:05.000"),
+			checkLabel(miss.Check),
+			shortID(miss.AccountID),
+			shortID(miss.NoteID),
+			proxyLabel(miss.ProxyID),
+			miss.RequestID,
+		})
+	}
+
+	// Rows can only be rendered once the table has columns, which are otherwise sized when the page is first rendered
+	if len(m.missesTable.Columns()) == 0 {
+		m.adjustMissesColumnWidths(m.width - 8)
+		m.missesTable.SetColumns(m.missesColumns)
+	}
+
+	m.missesTable.SetRows(rows)
+	m.missesTable.SetCursor(cursor)
+	m.selectConsistencyMiss()
+}
+
+// selectConsistencyMiss remembers the highlighted miss so it stays selected when new misses come in
+func (m *Model) selectConsistencyMiss() {
+	if miss, ok := m.highlightedMiss(); ok {
+		m.selectedMiss = miss
+	}
+}
+
+// highlightedMiss returns the miss highlighted in the table
+func (m *Model) highlightedMiss() (telemetry.ConsistencyMiss, bool) {
+	cursor := m.missesTable.Cursor()
+	if cursor < 0 || cursor >= len(m.misses) {
+		return telemetry.ConsistencyMiss{}, false
+	}
+	return m.misses[cursor], true
+}
+
+// showMissLogs switches to the logs page, filtered to the request that detected the highlighted miss
+func (m *Model) showMissLogs() {
+	if miss, ok := m.highlightedMiss(); ok {
+		m.showLogsOfRequest(miss.RequestID)
+	}
+}
+
+// checkLabel describes the check that detected a miss
+func checkLabel(check telemetry.ConsistencyCheck) string {
+	switch check {
+	case telemetry.ConsistencyCheckRead:
+		return "read"
+	case telemetry.ConsistencyCheckListMissing:
+		return "list missing"
+	case telemetry.ConsistencyCheckListMismatch:
+		return "list mismatch"
+	case telemetry.ConsistencyCheckReadYourWrites:
+		return "read own write"
+	case telemetry.ConsistencyCheckMonotonicRead:
+		return "monotonic read"
+	case telemetry.ConsistencyCheckCrossAccount:
+		return "cross account"
+	default:
+		return string(check)
+	}
+}
+
+// proxyLabel renders the version of the proxy that served a request
+func proxyLabel(proxyID int) string {
+	if proxyID == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("v%d", proxyID)
+}
+
+// shortID shortens a UUID to its first segment for table columns
+func shortID(id string) string {
+	if prefix, _, found := strings.Cut(id, "-"); found {
+		return prefix
+	}
+	return id
+}
+
+// adjustMissesColumnWidths gives the request ID all space not needed by the other columns
+func (m *Model) adjustMissesColumnWidths(tableWidth int) {
+	timeWidth := 12
+	checkWidth := 14
+	accountWidth := 8
+	noteWidth := 8
+	proxyWidth := 5
+	requestWidth := max(20, tableWidth-timeWidth-checkWidth-accountWidth-noteWidth-proxyWidth-12)
+
+	m.missesColumns = []table.Column{
+		{Title: "Time", Width: timeWidth},
+		{Title: "Check", Width: checkWidth},
+		{Title: "Account", Width: accountWidth},
+		{Title: "Note", Width: noteWidth},
+		{Title: "Proxy", Width: proxyWidth},
+		{Title: "Request ID", Width: requestWidth},
+	}
+}
+
+// renderPage5 renders recent consistency misses and the details of the selected miss
+func (m *Model) renderPage5(panelStyle lipgloss.Style, titleStyle lipgloss.Style, width, height int) string {
+	listHeight := (height * 6) / 10
+	detailHeight := height - listHeight - 4
+
+	tableWidth := width - 4
+	m.adjustMissesColumnWidths(tableWidth)
+
+	prevCursor := m.missesTable.Cursor()
+	m.missesTable = table.New(
+		table.WithColumns(m.missesColumns),
+		table.WithRows(m.missesTable.Rows()),
+		table.WithWidth(tableWidth),
+		table.WithHeight(max(3, listHeight-4)),
+		table.WithFocused(true),
+	)
+	m.missesTable.SetCursor(prevCursor)
+
+	listPanel := panelStyle.Width(width).Height(listHeight).Render(
+		m.missesTitle(titleStyle) + "\n" + m.missesTable.View(),
+	)
+
+	detailPanel := panelStyle.Width(width).Height(detailHeight).Render(
+		titleStyle.Render("Miss Details") + "\n" + m.renderMissDetails(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, listPanel, detailPanel)
+}
+
+// missesTitle renders the title of the misses list with the total number of misses, which may exceed the kept ones
+func (m *Model) missesTitle(titleStyle lipgloss.Style) string {
+	title := titleStyle.Render("Consistency Misses")
+	if m.appConfig.Telemetry == nil {
+		return title
+	}
+
+	stats := m.appConfig.Telemetry.GetStatsCollector().Export()
+	total := stats.ConsistencyMisses
+	if total == 0 {
+		return title
+	}
+
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	summary := fmt.Sprintf("%d total", total)
+	if total > len(m.misses) {
+		summary = fmt.Sprintf("latest %d of %d", len(m.misses), total)
+	}
+	if byCheck := missesByCheckSummary(stats.MissesByCheck); byCheck != "" {
+		summary += " · " + byCheck
+	}
+	return title + " " + subtleStyle.Render(summary)
+}
+
+// missesByCheckSummary lists the number of misses of every check that detected any, e.g. "read 2, monotonic read 1"
+func missesByCheckSummary(missesByCheck map[telemetry.ConsistencyCheck]int) string {
+	checks := make([]telemetry.ConsistencyCheck, 0, len(missesByCheck))
+	for check, count := range missesByCheck {
+		if count > 0 {
+			checks = append(checks, check)
+		}
+	}
+	slices.Sort(checks)
+
+	parts := make([]string, len(checks))
+	for i, check := range checks {
+		parts[i] = fmt.Sprintf("%s %d", checkLabel(check), missesByCheck[check])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderMissDetails shows every field of the selected miss, including the full IDs and hashes
+func (m *Model) renderMissDetails() string {
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+
+	miss, ok := m.highlightedMiss()
+	if !ok {
+		if m.options.Replay != nil {
+			return subtleStyle.Render("Recordings only contain the number of consistency misses")
+		}
+		return subtleStyle.Render("No consistency misses detected")
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(m.theme.Secondary).Width(15)
+	errorStyle := lipgloss.NewStyle().Foreground(m.theme.Error)
+
+	actualHash := miss.ActualHash
+	if actualHash == "" {
+		actualHash = errorStyle.Render("note missing")
+	} else {
+		actualHash = errorStyle.Render(actualHash)
+	}
+
+	servedBy := proxyLabel(miss.ProxyID)
+	if miss.ProxyID == 0 {
+		servedBy = subtleStyle.Render("unknown")
+	}
+
+	requestID := miss.RequestID
+	if requestID == "" {
+		requestID = subtleStyle.Render("unknown")
+	} else {
+		requestID += subtleStyle.Render("  (r for request logs)")
+	}
+
+	detail := miss.Detail
+	if detail == "" {
+		detail = subtleStyle.Render("-")
+	}
+
+	lines := []string{
+		labelStyle.Render("Time") + miss.Time.Format("2006-01-02 15:04:05.000"),
+		labelStyle.Render("Check") + checkLabel(miss.Check),
+		labelStyle.Render("Account") + miss.AccountID,
+		labelStyle.Render("Note") + miss.NoteID,
+		labelStyle.Render("Expected hash") + miss.ExpectedHash,
+		labelStyle.Render("Actual hash") + actualHash,
+		labelStyle.Render("Detail") + detail,
+		labelStyle.Render("Served by") + servedBy,
+		labelStyle.Render("Request ID") + requestID,
+	}
+	return strings.Join(lines, "\n")
+}
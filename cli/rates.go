@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+// rateWindow selects which of the request rates a stats table shows
+type rateWindow int
+
+const (
+	rateWindowOneMinute rateWindow = iota
+	rateWindowFiveMinutes
+	rateWindowEWMA
+	rateWindowCount
+)
+
+// rateColumnWidth fits the column title and rates up to 99999 RPM
+const rateColumnWidth = 7
+
+// next returns the window shown after this one, wrapping around to one minute
+func (w rateWindow) next() rateWindow {
+	return (w + 1) % rateWindowCount
+}
+
+// columnTitle returns the title of the rate column of a stats table
+func (w rateWindow) columnTitle() string {
+	switch w {
+	case rateWindowFiveMinutes:
+		return "RPM 5m"
+	case rateWindowEWMA:
+		return "RPM ~"
+	default:
+		return "RPM 1m"
+	}
+}
+
+// format renders the rate of the window, with a decimal for low rates so they do not round to zero
+func (w rateWindow) format(metrics telemetry.RequestMetrics) string {
+	var rate float64
+	switch w {
+	case rateWindowFiveMinutes:
+		rate = metrics.Rates.FiveMinutes
+	case rateWindowEWMA:
+		rate = metrics.Rates.EWMA
+	default:
+		rate = metrics.Rates.OneMinute
+	}
+
+	if rate > 0 && rate < 10 {
+		return fmt.Sprintf("%.1f", rate)
+	}
+	return fmt.Sprintf("%.0f", rate)
+}
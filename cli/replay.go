@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxReplayEvents limits the number of recorded events shown in place of the deployment panel
+const maxReplayEvents = 10
+
+// seekReplay moves the replay by a number of frames and shows the stats of the new frame
+func (m *Model) seekReplay(frames int) {
+	replay := m.options.Replay
+	replay.Seek(replay.Position() + frames)
+	m.refreshReplay()
+}
+
+// seekReplayEvent moves the replay to the previous or next frame with recorded events
+func (m *Model) seekReplayEvent(direction int) {
+	m.options.Replay.SeekEvent(direction)
+	m.refreshReplay()
+}
+
+// advanceReplay plays back one frame per tick, stopping at the last frame
+func (m *Model) advanceReplay() {
+	replay := m.options.Replay
+	if replay.Position() >= replay.Len()-1 {
+		m.replayPlaying = false
+		return
+	}
+	m.seekReplay(1)
+}
+
+// refreshReplay updates all stats tables from the current frame
+func (m *Model) refreshReplay() {
+	m.updateAPIStats()
+	m.updateDataStoreStats()
+}
+
+// renderReplayScrubber renders the position in the recording, with markers for frames with events
+func (m *Model) renderReplayScrubber(width int) string {
+	replay := m.options.Replay
+	position := replay.Position()
+	frame := replay.Frame(position)
+
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	playedStyle := lipgloss.NewStyle().Foreground(m.theme.Accent)
+	eventStyle := lipgloss.NewStyle().Foreground(m.theme.Warning)
+	labelStyle := lipgloss.NewStyle().Foreground(m.theme.Highlight).Bold(true)
+
+	state := "⏸"
+	if m.replayPlaying {
+		state = "▶"
+	}
+
+	// Consistency misses are cumulative, so the change since the previous frame shows spikes
+	misses := frame.Stats.ConsistencyMisses
+	missesText := fmt.Sprintf("misses %d", misses)
+	if position > 0 {
+		if delta := misses - replay.Frame(position-1).Stats.ConsistencyMisses; delta > 0 {
+			missesText += lipgloss.NewStyle().Foreground(m.theme.Error).Render(fmt.Sprintf(" (+%d)", delta))
+		}
+	}
+
+	label := fmt.Sprintf("%s Replay %s", state, frame.Time.Format("15:04:05"))
+	info := fmt.Sprintf(" frame %d/%d · %s", position+1, replay.Len(), missesText)
+
+	barWidth := max(10, width-lipgloss.Width(label)-lipgloss.Width(info)-2)
+	var bar strings.Builder
+	for cell := 0; cell < barWidth; cell++ {
+		// Every cell covers a range of frames
+		first := cell * replay.Len() / barWidth
+		last := max(first, (cell+1)*replay.Len()/barWidth-1)
+
+		hasEvents := false
+		for i := first; i <= last && i < replay.Len(); i++ {
+			if len(replay.Frame(i).Events) > 0 {
+				hasEvents = true
+				break
+			}
+		}
+
+		switch {
+		case hasEvents:
+			bar.WriteString(eventStyle.Render("◆"))
+		case first <= position:
+			bar.WriteString(playedStyle.Render("━"))
+		default:
+			bar.WriteString(subtleStyle.Render("─"))
+		}
+	}
+
+	return labelStyle.Render(label) + " " + bar.String() + info
+}
+
+// deploymentTitle renders the title of the deployment panel, which lists recorded events when replaying
+func (m *Model) deploymentTitle(titleStyle lipgloss.Style) string {
+	if m.options.Replay != nil {
+		return titleStyle.Render("Recorded Events")
+	}
+	return titleStyle.Render("Deployments [Press 'd' to deploy]")
+}
+
+// renderReplayEvents lists the most recent events up to the current frame in place of the deployment panel
+func (m *Model) renderReplayEvents() string {
+	events := m.options.Replay.EventsUntil()
+	if len(events) == 0 {
+		return lipgloss.NewStyle().Foreground(m.theme.Subtle).Render("No events recorded yet")
+	}
+	if len(events) > maxReplayEvents {
+		events = events[len(events)-maxReplayEvents:]
+	}
+
+	timeStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	var lines []string
+	for _, event := range events {
+		lines = append(lines, timeStyle.Render(event.Time.Format("15:04:05"))+" "+event.Event)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hasSLOs reports whether SLOs are evaluated, which adds the SLO panel to the overview
+func (m *Model) hasSLOs() bool {
+	return m.appConfig.Telemetry != nil && m.appConfig.Telemetry.SLOs != nil
+}
+
+// sloTitle renders the title of the SLO panel with the number of violated SLOs
+func (m *Model) sloTitle(titleStyle lipgloss.Style) string {
+	violations := len(m.appConfig.Telemetry.SLOs.Violations())
+	if violations == 0 {
+		return titleStyle.Render("SLOs")
+	}
+	return titleStyle.Render("SLOs") + " " + lipgloss.NewStyle().Foreground(m.theme.Error).Bold(true).Render(fmt.Sprintf("%d violated", violations))
+}
+
+// renderSLOs lists the burn rate and remaining error budget of every SLO, violated SLOs first
+func (m *Model) renderSLOs(maxLines int) string {
+	statuses := m.appConfig.Telemetry.SLOs.Statuses()
+	if len(statuses) == 0 {
+		return lipgloss.NewStyle().Foreground(m.theme.Subtle).Render("Waiting for first evaluation")
+	}
+
+	slices.SortStableFunc(statuses, func(a, b telemetry.SLOStatus) int {
+		switch {
+		case a.Violated == b.Violated:
+			return 0
+		case a.Violated:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	okStyle := lipgloss.NewStyle().Foreground(m.theme.Success)
+	violatedStyle := lipgloss.NewStyle().Foreground(m.theme.Error).Bold(true)
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+
+	maxLines = max(1, maxLines)
+	var lines []string
+	for i, status := range statuses {
+		if len(lines) == maxLines-1 && len(statuses)-i > 1 {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", len(statuses)-i)))
+			break
+		}
+
+		icon, style := "✓", okStyle
+		if status.Violated {
+			icon, style = "✗", violatedStyle
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			style.Render(icon+" "+status.SLO.Name),
+			subtleStyle.Render("burn"),
+			formatBurnRate(status.BurnRate)+subtleStyle.Render(" budget ")+fmt.Sprintf("%.0f%%", status.BudgetRemaining*100)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatBurnRate renders a burn rate, which is infinite when any miss is spent from a budget of zero
+func formatBurnRate(rate float64) string {
+	if math.IsInf(rate, 1) {
+		return "∞"
+	}
+	return fmt.Sprintf("%.1fx", rate)
+}
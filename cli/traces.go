@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxListedTraces limits the number of requests listed on the traces page
+const maxListedTraces = 100
+
+// updateTraces refreshes the list of recent requests, keeping the selected request highlighted
+func (m *Model) updateTraces() {
+	if m.appConfig.Telemetry == nil {
+		return
+	}
+
+	m.traceRoots = m.appConfig.Telemetry.GetTracer().RecentRoots(maxListedTraces)
+
+	var rows []table.Row
+	cursor := 0
+	for i, root := range m.traceRoots {
+		if root.TraceID == m.selectedTraceID {
+			cursor = i
+		}
+
+		status := root.Attribute("http.status_code")
+		if status == "" {
+			status = "-"
+		}
+
+		rows = append(rows, table.Row{
+			root.Start.Format("15:04:05.000"),
+			root.Name,
+			status,
+			formatLatency(root.Duration()) + "ms",
+			root.TraceID.String(),
+		})
+	}
+
+	// Rows can only be rendered once the table has columns, which are otherwise sized when the page is first rendered
+	if len(m.tracesTable.Columns()) == 0 {
+		m.adjustTracesColumnWidths(m.width - 8)
+		m.tracesTable.SetColumns(m.tracesColumns)
+	}
+
+	m.tracesTable.SetRows(rows)
+	m.tracesTable.SetCursor(cursor)
+	m.loadSelectedTrace()
+}
+
+// loadSelectedTrace loads all spans of the highlighted request, including those of data proxies
+func (m *Model) loadSelectedTrace() {
+	cursor := m.tracesTable.Cursor()
+	if cursor < 0 || cursor >= len(m.traceRoots) {
+		m.selectedTraceID = telemetry.TraceID{}
+		m.traceSpans = nil
+		return
+	}
+
+	traceID := m.traceRoots[cursor].TraceID
+	if traceID == m.selectedTraceID && m.traceSpans != nil {
+		return
+	}
+
+	spans, err := m.appConfig.Telemetry.GetTracer().Trace(traceID)
+	if err != nil {
+		m.appConfig.Telemetry.GetLogger().Warn("Could not load trace", "traceID", traceID.String(), "error", err)
+	}
+
+	m.selectedTraceID = traceID
+	m.traceSpans = spans
+}
+
+// adjustTracesColumnWidths gives the request name all space not needed by the other columns
+func (m *Model) adjustTracesColumnWidths(tableWidth int) {
+	timeWidth := 12
+	statusWidth := 6
+	durationWidth := 10
+	traceIDWidth := 32
+	requestWidth := max(20, tableWidth-timeWidth-statusWidth-durationWidth-traceIDWidth-10)
+
+	m.tracesColumns = []table.Column{
+		{Title: "Time", Width: timeWidth},
+		{Title: "Request", Width: requestWidth},
+		{Title: "Status", Width: statusWidth},
+		{Title: "Duration", Width: durationWidth},
+		{Title: "Trace ID", Width: traceIDWidth},
+	}
+}
+
+// renderPage4 renders recent requests and the waterfall of the selected request
+func (m *Model) renderPage4(panelStyle lipgloss.Style, titleStyle lipgloss.Style, width, height int) string {
+	listHeight := (height * 4) / 10
+	waterfallHeight := height - listHeight - 4
+
+	tableWidth := width - 4
+	m.adjustTracesColumnWidths(tableWidth)
+
+	prevCursor := m.tracesTable.Cursor()
+	m.tracesTable = table.New(
+		table.WithColumns(m.tracesColumns),
+		table.WithRows(m.tracesTable.Rows()),
+		table.WithWidth(tableWidth),
+		table.WithHeight(max(3, listHeight-4)),
+		table.WithFocused(true),
+	)
+	m.tracesTable.SetCursor(prevCursor)
+
+	listPanel := panelStyle.Width(width).Height(listHeight).Render(
+		titleStyle.Render("Recent Requests") + "\n" + m.tracesTable.View(),
+	)
+
+	waterfallPanel := panelStyle.Width(width).Height(waterfallHeight).Render(
+		titleStyle.Render("Trace Waterfall") + "\n" + m.renderWaterfall(tableWidth, max(1, waterfallHeight-3)),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, listPanel, waterfallPanel)
+}
+
+// waterfallRow is a span together with its depth in the trace
+type waterfallRow struct {
+	span  telemetry.SpanData
+	depth int
+}
+
+// waterfallRows orders spans depth-first, children sorted by start time.
+// Spans whose parent is missing, e.g. because it was not exported yet, are shown at the top level.
+func waterfallRows(spans []telemetry.SpanData) []waterfallRow {
+	known := make(map[telemetry.SpanID]bool, len(spans))
+	for _, span := range spans {
+		known[span.SpanID] = true
+	}
+
+	children := make(map[telemetry.SpanID][]telemetry.SpanData)
+	var roots []telemetry.SpanData
+	for _, span := range spans {
+		if span.ParentSpanID.IsValid() && known[span.ParentSpanID] {
+			children[span.ParentSpanID] = append(children[span.ParentSpanID], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	byStart := func(s []telemetry.SpanData) {
+		sort.SliceStable(s, func(i, j int) bool { return s[i].Start.Before(s[j].Start) })
+	}
+
+	var rows []waterfallRow
+	var visit func(span telemetry.SpanData, depth int)
+	visit = func(span telemetry.SpanData, depth int) {
+		rows = append(rows, waterfallRow{span: span, depth: depth})
+		next := children[span.SpanID]
+		byStart(next)
+		for _, child := range next {
+			visit(child, depth+1)
+		}
+	}
+
+	byStart(roots)
+	for _, root := range roots {
+		visit(root, 0)
+	}
+	return rows
+}
+
+// renderWaterfall draws every span of the selected trace as a bar relative to the start and end of the trace
+func (m *Model) renderWaterfall(width, height int) string {
+	subtleStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	if len(m.traceSpans) == 0 {
+		return subtleStyle.Render("Select a request to show its spans...")
+	}
+
+	rows := waterfallRows(m.traceSpans)
+
+	traceStart, traceEnd := rows[0].span.Start, rows[0].span.End
+	for _, row := range rows {
+		if row.span.Start.Before(traceStart) {
+			traceStart = row.span.Start
+		}
+		if row.span.End.After(traceEnd) {
+			traceEnd = row.span.End
+		}
+	}
+	total := traceEnd.Sub(traceStart)
+	if total <= 0 {
+		total = time.Microsecond
+	}
+
+	nameWidth := 36
+	serviceWidth := 14
+	durationWidth := 9
+	barWidth := max(10, width-nameWidth-serviceWidth-durationWidth-3)
+
+	headerStyle := lipgloss.NewStyle().Foreground(m.theme.Highlight)
+	apiStyle := lipgloss.NewStyle().Foreground(m.theme.Accent)
+	proxyStyle := lipgloss.NewStyle().Foreground(m.theme.Success)
+	errorStyle := lipgloss.NewStyle().Foreground(m.theme.Error)
+
+	var lines []string
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%-*s %-*s %*s %s",
+		nameWidth, "Span", serviceWidth, "Service", durationWidth, "Duration",
+		fmt.Sprintf("0 … %sms", formatLatency(total)))))
+
+	for _, row := range rows {
+		if len(lines) >= height {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("… %d more spans", len(rows)-len(lines)+1)))
+			break
+		}
+
+		name := strings.Repeat("  ", row.depth) + row.span.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth-1] + "…"
+		}
+		service := row.span.Service
+		if len(service) > serviceWidth {
+			service = service[:serviceWidth-1] + "…"
+		}
+
+		offset := int(float64(barWidth) * float64(row.span.Start.Sub(traceStart)) / float64(total))
+		length := int(float64(barWidth) * float64(row.span.Duration()) / float64(total))
+		offset = min(max(offset, 0), barWidth-1)
+		length = min(max(length, 1), barWidth-offset)
+
+		barStyle := apiStyle
+		if strings.HasPrefix(row.span.Service, "data-proxy") {
+			barStyle = proxyStyle
+		}
+		if row.span.Error != "" {
+			barStyle = errorStyle
+		}
+
+		lines = append(lines, fmt.Sprintf("%-*s %-*s %*s %s%s",
+			nameWidth, name,
+			serviceWidth, service,
+			durationWidth, formatLatency(row.span.Duration())+"ms",
+			strings.Repeat(" ", offset),
+			barStyle.Render(strings.Repeat("█", length)),
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -12,14 +12,21 @@ const (
 	// Server configuration
 	DefaultPort             = "8080"
 	GracefulShutdownTimeout = 5 * time.Second
+	MaxNoteContentLength    = 10000
 
 	// Load generator configuration
 	MillisecondsPerMinute = 60000
 	LoadGenStartupDelay   = 100 * time.Millisecond
 
 	// Telemetry configuration
-	DefaultLogBufferSize = 1000
-	DefaultStatsInterval = 2 * time.Second
+	DefaultLogBufferSize     = 1000
+	DefaultLogFileMaxBytes   = 10 * 1024 * 1024
+	DefaultStatsInterval     = 2 * time.Second
+	DefaultTraceBufferSize   = 1000
+	DefaultTraceSpanBuffer   = 20000 // Spans of all processes kept in memory to show recent traces
+	DefaultTraceFile         = ".data/traces.jsonl"
+	DefaultTraceFileMaxBytes = 50 * 1024 * 1024
+	DefaultStatsDir          = ".data/telemetry"
 
 	// Proxy configuration
 	InstrumentInterval     = 2 * time.Second
@@ -31,8 +38,25 @@ const (
 	ProcessMonitorInterval = 2 * time.Second
 	MaxRestartAttempts     = 5
 	RestartBackoffMax      = 10 * time.Second
+
+	// Chaos configuration
+	ChaosTimeout       = 10 * time.Second // How long calls failed with an injected timeout hang before being dropped
+	ChaosPauseDuration = 5 * time.Second  // How long proxies are paused unless requested otherwise
+
+	// Change stream configuration
+	ChangeStreamPollInterval = 500 * time.Millisecond
+	ChangeStreamHeartbeat    = 15 * time.Second
+	ChangeStreamBatchSize    = 100
+
+	// Dual-write configuration
+	ShadowReadTimeout    = 5 * time.Second
+	ShadowReadSoakPeriod = 2 * time.Minute
+	MaxShadowReads       = 4 // Shadow reads a proxy runs at once, reads beyond that are not shadowed
 )
 
+// AccountStore is the name of the accounts database
+const AccountStore = "accounts"
+
 // Note store identifier constants
 const (
 	// LegacyNoteStore represents the initial note store
@@ -0,0 +1,136 @@
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+)
+
+// Names of the consistency checks accepted by ParseConsistencyChecks
+const (
+	CheckReadYourWrites = "read-your-writes"
+	CheckMonotonicReads = "monotonic-reads"
+	CheckCrossAccount   = "cross-account"
+)
+
+// ConsistencyChecks configures the checks account loops run on top of comparing note contents to their last write.
+// The zero value runs no additional checks and reports notes missing from lists right away.
+type ConsistencyChecks struct {
+	ReadYourWrites  bool               `json:"readYourWrites,omitempty"`  // Read every note back right after writing it
+	MonotonicReads  bool               `json:"monotonicReads,omitempty"`  // Reads of a note must never return an older updatedAt than a previous read
+	CrossAccount    bool               `json:"crossAccount,omitempty"`    // Notes read by an account must have been created by it
+	ListGracePeriod telemetry.Duration `json:"listGracePeriod,omitempty"` // How long after creating a note it may be missing from lists
+}
+
+// ParseConsistencyChecks enables the checks in a comma-separated list of check names, e.g. "read-your-writes,cross-account"
+func ParseConsistencyChecks(names string, listGracePeriod time.Duration) (ConsistencyChecks, error) {
+	checks := ConsistencyChecks{ListGracePeriod: telemetry.Duration(listGracePeriod)}
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case CheckReadYourWrites:
+			checks.ReadYourWrites = true
+		case CheckMonotonicReads:
+			checks.MonotonicReads = true
+		case CheckCrossAccount:
+			checks.CrossAccount = true
+		default:
+			return ConsistencyChecks{}, fmt.Errorf("unknown consistency check %q, expected %s, %s or %s", name, CheckReadYourWrites, CheckMonotonicReads, CheckCrossAccount)
+		}
+	}
+	return checks, checks.Validate()
+}
+
+// Validate checks that all parameters are in range
+func (c ConsistencyChecks) Validate() error {
+	if c.ListGracePeriod < 0 {
+		return errors.New("list grace period must not be negative")
+	}
+	return nil
+}
+
+// trackedNote is what an account loop knows about one of its notes
+type trackedNote struct {
+	hash      string    // Hash of the content last written
+	createdAt time.Time // When the account created the note, lists may miss it during the grace period
+	readAt    time.Time // Latest updatedAt returned by a read, later reads must not return an older one
+}
+
+// trackWrite remembers the content the account wrote to a note. The caller must hold the notes lock.
+func (al *AccountLoop) trackWrite(note *store.Note) {
+	tracked, ok := al.notes[note.ID]
+	if !ok {
+		tracked = &trackedNote{createdAt: time.Now()}
+		al.notes[note.ID] = tracked
+	}
+	tracked.hash = hashContents(note.Content)
+}
+
+// checkRead runs all checks on a note returned by a read, recording a miss for every check it fails.
+// contentCheck names the check comparing the content to the last write. The caller must hold the notes lock.
+func (al *AccountLoop) checkRead(contentCheck telemetry.ConsistencyCheck, note *store.Note, info *restapi.ResponseInfo) {
+	actualHash := hashContents(note.Content)
+
+	if al.checks.CrossAccount && note.Creator != al.accountID {
+		al.logger.Warn("CONSISTENCY ERROR: Note of another account returned", "creator", note.Creator)
+		al.trackConsistencyMiss(telemetry.ConsistencyCheckCrossAccount, note.ID, "", actualHash, fmt.Sprintf("note was created by account %s", note.Creator), info)
+	}
+
+	tracked, ok := al.notes[note.ID]
+	if !ok {
+		return // The note was deleted meanwhile, or was created by another load generator
+	}
+
+	if actualHash != tracked.hash {
+		al.logger.Warn("CONSISTENCY ERROR: Note content mismatch detected", "check", contentCheck)
+		al.trackConsistencyMiss(contentCheck, note.ID, tracked.hash, actualHash, "", info)
+	}
+
+	if al.checks.MonotonicReads {
+		if note.UpdatedAt.Before(tracked.readAt) {
+			al.logger.Warn("CONSISTENCY ERROR: Note read went back in time", "updated_at", note.UpdatedAt, "previously_read", tracked.readAt)
+			detail := fmt.Sprintf("updatedAt went back from %s to %s", tracked.readAt.Format(time.RFC3339Nano), note.UpdatedAt.Format(time.RFC3339Nano))
+			al.trackConsistencyMiss(telemetry.ConsistencyCheckMonotonicRead, note.ID, tracked.hash, actualHash, detail, info)
+		} else {
+			tracked.readAt = note.UpdatedAt
+		}
+	}
+}
+
+// readYourWrite reads a note right after the account wrote it, which must return the written content
+func (al *AccountLoop) readYourWrite(noteID uuid.UUID) error {
+	if !al.checks.ReadYourWrites {
+		return nil
+	}
+
+	ctx, info := restapi.WithResponseInfo(al.ctx)
+	note, err := al.apiClient.GetNote(ctx, al.accountID, noteID)
+
+	al.notesLock.Lock()
+	defer al.notesLock.Unlock()
+
+	tracked, ok := al.notes[noteID]
+	if err != nil && info.Status == http.StatusNotFound && ok {
+		al.logger.Warn("CONSISTENCY ERROR: Written note not found")
+		al.trackConsistencyMiss(telemetry.ConsistencyCheckReadYourWrites, noteID, tracked.hash, "", "note was not found right after writing it", info)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read note after writing it: %w", err)
+	}
+
+	al.checkRead(telemetry.ConsistencyCheckReadYourWrites, note, info)
+	return nil
+}
+
+// listGraceElapsed returns true if a note created by the account must be part of a list started at the given time
+func (al *AccountLoop) listGraceElapsed(tracked *trackedNote, listStart time.Time) bool {
+	return listStart.Sub(tracked.createdAt) >= time.Duration(al.checks.ListGracePeriod)
+}
@@ -0,0 +1,114 @@
+package loadgen
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountLoop(t *testing.T, checks ConsistencyChecks) *AccountLoop {
+	collector := telemetry.NewStatsCollector(telemetry.WithAutoStart(false))
+	t.Cleanup(collector.Stop)
+
+	return &AccountLoop{
+		accountID: uuid.New(),
+		telemetry: &telemetry.Telemetry{StatsCollector: collector},
+		logger:    slog.New(slog.DiscardHandler),
+		notes:     make(map[uuid.UUID]*trackedNote),
+		checks:    checks,
+		stats:     newRunStats(),
+	}
+}
+
+func TestParseConsistencyChecks(t *testing.T) {
+	checks, err := ParseConsistencyChecks("read-your-writes, cross-account", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, ConsistencyChecks{ReadYourWrites: true, CrossAccount: true, ListGracePeriod: telemetry.Duration(time.Second)}, checks)
+
+	checks, err = ParseConsistencyChecks("", 0)
+	require.NoError(t, err)
+	require.Equal(t, ConsistencyChecks{}, checks)
+
+	_, err = ParseConsistencyChecks("read-your-writes,linearizable", 0)
+	require.ErrorContains(t, err, `unknown consistency check "linearizable"`)
+
+	_, err = ParseConsistencyChecks("", -time.Second)
+	require.Error(t, err)
+}
+
+func TestCheckReadMonotonicReads(t *testing.T) {
+	al := newTestAccountLoop(t, ConsistencyChecks{MonotonicReads: true})
+
+	note := &store.Note{ID: uuid.New(), Creator: al.accountID, Content: "hello", UpdatedAt: time.Now()}
+	al.trackWrite(note)
+
+	info := &restapi.ResponseInfo{RequestID: "req-1", ServedBy: 2}
+	al.checkRead(telemetry.ConsistencyCheckRead, note, info)
+	require.Zero(t, al.telemetry.StatsCollector.Export().ConsistencyMisses)
+
+	// An older version of the same content went back in time
+	stale := *note
+	stale.UpdatedAt = note.UpdatedAt.Add(-time.Second)
+	al.checkRead(telemetry.ConsistencyCheckRead, &stale, info)
+
+	misses := al.telemetry.StatsCollector.RecentConsistencyMisses()
+	require.Len(t, misses, 1)
+	require.Equal(t, telemetry.ConsistencyCheckMonotonicRead, misses[0].Check)
+	require.Equal(t, 2, misses[0].ProxyID)
+	require.Contains(t, misses[0].Detail, "updatedAt went back")
+
+	// The stale read must not lower what later reads are compared to
+	al.checkRead(telemetry.ConsistencyCheckRead, &stale, info)
+	require.Equal(t, 2, al.telemetry.StatsCollector.Export().MissesByCheck[telemetry.ConsistencyCheckMonotonicRead])
+}
+
+func TestCheckReadCrossAccount(t *testing.T) {
+	al := newTestAccountLoop(t, ConsistencyChecks{CrossAccount: true})
+
+	foreign := &store.Note{ID: uuid.New(), Creator: uuid.New(), Content: "not mine"}
+	al.checkRead(telemetry.ConsistencyCheckListMismatch, foreign, &restapi.ResponseInfo{})
+
+	misses := al.telemetry.StatsCollector.RecentConsistencyMisses()
+	require.Len(t, misses, 1)
+	require.Equal(t, telemetry.ConsistencyCheckCrossAccount, misses[0].Check)
+	require.Contains(t, misses[0].Detail, foreign.Creator.String())
+
+	// Without the check, notes the account does not know about are ignored
+	al.checks = ConsistencyChecks{}
+	al.checkRead(telemetry.ConsistencyCheckListMismatch, foreign, &restapi.ResponseInfo{})
+	require.Equal(t, 1, al.telemetry.StatsCollector.Export().ConsistencyMisses)
+}
+
+func TestCheckReadContent(t *testing.T) {
+	al := newTestAccountLoop(t, ConsistencyChecks{})
+
+	note := &store.Note{ID: uuid.New(), Creator: al.accountID, Content: "written"}
+	al.trackWrite(note)
+
+	read := *note
+	read.Content = "something else"
+	al.checkRead(telemetry.ConsistencyCheckReadYourWrites, &read, &restapi.ResponseInfo{})
+
+	misses := al.telemetry.StatsCollector.RecentConsistencyMisses()
+	require.Len(t, misses, 1)
+	require.Equal(t, telemetry.ConsistencyCheckReadYourWrites, misses[0].Check)
+	require.Equal(t, hashContents("written"), misses[0].ExpectedHash)
+	require.Equal(t, hashContents("something else"), misses[0].ActualHash)
+}
+
+func TestListGraceElapsed(t *testing.T) {
+	al := newTestAccountLoop(t, ConsistencyChecks{ListGracePeriod: telemetry.Duration(2 * time.Second)})
+
+	tracked := &trackedNote{createdAt: time.Now()}
+	require.False(t, al.listGraceElapsed(tracked, tracked.createdAt.Add(time.Second)))
+	require.True(t, al.listGraceElapsed(tracked, tracked.createdAt.Add(2*time.Second)))
+
+	al.checks.ListGracePeriod = 0
+	require.True(t, al.listGraceElapsed(tracked, tracked.createdAt))
+}
@@ -0,0 +1,293 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+const (
+	// defaultControlInterval is how often the closed-loop controller measures latency and adjusts the concurrency
+	defaultControlInterval = 5 * time.Second
+	// concurrencyBackoff is the share of workers kept when the p99 exceeded its target
+	concurrencyBackoff = 0.75
+	// idleWorkerPollInterval is how often workers above the current concurrency check whether they may run again
+	idleWorkerPollInterval = 100 * time.Millisecond
+	// minTargetP99Accounts is the number of accounts below which a target p99 is unlikely to find the saturation point,
+	// as the controller cannot add more workers than there are accounts
+	minTargetP99Accounts = 20
+)
+
+// ClosedLoop configures closed-loop load: workers send their next operation as soon as the previous one completed,
+// each on behalf of an account no other worker is using, so there are never more workers than accounts.
+// With a target p99, a controller adds a worker every interval while the p99 stays within the target and
+// removes a quarter of them when it is exceeded, settling at the saturation point of the system.
+type ClosedLoop struct {
+	Concurrency int                `json:"concurrency"`         // Workers to start with, kept throughout without a target p99
+	TargetP99   telemetry.Duration `json:"targetP99,omitempty"` // p99 operation latency to stay within, 0 for a fixed concurrency
+	Interval    telemetry.Duration `json:"interval,omitempty"`  // How often the controller measures and adjusts, defaults to 5s
+}
+
+// Validate checks that all parameters are in range
+func (c ClosedLoop) Validate() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", c.Concurrency)
+	}
+	if c.TargetP99 < 0 {
+		return errors.New("target p99 must not be negative")
+	}
+	if c.Interval < 0 {
+		return errors.New("control interval must not be negative")
+	}
+	return nil
+}
+
+// CurvePoint is the throughput and latency of operations measured while running at one concurrency
+type CurvePoint struct {
+	Concurrency int                `json:"concurrency"`
+	Duration    telemetry.Duration `json:"duration"` // Time spent at this concurrency
+	Operations  int                `json:"operations"`
+	Failed      int                `json:"failed"`
+	Throughput  float64            `json:"throughput"` // Completed operations per second
+	P50         telemetry.Duration `json:"p50"`
+	P99         telemetry.Duration `json:"p99"`
+}
+
+// LoadCurve is the throughput-versus-latency curve of a closed-loop run, ordered by concurrency
+type LoadCurve struct {
+	TargetP99  telemetry.Duration `json:"targetP99,omitempty"`
+	Points     []CurvePoint       `json:"points"`
+	Saturation *CurvePoint        `json:"saturation,omitempty"` // Highest throughput within the target p99, or overall without one
+}
+
+// concurrencyLevel accumulates the operations completed while running at one concurrency
+type concurrencyLevel struct {
+	duration   time.Duration
+	operations telemetry.Histogram
+	failed     int
+}
+
+// closedLoopController measures operation latency and adjusts how many workers run
+type closedLoopController struct {
+	config         ClosedLoop
+	maxConcurrency int
+	concurrency    atomic.Int64
+
+	mu     sync.Mutex
+	window telemetry.Histogram // Operations completed in the current interval
+	failed int
+	levels map[int]*concurrencyLevel
+}
+
+// newClosedLoopController starts at the configured concurrency, capped at the number of accounts
+func newClosedLoopController(config ClosedLoop, accounts int) *closedLoopController {
+	if config.Interval == 0 {
+		config.Interval = telemetry.Duration(defaultControlInterval)
+	}
+
+	c := &closedLoopController{
+		config:         config,
+		maxConcurrency: accounts,
+		levels:         make(map[int]*concurrencyLevel),
+	}
+	c.concurrency.Store(int64(min(config.Concurrency, accounts)))
+	return c
+}
+
+// active returns true if the worker runs at the current concurrency
+func (c *closedLoopController) active(worker int) bool {
+	return int64(worker) < c.concurrency.Load()
+}
+
+// observe records a completed operation
+func (c *closedLoopController) observe(duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.window.Observe(duration)
+	if err != nil {
+		c.failed++
+	}
+}
+
+// adjust closes the interval that just elapsed, attributing its operations to the concurrency it ran at,
+// and sets the concurrency of the next interval. It returns the measurements of the closed interval.
+func (c *closedLoopController) adjust(elapsed time.Duration) CurvePoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	concurrency := int(c.concurrency.Load())
+	level, ok := c.levels[concurrency]
+	if !ok {
+		level = &concurrencyLevel{}
+		c.levels[concurrency] = level
+	}
+	level.duration += elapsed
+	level.operations.Merge(c.window)
+	level.failed += c.failed
+
+	point := newCurvePoint(concurrency, elapsed, c.window, c.failed)
+	c.window = telemetry.Histogram{}
+	c.failed = 0
+
+	// Intervals without completed operations, e.g. while accounts create their initial notes, say nothing about latency
+	if c.config.TargetP99 == 0 || point.Operations == 0 {
+		return point
+	}
+
+	next := concurrency + 1
+	if point.P99 > c.config.TargetP99 {
+		next = int(float64(concurrency) * concurrencyBackoff)
+	}
+	c.concurrency.Store(int64(min(max(next, 1), c.maxConcurrency)))
+	return point
+}
+
+// curve returns the measurements of every concurrency the controller ran at
+func (c *closedLoopController) curve() LoadCurve {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	curve := LoadCurve{TargetP99: c.config.TargetP99}
+	for concurrency, level := range c.levels {
+		if level.operations.Count > 0 {
+			curve.Points = append(curve.Points, newCurvePoint(concurrency, level.duration, level.operations, level.failed))
+		}
+	}
+	slices.SortFunc(curve.Points, func(a, b CurvePoint) int {
+		return a.Concurrency - b.Concurrency
+	})
+
+	for i, point := range curve.Points {
+		if curve.TargetP99 > 0 && point.P99 > curve.TargetP99 {
+			continue
+		}
+		if curve.Saturation == nil || point.Throughput > curve.Saturation.Throughput {
+			curve.Saturation = &curve.Points[i]
+		}
+	}
+	return curve
+}
+
+func newCurvePoint(concurrency int, duration time.Duration, operations telemetry.Histogram, failed int) CurvePoint {
+	point := CurvePoint{
+		Concurrency: concurrency,
+		Duration:    telemetry.Duration(duration),
+		Operations:  operations.Count,
+		Failed:      failed,
+		P50:         telemetry.Duration(operations.P50()),
+		P99:         telemetry.Duration(operations.P99()),
+	}
+	if duration > 0 {
+		point.Throughput = float64(operations.Count) / duration.Seconds()
+	}
+	return point
+}
+
+// runClosedLoopWorker sends operations back to back on behalf of idle accounts while the worker is active
+func (s *Simulator) runClosedLoopWorker(worker int) {
+	defer s.wg.Done()
+
+	for {
+		if !s.closedLoop.active(worker) {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(idleWorkerPollInterval):
+				continue
+			}
+		}
+
+		var accountLoop *AccountLoop
+		select {
+		case <-s.ctx.Done():
+			return
+		case accountLoop = <-s.idle:
+		}
+
+		start := time.Now()
+		err := accountLoop.perform()
+		s.closedLoop.observe(time.Since(start), err)
+		s.idle <- accountLoop
+	}
+}
+
+// runClosedLoopController measures and adjusts the concurrency every interval until the simulator stops
+func (s *Simulator) runClosedLoopController() {
+	defer s.wg.Done()
+
+	interval := time.Duration(s.closedLoop.config.Interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			point := s.closedLoop.adjust(now.Sub(last))
+			last = now
+
+			s.logger.Debug("Closed-loop interval",
+				"concurrency", point.Concurrency,
+				"next_concurrency", s.closedLoop.concurrency.Load(),
+				"throughput", fmt.Sprintf("%.1f/s", point.Throughput),
+				"p50", time.Duration(point.P50),
+				"p99", time.Duration(point.P99),
+			)
+		}
+	}
+}
+
+// reportLoadCurve logs the throughput-versus-latency curve and writes it to the configured file
+func (s *Simulator) reportLoadCurve() {
+	curve := s.closedLoop.curve()
+	for _, point := range curve.Points {
+		s.logger.Info("Load curve",
+			"concurrency", point.Concurrency,
+			"throughput", fmt.Sprintf("%.1f/s", point.Throughput),
+			"p50", time.Duration(point.P50),
+			"p99", time.Duration(point.P99),
+			"operations", point.Operations,
+			"failed", point.Failed,
+			"duration", time.Duration(point.Duration).Round(time.Second),
+		)
+	}
+
+	if saturation := curve.Saturation; saturation != nil {
+		s.logger.Info("Saturation point",
+			"concurrency", saturation.Concurrency,
+			"throughput", fmt.Sprintf("%.1f/s", saturation.Throughput),
+			"p99", time.Duration(saturation.P99),
+			"target_p99", time.Duration(curve.TargetP99),
+		)
+		s.telemetry.RecordEvent("Saturated at concurrency %d with %.1f operations/s and p99 %s",
+			saturation.Concurrency, saturation.Throughput, time.Duration(saturation.P99))
+	} else if len(curve.Points) > 0 {
+		s.logger.Warn("No concurrency stayed within the target p99", "target_p99", time.Duration(curve.TargetP99))
+	}
+
+	if s.options.LoadCurveFile == "" {
+		return
+	}
+	if err := writeLoadCurve(s.options.LoadCurveFile, curve); err != nil {
+		s.logger.Error("Failed to write load curve", "path", s.options.LoadCurveFile, "error", err)
+	}
+}
+
+// writeLoadCurve writes the curve as indented JSON
+func writeLoadCurve(path string, curve LoadCurve) error {
+	data, err := json.MarshalIndent(curve, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,102 @@
+package loadgen
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClosedLoopControllerAdjustsConcurrency(t *testing.T) {
+	c := newClosedLoopController(ClosedLoop{Concurrency: 4, TargetP99: telemetry.Duration(50 * time.Millisecond)}, 10)
+	require.Equal(t, telemetry.Duration(defaultControlInterval), c.config.Interval)
+
+	// Intervals without operations keep the concurrency
+	c.adjust(time.Second)
+	require.EqualValues(t, 4, c.concurrency.Load())
+
+	// Within the target, one worker is added per interval
+	for range 10 {
+		c.observe(10*time.Millisecond, nil)
+	}
+	point := c.adjust(time.Second)
+	require.Equal(t, 4, point.Concurrency)
+	require.Equal(t, 10.0, point.Throughput)
+	require.EqualValues(t, 5, c.concurrency.Load())
+	require.True(t, c.active(4))
+	require.False(t, c.active(5))
+
+	// Exceeding the target removes a quarter of the workers
+	c.observe(200*time.Millisecond, errors.New("timeout"))
+	point = c.adjust(time.Second)
+	require.Equal(t, 1, point.Failed)
+	require.EqualValues(t, 3, c.concurrency.Load())
+
+	// Concurrency never drops below one worker or exceeds the accounts
+	for range 5 {
+		c.observe(time.Second, nil)
+		c.adjust(time.Second)
+	}
+	require.EqualValues(t, 1, c.concurrency.Load())
+
+	capped := newClosedLoopController(ClosedLoop{Concurrency: 3, TargetP99: telemetry.Duration(time.Second)}, 3)
+	capped.observe(time.Millisecond, nil)
+	capped.adjust(time.Second)
+	require.EqualValues(t, 3, capped.concurrency.Load())
+}
+
+func TestClosedLoopControllerFixedConcurrency(t *testing.T) {
+	c := newClosedLoopController(ClosedLoop{Concurrency: 8}, 5)
+	require.EqualValues(t, 5, c.concurrency.Load(), "Expected concurrency to be capped at the number of accounts")
+
+	c.observe(time.Second, nil)
+	c.adjust(time.Second)
+	require.EqualValues(t, 5, c.concurrency.Load(), "Expected concurrency to stay fixed without a target p99")
+}
+
+func TestClosedLoopCurve(t *testing.T) {
+	c := newClosedLoopController(ClosedLoop{Concurrency: 1, TargetP99: telemetry.Duration(100 * time.Millisecond)}, 10)
+
+	// Concurrency 1 and 2 stay within the target, 3 exceeds it with the highest throughput
+	steps := []struct {
+		operations int
+		latency    time.Duration
+	}{
+		{10, 10 * time.Millisecond},
+		{18, 20 * time.Millisecond},
+		{20, 300 * time.Millisecond},
+	}
+	for _, step := range steps {
+		for range step.operations {
+			c.observe(step.latency, nil)
+		}
+		c.adjust(time.Second)
+	}
+
+	// Operations at a concurrency the controller returns to are added to its point
+	for range 9 {
+		c.observe(20*time.Millisecond, nil)
+	}
+	c.adjust(time.Second)
+
+	curve := c.curve()
+	require.Len(t, curve.Points, 3)
+	require.Equal(t, []int{1, 2, 3}, []int{curve.Points[0].Concurrency, curve.Points[1].Concurrency, curve.Points[2].Concurrency})
+	require.Equal(t, 27, curve.Points[1].Operations)
+	require.Equal(t, 13.5, curve.Points[1].Throughput)
+
+	require.NotNil(t, curve.Saturation)
+	require.Equal(t, 2, curve.Saturation.Concurrency, "Expected the highest throughput within the target p99")
+
+	c.config.TargetP99 = 0
+	require.Equal(t, 3, c.curve().Saturation.Concurrency, "Expected the highest throughput without a target p99")
+}
+
+func TestClosedLoopValidate(t *testing.T) {
+	require.NoError(t, ClosedLoop{Concurrency: 1}.Validate())
+	require.Error(t, ClosedLoop{}.Validate())
+	require.Error(t, ClosedLoop{Concurrency: 1, TargetP99: -1}.Validate())
+	require.Error(t, ClosedLoop{Concurrency: 1, Interval: -1}.Validate())
+}
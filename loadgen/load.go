@@ -0,0 +1,97 @@
+package loadgen
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+)
+
+// loadPausedPollInterval is how often paused account loops check whether load was resumed
+const loadPausedPollInterval = time.Second
+
+// Operations lists all operations in the order weights are applied, so selection does not depend on map order
+var Operations = []Operation{OpCreate, OpRead, OpUpdate, OpDelete, OpList}
+
+// OperationWeights is a fixed mix of operations, e.g. {"update": 80, "read": 20} for a write spike
+type OperationWeights map[Operation]int
+
+// weightedOperation is the element type weightedRandomSelect takes
+type weightedOperation = struct {
+	op     Operation
+	weight int
+}
+
+// list returns the weights in the order of Operations
+func (w OperationWeights) list() []weightedOperation {
+	weights := make([]weightedOperation, 0, len(Operations))
+	for _, op := range Operations {
+		weights = append(weights, weightedOperation{op, w[op]})
+	}
+	return weights
+}
+
+// loadProfile is the load generated by every account loop. Scenario phases change it while the loops run.
+type loadProfile struct {
+	mu              sync.RWMutex
+	requestsPerMin  int
+	notesPerAccount int
+	weights         OperationWeights
+}
+
+func newLoadProfile(requestsPerMin, notesPerAccount int) *loadProfile {
+	return &loadProfile{
+		requestsPerMin:  requestsPerMin,
+		notesPerAccount: notesPerAccount,
+	}
+}
+
+// interval returns the time between requests of one account, or zero while load is paused
+func (p *loadProfile) interval() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.requestsPerMin <= 0 {
+		return 0
+	}
+	return time.Duration(constants.MillisecondsPerMinute/p.requestsPerMin) * time.Millisecond
+}
+
+// requestsPerMinute returns the requests per minute of every account
+func (p *loadProfile) requestsPerMinute() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.requestsPerMin
+}
+
+// targetNoteCount returns the number of notes every account keeps
+func (p *loadProfile) targetNoteCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.notesPerAccount
+}
+
+// operationWeights returns the fixed operation mix, or nil if operations adapt to the note count
+func (p *loadProfile) operationWeights() OperationWeights {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.weights
+}
+
+func (p *loadProfile) setRequestsPerMin(requestsPerMin int) {
+	p.mu.Lock()
+	p.requestsPerMin = requestsPerMin
+	p.mu.Unlock()
+}
+
+func (p *loadProfile) setNotesPerAccount(notesPerAccount int) {
+	p.mu.Lock()
+	p.notesPerAccount = notesPerAccount
+	p.mu.Unlock()
+}
+
+func (p *loadProfile) setWeights(weights OperationWeights) {
+	p.mu.Lock()
+	p.weights = weights
+	p.mu.Unlock()
+}
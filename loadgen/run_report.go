@@ -0,0 +1,353 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+)
+
+const (
+	// runReportTimeout bounds retrieving the migration progress of all accounts for the run report
+	runReportTimeout = 30 * time.Second
+	// allOperations labels the totals of all operation types in the run report
+	allOperations Operation = "all"
+)
+
+// RunReport summarizes a load generator run once it stopped.
+// It is written as JSON and Markdown, so runs on different branches can be diffed.
+type RunReport struct {
+	Seed              int64              `json:"seed"`
+	Duration          telemetry.Duration `json:"duration"`
+	Operations        []OperationReport  `json:"operations"` // Per operation type, followed by the totals of all operations
+	Errors            []ErrorReport      `json:"errors"`
+	ConsistencyMisses int                `json:"consistencyMisses"`
+	Proxies           []ProxyTraffic     `json:"proxies"`  // Requests served per data proxy version, ordered by version
+	Accounts          []AccountReport    `json:"accounts"` // In the order accounts were created
+}
+
+// OperationReport counts the operations of one type and their latency
+type OperationReport struct {
+	Operation Operation          `json:"operation"`
+	Count     int                `json:"count"`
+	Failed    int                `json:"failed"`
+	P50       telemetry.Duration `json:"p50"`
+	P99       telemetry.Duration `json:"p99"`
+}
+
+// ErrorReport counts the failed operations of one type by why they failed, e.g. "HTTP 503" or "timeout"
+type ErrorReport struct {
+	Operation Operation `json:"operation"`
+	Kind      string    `json:"kind"`
+	Count     int       `json:"count"`
+}
+
+// ProxyTraffic is the share of requests a data proxy version served
+type ProxyTraffic struct {
+	ProxyID  int     `json:"proxyId"`
+	Requests int     `json:"requests"`
+	Share    float64 `json:"share"`
+}
+
+// AccountReport is the consistency misses of an account and how far its notes were migrated
+type AccountReport struct {
+	AccountID         uuid.UUID                          `json:"accountId"`
+	MigrationState    store.MigrationState               `json:"migrationState,omitempty"`
+	Shard             string                             `json:"shard,omitempty"`
+	LegacyNotes       int                                `json:"legacyNotes"`
+	ShardNotes        int                                `json:"shardNotes"`
+	ConsistencyMisses map[telemetry.ConsistencyCheck]int `json:"consistencyMisses,omitempty"` // Per check that detected them
+	Error             string                             `json:"error,omitempty"`             // Why the migration progress is unknown
+}
+
+// errorKey identifies the failures counted in an ErrorReport
+type errorKey struct {
+	operation Operation
+	kind      string
+}
+
+// runStats collects what the run report covers while the load generator runs. It is safe for concurrent use.
+type runStats struct {
+	start time.Time
+
+	mu         sync.Mutex
+	operations map[Operation]*operationStats
+	errors     map[errorKey]int
+	servedBy   map[int]int
+	misses     map[uuid.UUID]map[telemetry.ConsistencyCheck]int
+}
+
+// operationStats is the latency of all operations of one type and how many of them failed
+type operationStats struct {
+	latency telemetry.Histogram
+	failed  int
+}
+
+func newRunStats() *runStats {
+	return &runStats{
+		start:      time.Now(),
+		operations: make(map[Operation]*operationStats),
+		errors:     make(map[errorKey]int),
+		servedBy:   make(map[int]int),
+		misses:     make(map[uuid.UUID]map[telemetry.ConsistencyCheck]int),
+	}
+}
+
+// observeOperation records the latency of an operation and why it failed, if it did
+func (rs *runStats) observeOperation(op Operation, duration time.Duration, err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	stats, ok := rs.operations[op]
+	if !ok {
+		stats = &operationStats{}
+		rs.operations[op] = stats
+	}
+	stats.latency.Observe(duration)
+	if err != nil {
+		stats.failed++
+		rs.errors[errorKey{operation: op, kind: errorKind(err)}]++
+	}
+}
+
+// observeResponse counts the data proxy that served a request, ignoring requests no proxy served
+func (rs *runStats) observeResponse(info restapi.ResponseInfo) {
+	if info.ServedBy == 0 {
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.servedBy[info.ServedBy]++
+}
+
+// observeMiss counts a consistency miss of an account
+func (rs *runStats) observeMiss(accountID uuid.UUID, check telemetry.ConsistencyCheck) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.misses[accountID] == nil {
+		rs.misses[accountID] = make(map[telemetry.ConsistencyCheck]int)
+	}
+	rs.misses[accountID][check]++
+}
+
+// errorKind classifies why an operation failed
+func errorKind(err error) string {
+	var apiErr *restapi.APIError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("HTTP %d", apiErr.Status)
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &urlErr):
+		if urlErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+// report summarizes the collected stats. Accounts are listed in the given order, without their migration progress.
+func (rs *runStats) report(seed int64, accountIDs []uuid.UUID) *RunReport {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	report := &RunReport{
+		Seed:       seed,
+		Duration:   telemetry.Duration(time.Since(rs.start).Round(time.Millisecond)),
+		Operations: []OperationReport{},
+		Errors:     []ErrorReport{},
+		Proxies:    []ProxyTraffic{},
+		Accounts:   []AccountReport{},
+	}
+
+	var all operationStats
+	for _, op := range Operations {
+		stats, ok := rs.operations[op]
+		if !ok {
+			continue
+		}
+		report.Operations = append(report.Operations, operationReport(op, stats))
+		all.latency.Merge(stats.latency)
+		all.failed += stats.failed
+	}
+	report.Operations = append(report.Operations, operationReport(allOperations, &all))
+
+	for key, count := range rs.errors {
+		report.Errors = append(report.Errors, ErrorReport{Operation: key.operation, Kind: key.kind, Count: count})
+	}
+	slices.SortFunc(report.Errors, func(a, b ErrorReport) int {
+		if a.Operation != b.Operation {
+			return slices.Index(Operations, a.Operation) - slices.Index(Operations, b.Operation)
+		}
+		return strings.Compare(a.Kind, b.Kind)
+	})
+
+	requests := 0
+	for _, count := range rs.servedBy {
+		requests += count
+	}
+	for _, proxyID := range slices.Sorted(maps.Keys(rs.servedBy)) {
+		count := rs.servedBy[proxyID]
+		report.Proxies = append(report.Proxies, ProxyTraffic{
+			ProxyID:  proxyID,
+			Requests: count,
+			Share:    float64(count) / float64(requests),
+		})
+	}
+
+	for _, accountID := range accountIDs {
+		account := AccountReport{AccountID: accountID, ConsistencyMisses: maps.Clone(rs.misses[accountID])}
+		for _, count := range account.ConsistencyMisses {
+			report.ConsistencyMisses += count
+		}
+		report.Accounts = append(report.Accounts, account)
+	}
+
+	return report
+}
+
+func operationReport(op Operation, stats *operationStats) OperationReport {
+	return OperationReport{
+		Operation: op,
+		Count:     stats.latency.Count,
+		Failed:    stats.failed,
+		P50:       telemetry.Duration(stats.latency.P50()),
+		P99:       telemetry.Duration(stats.latency.P99()),
+	}
+}
+
+// reportRun retrieves the migration progress of all accounts and writes the run report to the configured file
+func (s *Simulator) reportRun() {
+	accountIDs := make([]uuid.UUID, len(s.accounts))
+	for i, accountLoop := range s.accounts {
+		accountIDs[i] = accountLoop.accountID
+	}
+	report := s.stats.report(s.options.Seed, accountIDs)
+
+	// The simulator's context is canceled once stopping, the API is still up
+	ctx, cancel := context.WithTimeout(context.Background(), runReportTimeout)
+	defer cancel()
+	for i := range report.Accounts {
+		account := &report.Accounts[i]
+		progress, err := s.apiClient.GetMigrationProgress(ctx, account.AccountID)
+		if err != nil {
+			account.Error = err.Error()
+			continue
+		}
+		account.MigrationState = progress.MigrationState
+		account.Shard = progress.Shard
+		account.LegacyNotes = progress.LegacyNotes
+		account.ShardNotes = progress.ShardNotes
+	}
+
+	jsonPath, markdownPath := runReportPaths(s.options.RunReportFile)
+	if err := writeRunReport(jsonPath, markdownPath, report); err != nil {
+		s.logger.Error("Could not write run report", "error", err)
+		return
+	}
+	s.logger.Info("Wrote run report", "json", jsonPath, "markdown", markdownPath)
+}
+
+// runReportPaths returns where the JSON and Markdown versions of the run report are written.
+// The Markdown version replaces the extension of the path with .md.
+func runReportPaths(path string) (string, string) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	if filepath.Ext(path) == ".md" {
+		return base + ".json", path
+	}
+	return path, base + ".md"
+}
+
+// writeRunReport writes the report as indented JSON and as Markdown
+func writeRunReport(jsonPath, markdownPath string, report *RunReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(markdownPath, []byte(report.Markdown()), 0644)
+}
+
+// Markdown renders the report as Markdown tables
+func (r *RunReport) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Load generator run\n\n")
+	fmt.Fprintf(&b, "Seed %d, ran for %s, %d consistency misses.\n", r.Seed, time.Duration(r.Duration), r.ConsistencyMisses)
+
+	fmt.Fprintf(&b, "\n## Operations\n\n")
+	fmt.Fprintf(&b, "| Operation | Count | Failed | p50 | p99 |\n|---|---:|---:|---:|---:|\n")
+	for _, op := range r.Operations {
+		fmt.Fprintf(&b, "| %s | %d | %d | %s | %s |\n", op.Operation, op.Count, op.Failed, time.Duration(op.P50), time.Duration(op.P99))
+	}
+
+	fmt.Fprintf(&b, "\n## Errors\n\n")
+	if len(r.Errors) == 0 {
+		fmt.Fprintf(&b, "No operation failed.\n")
+	} else {
+		fmt.Fprintf(&b, "| Operation | Error | Count |\n|---|---|---:|\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", e.Operation, e.Kind, e.Count)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Traffic per proxy version\n\n")
+	if len(r.Proxies) == 0 {
+		fmt.Fprintf(&b, "No request was served by a data proxy.\n")
+	} else {
+		fmt.Fprintf(&b, "| Proxy | Requests | Share |\n|---|---:|---:|\n")
+		for _, p := range r.Proxies {
+			fmt.Fprintf(&b, "| v%d | %d | %.1f%% |\n", p.ProxyID, p.Requests, p.Share*100)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Accounts\n\n")
+	fmt.Fprintf(&b, "| Account | Migration state | Shard | Legacy notes | Shard notes | Consistency misses |\n|---|---|---|---:|---:|---|\n")
+	for _, a := range r.Accounts {
+		state, shard := string(a.MigrationState), a.Shard
+		if a.Error != "" {
+			state = "unknown: " + strings.ReplaceAll(a.Error, "|", "\\|")
+		}
+		if shard == "" {
+			shard = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %s |\n", a.AccountID, state, shard, a.LegacyNotes, a.ShardNotes, formatMisses(a.ConsistencyMisses))
+	}
+
+	return b.String()
+}
+
+// formatMisses lists consistency misses per check, e.g. "list_missing: 1, read: 2", or "-" for none
+func formatMisses(misses map[telemetry.ConsistencyCheck]int) string {
+	if len(misses) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(misses))
+	for _, check := range slices.Sorted(maps.Keys(misses)) {
+		parts = append(parts, fmt.Sprintf("%s: %d", check, misses[check]))
+	}
+	return strings.Join(parts, ", ")
+}
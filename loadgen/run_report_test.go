@@ -0,0 +1,108 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKind(t *testing.T) {
+	require.Equal(t, "HTTP 503", errorKind(fmt.Errorf("failed to read note: %w", &restapi.APIError{Status: 503, Message: "no proxy available"})))
+	require.Equal(t, "canceled", errorKind(fmt.Errorf("request failed: %w", context.Canceled)))
+	require.Equal(t, "timeout", errorKind(fmt.Errorf("request failed: %w", context.DeadlineExceeded)))
+	require.Equal(t, "network", errorKind(&url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")}))
+	require.Equal(t, "other", errorKind(errors.New("could not retrieve note")))
+}
+
+func TestRunStatsReport(t *testing.T) {
+	stats := newRunStats()
+	accounts := []uuid.UUID{uuid.New(), uuid.New()}
+
+	for range 9 {
+		stats.observeOperation(OpRead, 10*time.Millisecond, nil)
+	}
+	stats.observeOperation(OpRead, time.Second, &restapi.APIError{Status: 500})
+	stats.observeOperation(OpCreate, 20*time.Millisecond, context.DeadlineExceeded)
+	stats.observeOperation(OpCreate, 20*time.Millisecond, context.DeadlineExceeded)
+
+	stats.observeResponse(restapi.ResponseInfo{ServedBy: 2})
+	stats.observeResponse(restapi.ResponseInfo{ServedBy: 1})
+	stats.observeResponse(restapi.ResponseInfo{ServedBy: 2})
+	stats.observeResponse(restapi.ResponseInfo{ServedBy: 2})
+	stats.observeResponse(restapi.ResponseInfo{}) // Not served by a proxy
+
+	stats.observeMiss(accounts[1], telemetry.ConsistencyCheckRead)
+	stats.observeMiss(accounts[1], telemetry.ConsistencyCheckListMissing)
+	stats.observeMiss(accounts[1], telemetry.ConsistencyCheckRead)
+
+	report := stats.report(7, accounts)
+	require.Equal(t, int64(7), report.Seed)
+
+	require.Equal(t, []Operation{OpCreate, OpRead, allOperations}, []Operation{
+		report.Operations[0].Operation, report.Operations[1].Operation, report.Operations[2].Operation,
+	}, "Expected operations in their usual order, followed by the totals")
+	read := report.Operations[1]
+	require.Equal(t, 10, read.Count)
+	require.Equal(t, 1, read.Failed)
+	require.InDelta(t, float64(10*time.Millisecond), float64(read.P50), float64(time.Millisecond))
+	require.InDelta(t, float64(time.Second), float64(read.P99), float64(70*time.Millisecond))
+	require.Equal(t, OperationReport{Operation: allOperations, Count: 12, Failed: 3, P50: read.P50, P99: read.P99}, report.Operations[2])
+
+	require.Equal(t, []ErrorReport{
+		{Operation: OpCreate, Kind: "timeout", Count: 2},
+		{Operation: OpRead, Kind: "HTTP 500", Count: 1},
+	}, report.Errors)
+
+	require.Equal(t, []ProxyTraffic{
+		{ProxyID: 1, Requests: 1, Share: 0.25},
+		{ProxyID: 2, Requests: 3, Share: 0.75},
+	}, report.Proxies)
+
+	require.Equal(t, 3, report.ConsistencyMisses)
+	require.Empty(t, report.Accounts[0].ConsistencyMisses)
+	require.Equal(t, map[telemetry.ConsistencyCheck]int{telemetry.ConsistencyCheckRead: 2, telemetry.ConsistencyCheckListMissing: 1}, report.Accounts[1].ConsistencyMisses)
+}
+
+func TestRunReportMarkdown(t *testing.T) {
+	accountID := uuid.MustParse("6f1c1c66-0f5a-4bd8-9d6d-0c1f4e0e6a3b")
+	report := &RunReport{
+		Seed:       7,
+		Duration:   telemetry.Duration(time.Minute),
+		Operations: []OperationReport{{Operation: allOperations, Count: 2, P50: telemetry.Duration(time.Millisecond), P99: telemetry.Duration(2 * time.Millisecond)}},
+		Proxies:    []ProxyTraffic{{ProxyID: 2, Requests: 2, Share: 1}},
+		Accounts: []AccountReport{
+			{AccountID: accountID, MigrationState: "dual_write", Shard: "shard1", LegacyNotes: 3, ShardNotes: 2, ConsistencyMisses: map[telemetry.ConsistencyCheck]int{telemetry.ConsistencyCheckRead: 1}},
+			{AccountID: accountID, Error: "API error (500): no proxy | down"},
+		},
+	}
+
+	markdown := report.Markdown()
+	require.Contains(t, markdown, "Seed 7, ran for 1m0s, 0 consistency misses.")
+	require.Contains(t, markdown, "| all | 2 | 0 | 1ms | 2ms |")
+	require.Contains(t, markdown, "No operation failed.")
+	require.Contains(t, markdown, "| v2 | 2 | 100.0% |")
+	require.Contains(t, markdown, "| 6f1c1c66-0f5a-4bd8-9d6d-0c1f4e0e6a3b | dual_write | shard1 | 3 | 2 | read: 1 |")
+	require.Contains(t, markdown, `| unknown: API error (500): no proxy \| down | - |`, "Expected pipes in errors to be escaped")
+}
+
+func TestRunReportPaths(t *testing.T) {
+	jsonPath, markdownPath := runReportPaths("reports/run.json")
+	require.Equal(t, "reports/run.json", jsonPath)
+	require.Equal(t, "reports/run.md", markdownPath)
+
+	jsonPath, markdownPath = runReportPaths("run.md")
+	require.Equal(t, "run.json", jsonPath)
+	require.Equal(t, "run.md", markdownPath)
+
+	jsonPath, markdownPath = runReportPaths("run")
+	require.Equal(t, "run", jsonPath)
+	require.Equal(t, "run.md", markdownPath)
+}
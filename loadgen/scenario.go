@@ -0,0 +1,207 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+// ScenarioActionType is something a scenario does once at the start of a phase
+type ScenarioActionType string
+
+const (
+	ActionDeploy            ScenarioActionType = "deploy"             // Roll out a new data proxy
+	ActionMigrate           ScenarioActionType = "migrate"            // Advance accounts to a migration state, assigning shards as needed
+	ActionRollbackMigration ScenarioActionType = "rollback_migration" // Move accounts back by one migration phase
+	ActionAbandonNotes      ScenarioActionType = "abandon_notes"      // Stop using all notes of accounts, leaving them in the stores
+	ActionPartition         ScenarioActionType = "partition"          // Partition the API from a data proxy
+	ActionHeal              ScenarioActionType = "heal"               // Remove all partitions between the API and data proxies
+)
+
+// Scenario scripts a load generator run as timed phases and the expectations it must meet
+type Scenario struct {
+	Name            string               `json:"name"`
+	Accounts        int                  `json:"accounts,omitempty"`        // Overrides --concurrency
+	NotesPerAccount int                  `json:"notesPerAccount,omitempty"` // Overrides --notes-per-account
+	Seed            int64                `json:"seed,omitempty"`            // Used unless --seed is passed, so drills repeat the same operations
+	Workload        *Workload            `json:"workload,omitempty"`        // Overrides the workload flags
+	Checks          *ConsistencyChecks   `json:"checks,omitempty"`          // Overrides --checks and --list-grace
+	Phases          []ScenarioPhase      `json:"phases"`
+	Expect          ScenarioExpectations `json:"expect"`
+}
+
+// ScenarioPhase runs its actions, then generates load for its duration.
+// The request rate and note count carry over to later phases, the operation mix does not.
+type ScenarioPhase struct {
+	Name            string             `json:"name"`
+	Duration        telemetry.Duration `json:"duration,omitempty"`        // Zero only runs the actions
+	RequestsPerMin  *int               `json:"rpm,omitempty"`             // Requests per minute of every account, 0 pauses load
+	RampTo          *int               `json:"rampTo,omitempty"`          // Requests per minute reached linearly at the end of the phase
+	NotesPerAccount int                `json:"notesPerAccount,omitempty"` // Notes every account keeps
+	Weights         OperationWeights   `json:"weights,omitempty"`         // Fixed operation mix, adapts to the note count if empty
+	Actions         []ScenarioAction   `json:"actions,omitempty"`
+}
+
+// ScenarioAction is run against a share of the simulated accounts, in the order accounts were created.
+// Deploys and partitions are run once.
+type ScenarioAction struct {
+	Type      ScenarioActionType   `json:"type"`
+	Fraction  float64              `json:"fraction,omitempty"`  // Share of accounts, defaults to all
+	State     store.MigrationState `json:"state,omitempty"`     // Migration state to advance to, defaults to dual_write
+	Proxy     string               `json:"proxy,omitempty"`     // Proxy to partition by ID, "current" or "previous", defaults to current
+	Partition *chaos.Partition     `json:"partition,omitempty"` // How requests to the proxy are faulted
+}
+
+// ScenarioExpectations decide whether a scenario passed. Misses and failed actions default to none allowed.
+type ScenarioExpectations struct {
+	MaxConsistencyMisses *int     `json:"maxConsistencyMisses,omitempty"`
+	MaxFailedActions     *int     `json:"maxFailedActions,omitempty"` // Accounts an action failed for, or failed deploys and partitions
+	MaxErrorRate         *float64 `json:"maxErrorRate,omitempty"`     // Share of failed operations, not checked by default
+}
+
+// LoadScenario reads a scenario from a JSON file, applying defaults
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read scenario file: %w", err)
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("could not decode scenario file: %w", err)
+	}
+
+	scenario.applyDefaults()
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %q: %w", scenario.Name, err)
+	}
+
+	return &scenario, nil
+}
+
+func (s *Scenario) applyDefaults() {
+	if s.Expect.MaxConsistencyMisses == nil {
+		s.Expect.MaxConsistencyMisses = new(int)
+	}
+	if s.Expect.MaxFailedActions == nil {
+		s.Expect.MaxFailedActions = new(int)
+	}
+
+	for i := range s.Phases {
+		phase := &s.Phases[i]
+		if phase.Name == "" {
+			phase.Name = fmt.Sprintf("phase %d", i+1)
+		}
+		for j := range phase.Actions {
+			action := &phase.Actions[j]
+			if action.Fraction == 0 {
+				action.Fraction = 1
+			}
+			if action.Type == ActionMigrate && action.State == "" {
+				action.State = store.MigrationStateDualWrite
+			}
+			if action.Type == ActionPartition && action.Proxy == "" {
+				action.Proxy = chaos.CurrentProxy
+			}
+		}
+	}
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Phases) == 0 {
+		return errors.New("at least one phase is required")
+	}
+	if s.Accounts < 0 || s.NotesPerAccount < 0 {
+		return errors.New("accounts and notesPerAccount must not be negative")
+	}
+
+	if s.Workload != nil {
+		if err := s.Workload.Validate(); err != nil {
+			return fmt.Errorf("workload: %w", err)
+		}
+	}
+	if s.Checks != nil {
+		if err := s.Checks.Validate(); err != nil {
+			return fmt.Errorf("checks: %w", err)
+		}
+	}
+
+	for _, phase := range s.Phases {
+		if err := phase.validate(); err != nil {
+			return fmt.Errorf("phase %q: %w", phase.Name, err)
+		}
+	}
+
+	if s.Expect.MaxErrorRate != nil && (*s.Expect.MaxErrorRate < 0 || *s.Expect.MaxErrorRate > 1) {
+		return fmt.Errorf("maxErrorRate must be between 0 and 1, got %v", *s.Expect.MaxErrorRate)
+	}
+	return nil
+}
+
+func (p ScenarioPhase) validate() error {
+	if p.Duration < 0 {
+		return errors.New("duration must not be negative")
+	}
+	if p.RequestsPerMin != nil && *p.RequestsPerMin < 0 {
+		return errors.New("rpm must not be negative")
+	}
+	if p.RampTo != nil {
+		if *p.RampTo < 0 {
+			return errors.New("rampTo must not be negative")
+		}
+		if p.Duration == 0 {
+			return errors.New("rampTo requires a duration")
+		}
+	}
+	if p.NotesPerAccount < 0 {
+		return errors.New("notesPerAccount must not be negative")
+	}
+
+	for op, weight := range p.Weights {
+		if !slices.Contains(Operations, op) {
+			return fmt.Errorf("unknown operation %q in weights", op)
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight of %s must not be negative", op)
+		}
+	}
+
+	for _, action := range p.Actions {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("action %s: %w", action.Type, err)
+		}
+	}
+	return nil
+}
+
+func (a ScenarioAction) validate() error {
+	switch a.Type {
+	case ActionDeploy, ActionRollbackMigration, ActionAbandonNotes, ActionHeal:
+	case ActionPartition:
+		if a.Partition == nil {
+			return errors.New("partition is required")
+		}
+		if err := a.Partition.Validate(); err != nil {
+			return err
+		}
+	case ActionMigrate:
+		// Accounts can be advanced up to done, rolling back is a separate action
+		index := slices.Index(store.MigrationStates, a.State)
+		if index <= slices.Index(store.MigrationStates, store.MigrationStateNotStarted) || index > slices.Index(store.MigrationStates, store.MigrationStateDone) {
+			return fmt.Errorf("cannot migrate accounts to state %q", a.State)
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+
+	if a.Fraction <= 0 || a.Fraction > 1 {
+		return fmt.Errorf("fraction must be between 0 and 1, got %v", a.Fraction)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+const (
+	// scenarioRampInterval is how often the request rate is adjusted while ramping
+	scenarioRampInterval = time.Second
+	// scenarioActionTimeout bounds the requests an action makes for a single account
+	scenarioActionTimeout = 30 * time.Second
+	// maxReportedActionErrors limits the errors kept per action in the report
+	maxReportedActionErrors = 5
+)
+
+// ScenarioReport is the result of running a scenario
+type ScenarioReport struct {
+	Scenario          string             `json:"scenario"`
+	Seed              int64              `json:"seed"` // Reproduces the run with --seed
+	Passed            bool               `json:"passed"`
+	Failures          []string           `json:"failures,omitempty"` // Expectations that were not met
+	Start             time.Time          `json:"start"`
+	Duration          telemetry.Duration `json:"duration"`
+	Operations        int64              `json:"operations"`
+	FailedOperations  int64              `json:"failedOperations"`
+	ConsistencyMisses int                `json:"consistencyMisses"`
+	FailedActions     int                `json:"failedActions"`
+	Phases            []PhaseReport      `json:"phases"`
+}
+
+// PhaseReport is the result of running a single phase
+type PhaseReport struct {
+	Name              string             `json:"name"`
+	Start             time.Time          `json:"start"`
+	Duration          telemetry.Duration `json:"duration"`
+	Operations        int64              `json:"operations"`
+	FailedOperations  int64              `json:"failedOperations"`
+	ConsistencyMisses int                `json:"consistencyMisses"`
+	Actions           []ActionReport     `json:"actions,omitempty"`
+}
+
+// ActionReport is the result of running an action
+type ActionReport struct {
+	Type     ScenarioActionType `json:"type"`
+	Accounts int                `json:"accounts"` // Accounts the action ran for, zero for deploys
+	Failed   int                `json:"failed"`
+	Errors   []string           `json:"errors,omitempty"` // The first errors, if any
+}
+
+// Err returns an error listing the failed expectations, or nil if the scenario passed
+func (r *ScenarioReport) Err() error {
+	if r == nil || r.Passed {
+		return nil
+	}
+	return fmt.Errorf("scenario %q failed: %v", r.Scenario, r.Failures)
+}
+
+// runScenario runs all phases of a scenario, then reports whether its expectations were met
+func (s *Simulator) runScenario(scenario Scenario) {
+	defer s.wg.Done()
+
+	s.logger.Info("Running scenario", "scenario", scenario.Name, "phases", len(scenario.Phases))
+	s.telemetry.RecordEvent("Scenario %q started", scenario.Name)
+
+	report := &ScenarioReport{Scenario: scenario.Name, Seed: s.options.Seed, Start: time.Now()}
+	for _, phase := range scenario.Phases {
+		phaseReport, completed := s.runPhase(phase)
+		report.Phases = append(report.Phases, phaseReport)
+		if !completed {
+			s.logger.Info("Scenario stopped before completing", "scenario", scenario.Name, "phase", phase.Name)
+			return
+		}
+	}
+	report.Duration = telemetry.Duration(time.Since(report.Start))
+	report.evaluate(scenario.Expect)
+
+	s.logReport(report)
+	if s.options.ScenarioReportFile != "" {
+		if err := writeScenarioReport(s.options.ScenarioReportFile, report); err != nil {
+			s.logger.Error("Could not write scenario report", "error", err)
+		}
+	}
+
+	s.done <- report.Err()
+}
+
+// abortScenario reports a scenario as failed without running it
+func (s *Simulator) abortScenario(err error) {
+	if s.options.Scenario == nil {
+		return
+	}
+	s.done <- (&ScenarioReport{Scenario: s.options.Scenario.Name, Failures: []string{err.Error()}}).Err()
+}
+
+// runPhase applies the load of a phase, runs its actions and waits for its duration.
+// It returns false if the simulator was stopped before the phase completed.
+func (s *Simulator) runPhase(phase ScenarioPhase) (PhaseReport, bool) {
+	s.logger.Info("Starting scenario phase", "phase", phase.Name, "duration", time.Duration(phase.Duration))
+	s.telemetry.RecordEvent("Scenario phase %q started", phase.Name)
+
+	report := PhaseReport{Name: phase.Name, Start: time.Now()}
+	operations := s.counts.total.Load()
+	failedOperations := s.counts.failed.Load()
+	misses := s.telemetry.StatsCollector.Export().ConsistencyMisses
+
+	if phase.RequestsPerMin != nil {
+		s.load.setRequestsPerMin(*phase.RequestsPerMin)
+	}
+	if phase.NotesPerAccount > 0 {
+		s.load.setNotesPerAccount(phase.NotesPerAccount)
+	}
+	s.load.setWeights(phase.Weights)
+
+	for _, action := range phase.Actions {
+		report.Actions = append(report.Actions, s.runAction(action))
+	}
+
+	completed := s.waitPhase(phase)
+
+	report.Duration = telemetry.Duration(time.Since(report.Start))
+	report.Operations = s.counts.total.Load() - operations
+	report.FailedOperations = s.counts.failed.Load() - failedOperations
+	report.ConsistencyMisses = s.telemetry.StatsCollector.Export().ConsistencyMisses - misses
+	return report, completed
+}
+
+// waitPhase waits for the duration of a phase, ramping the request rate if the phase asks for it
+func (s *Simulator) waitPhase(phase ScenarioPhase) bool {
+	duration := time.Duration(phase.Duration)
+	if phase.RampTo == nil {
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-time.After(duration):
+			return true
+		}
+	}
+
+	from := s.load.requestsPerMinute()
+	to := *phase.RampTo
+	start := time.Now()
+
+	ticker := time.NewTicker(scenarioRampInterval)
+	defer ticker.Stop()
+
+	for {
+		elapsed := time.Since(start)
+		if elapsed >= duration {
+			s.load.setRequestsPerMin(to)
+			return true
+		}
+		progress := float64(elapsed) / float64(duration)
+		s.load.setRequestsPerMin(from + int(math.Round(float64(to-from)*progress)))
+
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAction runs an action against the share of accounts it applies to
+func (s *Simulator) runAction(action ScenarioAction) ActionReport {
+	s.logger.Info("Running scenario action", "action", string(action.Type), "fraction", action.Fraction)
+	report := ActionReport{Type: action.Type}
+
+	fail := func(err error) {
+		report.Failed++
+		if len(report.Errors) < maxReportedActionErrors {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		s.logger.Warn("Scenario action failed", "action", string(action.Type), "error", err)
+	}
+
+	switch action.Type {
+	case ActionDeploy, ActionPartition, ActionHeal:
+		ctx, cancel := context.WithTimeout(s.ctx, scenarioActionTimeout)
+		defer cancel()
+		if err := s.runNetworkAction(ctx, action); err != nil {
+			fail(err)
+		}
+		return report
+	}
+
+	accounts := s.accounts[:int(math.Ceil(action.Fraction*float64(len(s.accounts))))]
+	report.Accounts = len(accounts)
+	for i, accountLoop := range accounts {
+		var err error
+		switch action.Type {
+		case ActionMigrate:
+			err = s.migrateAccount(accountLoop, action.State, constants.Shards[i%len(constants.Shards)])
+		case ActionRollbackMigration:
+			ctx, cancel := context.WithTimeout(s.ctx, scenarioActionTimeout)
+			_, err = s.apiClient.RollbackMigration(ctx, accountLoop.accountID)
+			cancel()
+		case ActionAbandonNotes:
+			accountLoop.abandonNotes()
+		}
+		if err != nil {
+			fail(fmt.Errorf("account %s: %w", accountLoop.accountID, err))
+		}
+	}
+	return report
+}
+
+// runNetworkAction deploys a new data proxy or changes the partitions between the API and data proxies
+func (s *Simulator) runNetworkAction(ctx context.Context, action ScenarioAction) error {
+	switch action.Type {
+	case ActionDeploy:
+		return s.apiClient.Deploy(ctx)
+	case ActionPartition:
+		fault, err := s.apiClient.PartitionProxy(ctx, action.Proxy, *action.Partition)
+		if err != nil {
+			return err
+		}
+		s.logger.Info("Partitioned proxy", "proxy", fault.ProxyID, "partition", action.Partition.String())
+		return nil
+	case ActionHeal:
+		return s.apiClient.HealPartitions(ctx)
+	}
+	return nil
+}
+
+// migrateAccount assigns a shard to an account that has none, then advances it until it reaches the target state
+func (s *Simulator) migrateAccount(accountLoop *AccountLoop, target store.MigrationState, shard string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, scenarioActionTimeout)
+	defer cancel()
+
+	account, err := s.apiClient.GetAccount(ctx, accountLoop.accountID)
+	if err != nil {
+		return err
+	}
+
+	if account.Shard == nil && account.MigrationState.Normalize() == store.MigrationStateNotStarted {
+		account.Shard = &shard
+		if account, err = s.apiClient.UpdateAccount(ctx, *account); err != nil {
+			return fmt.Errorf("could not assign shard: %w", err)
+		}
+	}
+
+	targetIndex := slices.Index(store.MigrationStates, target)
+	for slices.Index(store.MigrationStates, account.MigrationState.Normalize()) < targetIndex {
+		if account, err = s.apiClient.AdvanceMigration(ctx, account.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abandonNotes stops using all notes of the account, leaving them in the stores. New notes are created up to the target count.
+func (al *AccountLoop) abandonNotes() {
+	al.notesLock.Lock()
+	clear(al.notes)
+	al.notesLock.Unlock()
+}
+
+// evaluate checks the totals of all phases against the expectations of the scenario
+func (r *ScenarioReport) evaluate(expect ScenarioExpectations) {
+	for _, phase := range r.Phases {
+		r.Operations += phase.Operations
+		r.FailedOperations += phase.FailedOperations
+		r.ConsistencyMisses += phase.ConsistencyMisses
+		for _, action := range phase.Actions {
+			r.FailedActions += action.Failed
+		}
+	}
+
+	if expect.MaxConsistencyMisses != nil && r.ConsistencyMisses > *expect.MaxConsistencyMisses {
+		r.Failures = append(r.Failures, fmt.Sprintf("%d consistency misses, expected at most %d", r.ConsistencyMisses, *expect.MaxConsistencyMisses))
+	}
+	if expect.MaxFailedActions != nil && r.FailedActions > *expect.MaxFailedActions {
+		r.Failures = append(r.Failures, fmt.Sprintf("%d failed actions, expected at most %d", r.FailedActions, *expect.MaxFailedActions))
+	}
+	if expect.MaxErrorRate != nil && r.Operations > 0 {
+		errorRate := float64(r.FailedOperations) / float64(r.Operations)
+		if errorRate > *expect.MaxErrorRate {
+			r.Failures = append(r.Failures, fmt.Sprintf("error rate %.2f%%, expected at most %.2f%%", errorRate*100, *expect.MaxErrorRate*100))
+		}
+	}
+
+	r.Passed = len(r.Failures) == 0
+}
+
+// logReport logs a summary of every phase and the overall result
+func (s *Simulator) logReport(report *ScenarioReport) {
+	for _, phase := range report.Phases {
+		s.logger.Info("Scenario phase result",
+			"phase", phase.Name,
+			"operations", phase.Operations,
+			"failedOperations", phase.FailedOperations,
+			"consistencyMisses", phase.ConsistencyMisses,
+			"actions", len(phase.Actions),
+		)
+	}
+
+	if report.Passed {
+		s.logger.Info("Scenario passed", "scenario", report.Scenario, "seed", report.Seed, "duration", time.Duration(report.Duration))
+		s.telemetry.RecordEvent("Scenario %q passed", report.Scenario)
+		return
+	}
+
+	for _, failure := range report.Failures {
+		s.logger.Error("Scenario expectation not met", "scenario", report.Scenario, "failure", failure)
+	}
+	s.telemetry.RecordEvent("Scenario %q failed with seed %d", report.Scenario, report.Seed)
+}
+
+// writeScenarioReport writes the report as indented JSON
+func writeScenarioReport(path string, report *ScenarioReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
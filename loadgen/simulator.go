@@ -0,0 +1,758 @@
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+	"github.com/google/uuid"
+)
+
+// Operation represents the types of operations the simulator can perform
+type Operation string
+
+const (
+	OpCreate Operation = "create"
+	OpRead   Operation = "read"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+	OpList   Operation = "list"
+)
+
+type SimulatorOptions struct {
+	AccountCount    int
+	NotesPerAccount int
+	RequestsPerMin  int
+	BaseURL         string // URL of the Notely API to generate load against, e.g. http://localhost:8080
+	// ReuseAccounts uses existing accounts before creating new ones, so several generators can load the same accounts
+	ReuseAccounts bool
+	// Seed drives account and note IDs and the operations of every account, so runs with the same seed repeat them
+	Seed int64
+	// Workload distributes activity across accounts, notes and time
+	Workload Workload
+	// Checks configures the consistency checks run on top of comparing note contents to their last write
+	Checks ConsistencyChecks
+
+	// ClosedLoop sends operations back to back instead of at the requests per minute, nil for open-loop load
+	ClosedLoop *ClosedLoop
+	// LoadCurveFile is where the load curve of a closed-loop run is written as JSON, empty to only log it
+	LoadCurveFile string
+	// RunReportFile is where the report of the run is written as JSON once stopped, with a Markdown version next to it.
+	// Empty to skip the report.
+	RunReportFile string
+
+	// Scenario scripts the load in timed phases, nil to generate constant load until stopped
+	Scenario *Scenario
+	// ScenarioReportFile is where the scenario report is written as JSON, empty to only log it
+	ScenarioReportFile string
+
+	// Traffic is re-issued instead of generating load, nil to generate load
+	Traffic []restapi.TrafficRecord
+	// TrafficSpeed scales the pace of replayed traffic, 2 replays twice as fast and 0 as fast as possible
+	TrafficSpeed float64
+}
+
+// operationCounts counts the operations of all account loops
+type operationCounts struct {
+	total  atomic.Int64
+	failed atomic.Int64
+}
+
+type Simulator struct {
+	apiClient *restapi.RestAPIClient
+	telemetry *telemetry.Telemetry
+	logger    *slog.Logger
+	options   SimulatorOptions
+
+	load     *loadProfile
+	counts   operationCounts
+	stats    *runStats  // Collects the run report
+	ids      *util.Rand // Draws account IDs
+	accounts []*AccountLoop
+	done     chan error // Receives the result of a scenario or traffic replay
+
+	// Closed-loop load, nil for open-loop load. Idle holds the accounts no worker is using.
+	closedLoop *closedLoopController
+	idle       chan *AccountLoop
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type AccountLoop struct {
+	accountID uuid.UUID
+	apiClient *restapi.RestAPIClient
+	telemetry *telemetry.Telemetry
+	logger    *slog.Logger
+
+	// Track notes with their expected content hashes
+	notes     map[uuid.UUID]*trackedNote
+	notesLock sync.RWMutex
+	checks    ConsistencyChecks
+
+	// Operations and note IDs are drawn from a generator of the account's own, so they do not depend on other accounts
+	rng *util.Rand
+	// clock is a logical timestamp written into note contents, so contents are the same across seeded runs
+	clock int64
+
+	// Activity of the account relative to the configured rate, and when the simulated day started
+	workload Workload
+	activity float64
+	start    time.Time
+
+	// Load shared by all account loops and the counts and stats they report to
+	load   *loadProfile
+	counts *operationCounts
+	stats  *runStats
+
+	ctx context.Context
+}
+
+// hashContents returns a SHA256 hash of the given content string
+func hashContents(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("%x", hash)
+}
+
+func NewSimulator(telemetry *telemetry.Telemetry, options SimulatorOptions) *Simulator {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	simulator := &Simulator{
+		apiClient: restapi.NewRestAPIClient(options.BaseURL),
+		telemetry: telemetry,
+		logger:    simulatorLogger(telemetry),
+		options:   options,
+		load:      newLoadProfile(options.RequestsPerMin, options.NotesPerAccount),
+		stats:     newRunStats(),
+		ids:       util.NewRand(options.Seed, "accounts"),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if options.Scenario != nil || options.Traffic != nil {
+		simulator.done = make(chan error, 1)
+	}
+	simulator.apiClient.OnResponse(simulator.stats.observeResponse)
+	return simulator
+}
+
+func (s *Simulator) Start() error {
+	if s.options.Traffic != nil {
+		s.wg.Add(1)
+		go s.replayTraffic()
+		return nil
+	}
+
+	s.logger.Info("Starting load generator",
+		"accounts", s.options.AccountCount,
+		"notes_per_account", s.options.NotesPerAccount,
+		"requests_per_min", s.options.RequestsPerMin,
+		"workload", s.options.Workload,
+		"checks", s.options.Checks,
+	)
+
+	accounts, err := s.createAccounts()
+	if err != nil {
+		err = fmt.Errorf("failed to create accounts: %w", err)
+		s.abortScenario(err)
+		return err
+	}
+
+	if s.options.ClosedLoop != nil {
+		s.startClosedLoop(len(accounts))
+	}
+
+	// Start a goroutine for each account
+	activity := s.options.Workload.accountActivity(len(accounts))
+	start := time.Now()
+	for i, account := range accounts {
+		accountLoop := s.newAccountLoop(i, account, activity[i], start)
+		s.accounts = append(s.accounts, accountLoop)
+
+		s.wg.Add(1)
+		go s.runAccountLoop(i, account, accountLoop)
+	}
+
+	if s.options.Scenario != nil {
+		s.wg.Add(1)
+		go s.runScenario(*s.options.Scenario)
+	}
+
+	return nil
+}
+
+// Done delivers the result once a scenario or traffic replay completed, nil if it passed.
+// It never delivers while generating load until stopped.
+func (s *Simulator) Done() <-chan error {
+	if s == nil {
+		return nil
+	}
+	return s.done
+}
+
+// UpdateLogger updates the simulator's logger reference
+func (s *Simulator) UpdateLogger() {
+	s.logger = simulatorLogger(s.telemetry)
+}
+
+// simulatorLogger attributes logs of the load generator to the simulator source
+func simulatorLogger(tel *telemetry.Telemetry) *slog.Logger {
+	return tel.GetLogger().With(telemetry.LogSourceKey, telemetry.LogSourceSimulator)
+}
+
+func (s *Simulator) Stop() {
+	s.logger.Info("Stopping load generator...")
+	s.cancel()
+
+	// Wait for goroutines to finish with a timeout to prevent hanging
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Load generator stopped",
+			"operations", s.counts.total.Load(),
+			"failedOperations", s.counts.failed.Load(),
+			"consistencyMisses", s.telemetry.StatsCollector.Export().ConsistencyMisses,
+		)
+	case <-time.After(2 * time.Second):
+		s.logger.Warn("Load generator stop timed out, some goroutines may still be running")
+	}
+
+	if s.closedLoop != nil {
+		s.reportLoadCurve()
+	}
+	if s.options.RunReportFile != "" {
+		s.reportRun()
+	}
+}
+
+// createAccounts returns the accounts to simulate, reusing existing accounts if configured and creating the rest
+func (s *Simulator) createAccounts() ([]store.Account, error) {
+	accounts := make([]store.Account, 0, s.options.AccountCount)
+
+	if s.options.ReuseAccounts {
+		existing, err := s.apiClient.ListAccounts(s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+
+		// Sort accounts so generators started with the same options load the same accounts
+		slices.SortFunc(existing, func(a, b store.Account) int {
+			return bytes.Compare(a.ID[:], b.ID[:])
+		})
+		accounts = append(accounts, existing[:min(len(existing), s.options.AccountCount)]...)
+		s.logger.Info("Reusing accounts", "count", len(accounts))
+	}
+
+	s.logger.Info("Creating accounts", "count", s.options.AccountCount-len(accounts))
+
+	timestamp := time.Now().Format("15:04:05")
+	for i := len(accounts); i < s.options.AccountCount; i++ {
+		account := store.Account{
+			ID:   uuid.Must(uuid.NewRandomFromReader(s.ids)),
+			Name: fmt.Sprintf("LoadTestUser%d_%s", i+1, timestamp),
+		}
+
+		createdAccount, err := s.apiClient.CreateAccount(s.ctx, account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create account %s: %w", account.Name, err)
+		}
+
+		accounts = append(accounts, *createdAccount)
+	}
+
+	s.logger.Info("Successfully prepared accounts", "count", len(accounts))
+	return accounts, nil
+}
+
+// newAccountLoop creates the loop of the i-th account, seeding its generator with the position of the account
+func (s *Simulator) newAccountLoop(i int, account store.Account, activity float64, start time.Time) *AccountLoop {
+	return &AccountLoop{
+		accountID: account.ID,
+		apiClient: s.apiClient,
+		telemetry: s.telemetry,
+		logger:    s.logger.With("account_id", account.ID),
+		notes:     make(map[uuid.UUID]*trackedNote),
+		checks:    s.options.Checks,
+		rng:       util.NewRand(s.options.Seed, fmt.Sprintf("account %d", i)),
+		workload:  s.options.Workload,
+		activity:  activity,
+		start:     start,
+		load:      s.load,
+		counts:    &s.counts,
+		stats:     s.stats,
+		ctx:       s.ctx,
+	}
+}
+
+func (s *Simulator) runAccountLoop(i int, account store.Account, accountLoop *AccountLoop) {
+	defer s.wg.Done()
+
+	// Create initial notes for this account
+	if err := accountLoop.createInitialNotes(s.options.NotesPerAccount); err != nil {
+		s.logger.Error("Failed to create initial notes", "account", account.ID, "error", err)
+		return
+	}
+
+	// Reduce log noise - only log for first account
+	if i == 0 {
+		s.logger.Info("Started account loops for load generator")
+	}
+
+	// With closed-loop load, workers run the account's operations once it is ready
+	if s.closedLoop != nil {
+		s.idle <- accountLoop
+		return
+	}
+
+	// Run the account operations loop
+	accountLoop.run()
+}
+
+// startClosedLoop starts a worker for every account, of which the controller keeps the current concurrency running
+func (s *Simulator) startClosedLoop(accounts int) {
+	config := *s.options.ClosedLoop
+	if config.Concurrency > accounts {
+		s.logger.Warn("Closed-loop concurrency is capped at the number of accounts", "concurrency", config.Concurrency, "accounts", accounts)
+	}
+	if config.TargetP99 > 0 && accounts < minTargetP99Accounts {
+		s.logger.Warn("Closed-loop workers cannot exceed the number of accounts, raise --concurrency so the target p99 can be reached",
+			"accounts", accounts, "target_p99", time.Duration(config.TargetP99))
+	}
+	if workload := s.options.Workload; workload.Arrivals == ArrivalsPoisson || workload.AccountSkew != 0 || workload.DiurnalPeriod != 0 {
+		s.logger.Warn("Arrivals, account skew and the diurnal curve have no effect on closed-loop load, workers send operations back to back")
+	}
+
+	s.closedLoop = newClosedLoopController(config, accounts)
+	s.idle = make(chan *AccountLoop, accounts)
+	s.logger.Info("Starting closed-loop load",
+		"concurrency", s.closedLoop.concurrency.Load(),
+		"target_p99", time.Duration(config.TargetP99),
+		"interval", time.Duration(s.closedLoop.config.Interval),
+	)
+
+	for worker := range accounts {
+		s.wg.Add(1)
+		go s.runClosedLoopWorker(worker)
+	}
+	s.wg.Add(1)
+	go s.runClosedLoopController()
+}
+
+func (al *AccountLoop) createInitialNotes(count int) error {
+	for i := 0; i < count; i++ {
+		content := al.workload.noteContent(al.rng, fmt.Sprintf("Initial note %d for account %s", i+1, al.accountID))
+		note := store.Note{
+			ID:        al.newNoteID(),
+			Creator:   al.accountID,
+			CreatedAt: time.Now(),
+			Content:   content,
+		}
+
+		createdNote, err := al.apiClient.CreateNote(al.ctx, al.accountID, note)
+		if err != nil {
+			return fmt.Errorf("failed to create note: %w", err)
+		}
+
+		al.notesLock.Lock()
+		al.trackWrite(createdNote)
+		al.notesLock.Unlock()
+
+		if err := al.readYourWrite(createdNote.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (al *AccountLoop) run() {
+	// Requests are scheduled one at a time, as their spacing follows the requests per minute,
+	// which scenario phases may change, the account's activity and the time of the simulated day
+	next := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		delay := al.nextDelay()
+		if delay == 0 {
+			next = time.Now().Add(loadPausedPollInterval)
+		} else {
+			// Schedule from the previous request so slow responses do not lower the rate, without catching up on missed requests
+			next = later(next.Add(delay), time.Now())
+		}
+		timer.Reset(time.Until(next))
+
+		select {
+		case <-al.ctx.Done():
+			return
+		case <-timer.C:
+			if delay == 0 {
+				continue // Load is paused
+			}
+
+			_ = al.perform()
+		}
+	}
+}
+
+// perform selects and runs one operation, counting it and whether it failed
+func (al *AccountLoop) perform() error {
+	operations := map[Operation]func() error{
+		OpCreate: al.createNote,
+		OpUpdate: al.updateNote,
+		OpRead:   al.readNote,
+		OpDelete: al.deleteNote,
+		OpList:   al.listNotes,
+	}
+
+	op := al.selectOperation()
+	al.counts.total.Add(1)
+	start := time.Now()
+	err := operations[op]()
+	al.stats.observeOperation(op, time.Since(start), err)
+	if err != nil {
+		al.counts.failed.Add(1)
+		// Only log errors, not every operation
+		al.logger.Error("Load generator operation failed", "op", string(op), "error", err)
+		return err
+	}
+
+	al.logger.Debug("successfully performed operation", "op", string(op))
+	return nil
+}
+
+// nextDelay returns the time until the account's next request, or zero while load is paused
+func (al *AccountLoop) nextDelay() time.Duration {
+	interval := al.load.interval()
+	if interval == 0 {
+		return 0
+	}
+
+	rate := al.activity * al.workload.rateFactor(time.Since(al.start))
+	return al.workload.delay(al.rng, interval, rate)
+}
+
+// later returns the later of two times
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// selectOperation chooses an operation based on current note count vs target, unless a scenario fixed the mix
+func (al *AccountLoop) selectOperation() Operation {
+	if weights := al.load.operationWeights(); len(weights) > 0 {
+		return al.weightedRandomSelect(weights.list())
+	}
+
+	al.notesLock.RLock()
+	currentCount := len(al.notes)
+	al.notesLock.RUnlock()
+
+	targetNoteCount := al.load.targetNoteCount()
+
+	// If we have exactly the target count, all operations except create/delete
+	if currentCount == targetNoteCount {
+		// Higher weight on read operations (most common in real usage)
+		weights := []struct {
+			op     Operation
+			weight int
+		}{
+			{OpRead, 50},   // 50% read
+			{OpUpdate, 25}, // 25% update
+			{OpList, 25},   // 25% list
+		}
+		return al.weightedRandomSelect(weights)
+	}
+
+	// If below target, bias towards create
+	if currentCount < targetNoteCount {
+		// Calculate how far we are from target (0.0 to 1.0)
+		deficit := float64(targetNoteCount-currentCount) / float64(targetNoteCount)
+
+		// More aggressive create bias when further from target
+		createWeight := int(30 + deficit*40) // 30-70% based on deficit
+
+		weights := []struct {
+			op     Operation
+			weight int
+		}{
+			{OpCreate, createWeight},
+			{OpRead, 30},
+			{OpUpdate, 20},
+			{OpList, 15},
+			{OpDelete, 100 - createWeight - 65}, // Remainder, but at least 5%
+		}
+
+		// Don't allow delete if at 0
+		if currentCount == 0 {
+			weights[4].weight = 0
+		}
+
+		return al.weightedRandomSelect(weights)
+	}
+
+	// If above target (shouldn't happen with max=target, but handle it)
+	if currentCount > targetNoteCount {
+		// Must delete to get back to target
+		weights := []struct {
+			op     Operation
+			weight int
+		}{
+			{OpDelete, 60}, // 60% delete to get back to target
+			{OpRead, 20},
+			{OpUpdate, 10},
+			{OpList, 10},
+			{OpCreate, 0}, // No creates when over target
+		}
+		return al.weightedRandomSelect(weights)
+	}
+
+	// Default balanced distribution
+	weights := []struct {
+		op     Operation
+		weight int
+	}{
+		{OpRead, 40},
+		{OpUpdate, 20},
+		{OpCreate, 15},
+		{OpDelete, 15},
+		{OpList, 10},
+	}
+	return al.weightedRandomSelect(weights)
+}
+
+// weightedRandomSelect picks a random operation based on weights
+func (al *AccountLoop) weightedRandomSelect(weights []struct {
+	op     Operation
+	weight int
+}) Operation {
+	totalWeight := 0
+	for _, w := range weights {
+		totalWeight += w.weight
+	}
+
+	if totalWeight == 0 {
+		// Fallback to read if all weights are 0
+		return OpRead
+	}
+
+	r := al.rng.IntN(totalWeight)
+	for _, w := range weights {
+		r -= w.weight
+		if r < 0 {
+			return w.op
+		}
+	}
+
+	// Should never reach here, but return read as fallback
+	return OpRead
+}
+
+// randomNoteID picks one of the account's notes, which must not be empty. The caller must hold the notes lock.
+// Notes are sorted first, as map order would make the pick differ between runs with the same seed.
+// With note skew, notes with lower IDs are picked more often.
+func (al *AccountLoop) randomNoteID() uuid.UUID {
+	noteIDs := make([]uuid.UUID, 0, len(al.notes))
+	for noteID := range al.notes {
+		noteIDs = append(noteIDs, noteID)
+	}
+	slices.SortFunc(noteIDs, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	if al.workload.NoteSkew > 0 {
+		return noteIDs[al.rng.Zipf(len(noteIDs), al.workload.NoteSkew)]
+	}
+	return noteIDs[al.rng.IntN(len(noteIDs))]
+}
+
+// newNoteID draws the ID of a note to create
+func (al *AccountLoop) newNoteID() uuid.UUID {
+	return uuid.Must(uuid.NewRandomFromReader(al.rng))
+}
+
+// tick advances the account's logical clock and returns the new time
+func (al *AccountLoop) tick() int64 {
+	al.clock++
+	return al.clock
+}
+
+func (al *AccountLoop) createNote() error {
+	// Check if we're at target capacity
+	al.notesLock.RLock()
+	currentCount := len(al.notes)
+	al.notesLock.RUnlock()
+
+	if currentCount >= al.load.targetNoteCount() {
+		// Silently skip creation when at target
+		return nil
+	}
+
+	content := al.workload.noteContent(al.rng, fmt.Sprintf("Note created at t=%d", al.tick()))
+	note := store.Note{
+		ID:        al.newNoteID(),
+		Creator:   al.accountID,
+		CreatedAt: time.Now(),
+		Content:   content,
+	}
+
+	createdNote, err := al.apiClient.CreateNote(al.ctx, al.accountID, note)
+	if err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+
+	al.notesLock.Lock()
+	al.trackWrite(createdNote)
+	al.notesLock.Unlock()
+
+	return al.readYourWrite(createdNote.ID)
+}
+
+func (al *AccountLoop) updateNote() error {
+	al.notesLock.Lock()
+
+	if len(al.notes) == 0 {
+		al.notesLock.Unlock()
+		return nil // No notes to update
+	}
+
+	randomNoteID := al.randomNoteID()
+
+	updatedAt := time.Now()
+	newContent := al.workload.noteContent(al.rng, fmt.Sprintf("Updated at t=%d", al.tick()))
+	note := store.Note{
+		ID:        randomNoteID,
+		Creator:   al.accountID,
+		UpdatedAt: updatedAt,
+		Content:   newContent,
+	}
+
+	updatedNote, err := al.apiClient.UpdateNote(al.ctx, al.accountID, note)
+	if err != nil {
+		al.notesLock.Unlock()
+		return fmt.Errorf("failed to update note: %w", err)
+	}
+
+	// Update the hash while still holding the lock
+	al.trackWrite(updatedNote)
+	al.notesLock.Unlock()
+
+	return al.readYourWrite(updatedNote.ID)
+}
+
+func (al *AccountLoop) readNote() error {
+	al.notesLock.RLock()
+	if len(al.notes) == 0 {
+		al.notesLock.RUnlock()
+		return nil // No notes to read
+	}
+
+	// Get a random note ID while holding the lock
+	randomNoteID := al.randomNoteID()
+	al.notesLock.RUnlock()
+
+	ctx, info := restapi.WithResponseInfo(al.ctx)
+	note, err := al.apiClient.GetNote(ctx, al.accountID, randomNoteID)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	// Check content consistency
+	al.notesLock.Lock()
+	al.checkRead(telemetry.ConsistencyCheckRead, note, info)
+	al.notesLock.Unlock()
+
+	return nil
+}
+
+func (al *AccountLoop) deleteNote() error {
+	al.notesLock.Lock()
+	defer al.notesLock.Unlock()
+
+	currentCount := len(al.notes)
+
+	if currentCount == 0 {
+		return nil // No notes to delete
+	}
+
+	randomNoteID := al.randomNoteID()
+
+	err := al.apiClient.DeleteNote(al.ctx, al.accountID, randomNoteID)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	// Remove from local tracking
+	delete(al.notes, randomNoteID)
+
+	return nil
+}
+
+func (al *AccountLoop) listNotes() error {
+	listStart := time.Now()
+	ctx, listInfo := restapi.WithResponseInfo(al.ctx)
+	noteIDs, err := al.apiClient.ListNotes(ctx, al.accountID)
+	if err != nil {
+		return fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	al.notesLock.Lock()
+	defer al.notesLock.Unlock()
+
+	// Check every listed note that should exist in our local map
+	listed := make(map[uuid.UUID]bool)
+	for _, noteID := range noteIDs {
+		ctx, info := restapi.WithResponseInfo(al.ctx)
+		note, err := al.apiClient.GetNote(ctx, al.accountID, noteID)
+		if err != nil {
+			return fmt.Errorf("could not retrieve note: %w", err)
+		}
+
+		listed[noteID] = true
+		al.checkRead(telemetry.ConsistencyCheckListMismatch, note, info)
+	}
+
+	// Check that all local notes exist on the server, unless they were created within the grace period.
+	// Mismatches were already recorded above.
+	for noteID, tracked := range al.notes {
+		if !listed[noteID] && al.listGraceElapsed(tracked, listStart) {
+			al.logger.Warn("CONSISTENCY ERROR: Note missing from server")
+			detail := fmt.Sprintf("note created %s before the list", listStart.Sub(tracked.createdAt).Round(time.Millisecond))
+			al.trackConsistencyMiss(telemetry.ConsistencyCheckListMissing, noteID, tracked.hash, "", detail, listInfo)
+		}
+	}
+
+	return nil
+}
+
+// trackConsistencyMiss records a consistency miss along with the request that detected it
+func (al *AccountLoop) trackConsistencyMiss(check telemetry.ConsistencyCheck, noteID uuid.UUID, expectedHash, actualHash, detail string, info *restapi.ResponseInfo) {
+	al.stats.observeMiss(al.accountID, check)
+	al.telemetry.StatsCollector.TrackConsistencyMiss(telemetry.ConsistencyMiss{
+		Time:         time.Now(),
+		AccountID:    al.accountID.String(),
+		NoteID:       noteID.String(),
+		Check:        check,
+		ExpectedHash: expectedHash,
+		ActualHash:   actualHash,
+		ProxyID:      info.ServedBy,
+		RequestID:    info.RequestID,
+		Detail:       detail,
+	})
+}
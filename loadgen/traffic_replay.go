@@ -0,0 +1,206 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/google/uuid"
+)
+
+// trafficRequestTimeout bounds a single replayed request
+const trafficRequestTimeout = 30 * time.Second
+
+// clientTimestampFields are timestamps clients may set in request bodies
+var clientTimestampFields = []string{"createdAt", "updatedAt"}
+
+// TrafficReplayReport counts replayed requests whose responses differ from the recording
+type TrafficReplayReport struct {
+	Requests         int
+	Errors           int // Requests that could not be sent
+	StatusMismatches int
+	BodyMismatches   int // Responses with the recorded status but a different body
+}
+
+// Err returns an error summarizing all differences, or nil if every response matched
+func (r *TrafficReplayReport) Err() error {
+	if r.Errors == 0 && r.StatusMismatches == 0 && r.BodyMismatches == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d replayed requests differ: %d failed, %d with a different status, %d with a different body",
+		r.Errors+r.StatusMismatches+r.BodyMismatches, r.Requests, r.Errors, r.StatusMismatches, r.BodyMismatches)
+}
+
+// replayTraffic re-issues recorded traffic at its original pace, scaled by the replay speed.
+// Requests for the same account are sent in the recorded order, different accounts run concurrently.
+func (s *Simulator) replayTraffic() {
+	defer s.wg.Done()
+
+	records := s.options.Traffic
+	s.logger.Info("Replaying traffic", "requests", len(records), "speed", s.options.TrafficSpeed)
+	s.telemetry.RecordEvent("Traffic replay of %d requests started", len(records))
+
+	queues := make(map[string][]restapi.TrafficRecord)
+	var keys []string
+	for _, record := range records {
+		key := trafficQueueKey(record)
+		if _, ok := queues[key]; !ok {
+			keys = append(keys, key)
+		}
+		queues[key] = append(queues[key], record)
+	}
+
+	var (
+		mu     sync.Mutex
+		report TrafficReplayReport
+		wg     sync.WaitGroup
+	)
+	start := time.Now()
+	for _, key := range keys {
+		wg.Add(1)
+		go func(queue []restapi.TrafficRecord) {
+			defer wg.Done()
+			for _, record := range queue {
+				if !s.waitForRecord(start, records[0].Time, record.Time) {
+					return
+				}
+				result := s.replayRecord(record)
+
+				mu.Lock()
+				report.Requests++
+				switch result {
+				case replayFailed:
+					report.Errors++
+				case replayStatusMismatch:
+					report.StatusMismatches++
+				case replayBodyMismatch:
+					report.BodyMismatches++
+				}
+				mu.Unlock()
+			}
+		}(queues[key])
+	}
+	wg.Wait()
+
+	if s.ctx.Err() != nil {
+		s.logger.Info("Traffic replay stopped before completing", "requests", report.Requests)
+		return
+	}
+
+	err := report.Err()
+	if err != nil {
+		s.logger.Error("Traffic replay found differences", "error", err, "duration", time.Since(start))
+		s.telemetry.RecordEvent("Traffic replay failed: %d requests differ", report.Errors+report.StatusMismatches+report.BodyMismatches)
+	} else {
+		s.logger.Info("Traffic replay matched all responses", "requests", report.Requests, "duration", time.Since(start))
+		s.telemetry.RecordEvent("Traffic replay of %d requests matched", report.Requests)
+	}
+	s.done <- err
+}
+
+// waitForRecord waits until a record is due, returning false if the simulator was stopped
+func (s *Simulator) waitForRecord(start, firstRecorded, recorded time.Time) bool {
+	if s.options.TrafficSpeed <= 0 {
+		return s.ctx.Err() == nil
+	}
+
+	due := start.Add(time.Duration(float64(recorded.Sub(firstRecorded)) / s.options.TrafficSpeed))
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-time.After(time.Until(due)):
+		return true
+	}
+}
+
+type replayResult int
+
+const (
+	replayMatched replayResult = iota
+	replayFailed
+	replayStatusMismatch
+	replayBodyMismatch
+)
+
+// replayRecord re-issues a recorded request and compares the response to the recorded one
+func (s *Simulator) replayRecord(record restapi.TrafficRecord) replayResult {
+	ctx, cancel := context.WithTimeout(s.ctx, trafficRequestTimeout)
+	defer cancel()
+	ctx, info := restapi.WithResponseInfo(ctx)
+
+	status, body, err := s.apiClient.Send(ctx, record.Method, record.Path, replayBody(record, time.Since(record.Time)))
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.logger.Warn("Could not replay request", "method", record.Method, "path", record.Path, "error", err)
+		}
+		return replayFailed
+	}
+
+	result := replayMatched
+	if status != record.Status {
+		result = replayStatusMismatch
+	} else if restapi.HashResponseBody(body) != record.ResponseHash {
+		result = replayBodyMismatch
+	}
+
+	if result != replayMatched {
+		s.logger.Warn("Replayed response differs from recording",
+			"method", record.Method,
+			"path", record.Path,
+			"recordedStatus", record.Status,
+			"status", status,
+			"recordedRequestID", record.RequestID,
+			"recordedServedBy", record.ServedBy,
+			"requestID", info.RequestID,
+			"servedBy", info.ServedBy,
+		)
+	}
+	return result
+}
+
+// trafficQueueKey returns the account a request belongs to, so requests of one account keep their order
+func trafficQueueKey(record restapi.TrafficRecord) string {
+	path, _, _ := strings.Cut(record.Path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "accounts" {
+		return segments[1]
+	}
+	if len(segments) == 1 && segments[0] == "accounts" && record.ResourceID != "" {
+		return record.ResourceID
+	}
+	return ""
+}
+
+// replayBody returns the recorded request body, moved to the time of the replay. Timestamps set by clients
+// are shifted by how much later a request is replayed than recorded, as stores reject updates older than
+// the stored note. Creates that let the server choose an ID get the recorded ID, so later requests for
+// the created account or note find it.
+func replayBody(record restapi.TrafficRecord, shift time.Duration) []byte {
+	var body map[string]any
+	if err := json.Unmarshal(record.Body, &body); err != nil {
+		return record.Body
+	}
+
+	for _, field := range clientTimestampFields {
+		value, _ := body[field].(string)
+		timestamp, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil || timestamp.IsZero() {
+			continue
+		}
+		body[field] = timestamp.Add(shift)
+	}
+
+	if id, _ := body["id"].(string); record.ResourceID != "" && (id == "" || id == uuid.Nil.String()) {
+		body["id"] = record.ResourceID
+	}
+
+	replayed, err := json.Marshal(body)
+	if err != nil {
+		return record.Body
+	}
+	return replayed
+}
@@ -0,0 +1,117 @@
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+)
+
+// ArrivalProcess decides how requests of an account are spaced
+type ArrivalProcess string
+
+const (
+	ArrivalsFixed   ArrivalProcess = "fixed"   // Requests are evenly spaced
+	ArrivalsPoisson ArrivalProcess = "poisson" // Requests arrive independently at the same average rate
+)
+
+const (
+	// minDiurnalRate keeps accounts issuing some requests at the low point of a diurnal curve
+	minDiurnalRate = 0.01
+	// maxRequestDelay caps the time between requests of an account, so accounts with hardly any activity
+	// do not overflow the delay and still issue a request now and then
+	maxRequestDelay = time.Hour
+	// noteSizeSpread is the standard deviation of the log of note sizes, giving a long tail of large notes
+	noteSizeSpread = 1.0
+	// noteFiller pads note contents to their drawn size
+	noteFiller = " Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."
+)
+
+// Workload describes how activity is distributed across accounts, notes and time.
+// The zero value makes all accounts equally active with evenly spaced requests and short notes.
+type Workload struct {
+	Arrivals         ArrivalProcess     `json:"arrivals,omitempty"`         // Defaults to fixed
+	AccountSkew      float64            `json:"accountSkew,omitempty"`      // Zipf exponent of account activity, 0 for all accounts equally active
+	NoteSkew         float64            `json:"noteSkew,omitempty"`         // Zipf exponent of which notes are read, updated and deleted
+	DiurnalPeriod    telemetry.Duration `json:"diurnalPeriod,omitempty"`    // Length of a simulated day, 0 for a constant rate
+	DiurnalAmplitude float64            `json:"diurnalAmplitude,omitempty"` // Share the rate swings around its average over a day
+	NoteSize         int                `json:"noteSize,omitempty"`         // Median length of note contents, 0 for short notes
+}
+
+// Validate checks that all parameters are in range
+func (w Workload) Validate() error {
+	switch w.Arrivals {
+	case "", ArrivalsFixed, ArrivalsPoisson:
+	default:
+		return fmt.Errorf("unknown arrival process %q", w.Arrivals)
+	}
+	if w.AccountSkew < 0 || w.NoteSkew < 0 {
+		return errors.New("skew must not be negative")
+	}
+	if w.DiurnalPeriod < 0 {
+		return errors.New("diurnal period must not be negative")
+	}
+	if w.DiurnalAmplitude < 0 || w.DiurnalAmplitude > 1 {
+		return fmt.Errorf("diurnal amplitude must be between 0 and 1, got %v", w.DiurnalAmplitude)
+	}
+	if w.NoteSize < 0 || w.NoteSize > constants.MaxNoteContentLength {
+		return fmt.Errorf("note size must be between 0 and %d, got %d", constants.MaxNoteContentLength, w.NoteSize)
+	}
+	return nil
+}
+
+// accountActivity returns how active each of n accounts is relative to the configured rate, averaging 1.
+// With skew, the first accounts are the hot ones.
+func (w Workload) accountActivity(n int) []float64 {
+	activity := util.ZipfWeights(n, w.AccountSkew)
+
+	total := 0.0
+	for _, weight := range activity {
+		total += weight
+	}
+	for i := range activity {
+		activity[i] *= float64(n) / total
+	}
+	return activity
+}
+
+// rateFactor returns how the rate at a point of the simulated day compares to the average rate
+func (w Workload) rateFactor(elapsed time.Duration) float64 {
+	if w.DiurnalPeriod <= 0 || w.DiurnalAmplitude == 0 {
+		return 1
+	}
+	phase := 2 * math.Pi * float64(elapsed) / float64(w.DiurnalPeriod)
+	return max(minDiurnalRate, 1+w.DiurnalAmplitude*math.Sin(phase))
+}
+
+// delay returns the time until the next request, given the average interval between requests at a rate of 1
+// and the rate of the account relative to it
+func (w Workload) delay(rng *util.Rand, interval time.Duration, rate float64) time.Duration {
+	mean := float64(interval) / rate
+	if w.Arrivals == ArrivalsPoisson {
+		mean *= rng.ExpFloat64()
+	}
+	return time.Duration(min(mean, float64(maxRequestDelay)))
+}
+
+// noteContent pads content with filler to a drawn size. Sizes are log-normally distributed around the
+// configured median, so most notes are small and a few approach the content limit.
+func (w Workload) noteContent(rng *util.Rand, content string) string {
+	if w.NoteSize == 0 {
+		return content
+	}
+
+	size := int(float64(w.NoteSize) * math.Exp(noteSizeSpread*rng.NormFloat64()))
+	size = min(max(size, len(content)), constants.MaxNoteContentLength)
+	if size <= len(content) {
+		return content
+	}
+
+	padding := strings.Repeat(noteFiller, (size-len(content))/len(noteFiller)+1)
+	return content + padding[:size-len(content)]
+}
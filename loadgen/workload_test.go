@@ -0,0 +1,140 @@
+package loadgen
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload Workload
+		wantErr  bool
+	}{
+		{"zero value", Workload{}, false},
+		{"full workload", Workload{Arrivals: ArrivalsPoisson, AccountSkew: 1.2, NoteSkew: 0.8, DiurnalPeriod: telemetry.Duration(time.Minute), DiurnalAmplitude: 0.5, NoteSize: 500}, false},
+		{"unknown arrivals", Workload{Arrivals: "bursty"}, true},
+		{"negative account skew", Workload{AccountSkew: -1}, true},
+		{"negative note skew", Workload{NoteSkew: -1}, true},
+		{"negative diurnal period", Workload{DiurnalPeriod: telemetry.Duration(-time.Minute)}, true},
+		{"amplitude above 1", Workload{DiurnalAmplitude: 1.5}, true},
+		{"note size above limit", Workload{NoteSize: constants.MaxNoteContentLength + 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.workload.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWorkloadAccountActivity(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		skew float64
+	}{
+		{"single account", 1, 1.5},
+		{"uniform", 10, 0},
+		{"skewed", 10, 1.2},
+		{"heavily skewed", 1000, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := Workload{AccountSkew: tt.skew}.accountActivity(tt.n)
+			require.Len(t, activity, tt.n)
+
+			total := 0.0
+			for i, a := range activity {
+				total += a
+				if i > 0 {
+					require.LessOrEqual(t, a, activity[i-1], "Expected the first accounts to be the most active")
+				}
+			}
+			require.InDelta(t, 1, total/float64(tt.n), 1e-9, "Expected activity to average 1")
+		})
+	}
+}
+
+func TestWorkloadRateFactor(t *testing.T) {
+	day := telemetry.Duration(4 * time.Minute)
+	tests := []struct {
+		name     string
+		workload Workload
+		elapsed  time.Duration
+		want     float64
+	}{
+		{"constant rate", Workload{}, time.Minute, 1},
+		{"no amplitude", Workload{DiurnalPeriod: day}, time.Minute, 1},
+		{"peak", Workload{DiurnalPeriod: day, DiurnalAmplitude: 0.5}, time.Minute, 1.5},
+		{"trough", Workload{DiurnalPeriod: day, DiurnalAmplitude: 0.5}, 3 * time.Minute, 0.5},
+		{"floored at full amplitude", Workload{DiurnalPeriod: day, DiurnalAmplitude: 1}, 3 * time.Minute, minDiurnalRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.want, tt.workload.rateFactor(tt.elapsed), 1e-9)
+		})
+	}
+}
+
+func TestWorkloadDelay(t *testing.T) {
+	rng := util.NewRand(1, "delay")
+	tests := []struct {
+		name     string
+		workload Workload
+		rate     float64
+		want     time.Duration
+	}{
+		{"average account", Workload{}, 1, time.Second},
+		{"hot account", Workload{}, 4, 250 * time.Millisecond},
+		{"cold account", Workload{}, 1e-12, maxRequestDelay},
+		{"inactive account", Workload{}, 0, maxRequestDelay},
+		{"cold account with poisson arrivals", Workload{Arrivals: ArrivalsPoisson}, math.SmallestNonzeroFloat64, maxRequestDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.workload.delay(rng, time.Second, tt.rate))
+		})
+	}
+}
+
+func TestWorkloadNoteContent(t *testing.T) {
+	rng := util.NewRand(1, "notes")
+	tests := []struct {
+		name     string
+		workload Workload
+		content  string
+	}{
+		{"short notes", Workload{}, "hello"},
+		{"medium notes", Workload{NoteSize: 200}, "hello"},
+		{"notes at the limit", Workload{NoteSize: constants.MaxNoteContentLength}, "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 200 {
+				content := tt.workload.noteContent(rng, tt.content)
+				require.LessOrEqual(t, len(content), constants.MaxNoteContentLength)
+				require.GreaterOrEqual(t, len(content), len(tt.content))
+				require.Equal(t, tt.content, content[:len(tt.content)])
+				if tt.workload.NoteSize == 0 {
+					require.Equal(t, tt.content, content)
+				}
+			}
+		})
+	}
+}
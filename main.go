@@ -6,28 +6,39 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"math/rand/v2"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/brunoscheufler/gopherconuk25/cli"
 	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/loadgen"
 	"github.com/brunoscheufler/gopherconuk25/proxy"
 	"github.com/brunoscheufler/gopherconuk25/restapi"
+	"github.com/brunoscheufler/gopherconuk25/snapshot"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/verify"
 )
 
 // Config holds all configuration parameters for running the application
 type Config struct {
 	// CLI configuration
-	CLIMode  bool
-	Theme    string
-	Port     string
-	LogLevel string
+	CLIMode   bool
+	Theme     string
+	Port      string
+	LogLevel  string
+	TraceFile string
+	LogFile   string
+	StatsDir  string
+	Replay    string
+	SLOFile   string
+	Seed      int64
 
 	// Proxy configuration
 	ProxyMode bool
@@ -36,9 +47,31 @@ type Config struct {
 
 	// Load generator configuration
 	EnableLoadGen   bool
+	LoadGenMode     bool
+	Target          string
 	AccountCount    int
 	NotesPerAccount int
 	RequestsPerMin  int
+	Scenario        string
+	ScenarioReport  string
+	RecordTraffic   string
+	ReplayTraffic   string
+	ReplaySpeed     float64
+	Workload        loadgen.Workload
+	Checks          loadgen.ConsistencyChecks
+	ClosedLoop      loadgen.ClosedLoop
+	LoadCurve       string
+	RunReport       string
+
+	// Verifier configuration
+	VerifyMode   bool
+	VerifyFormat string
+	Repair       bool
+
+	// Snapshot configuration
+	SnapshotName  string
+	RestoreName   string
+	ListSnapshots bool
 }
 
 // AppConfig groups common application dependencies to reduce parameter lists
@@ -54,6 +87,12 @@ func main() {
 	theme := flag.String("theme", "dark", "Theme for CLI mode (dark or light)")
 	port := flag.String("port", constants.DefaultPort, "Port to run the HTTP server on")
 	logLevel := flag.String("log-level", "", "Log level (DEBUG, INFO, WARN, ERROR). Defaults to DEBUG")
+	traceFile := flag.String("trace-file", constants.DefaultTraceFile, "File to export spans to as OTLP JSON lines, empty to keep traces in memory only")
+	logFile := flag.String("log-file", "", "File to persist logs of the API and all data proxies to as JSON lines, rotated at 10MB")
+	statsDir := flag.String("stats-dir", constants.DefaultStatsDir, "Directory to record all stats to every tick as JSON lines, empty to disable")
+	replay := flag.String("replay", "", "Open the TUI against a stats recording instead of running the application")
+	sloFile := flag.String("slo-file", "", "JSON file with SLOs to evaluate and gate rollouts on")
+	seed := flag.Int64("seed", 0, "Seed for the load generator, simulated network delays and proxy routing, 0 picks a random seed")
 
 	// Proxy flags
 	proxyMode := flag.Bool("proxy", false, "Run as data proxy")
@@ -62,9 +101,39 @@ func main() {
 
 	// Load generator flags
 	enableLoadGen := flag.Bool("gen", false, "Enable load generator")
+	loadGenMode := flag.Bool("loadgen", false, "Run only the load generator against the Notely API at --target, reusing its existing accounts")
+	target := flag.String("target", "http://localhost:"+constants.DefaultPort, "Base URL of the Notely API to generate load against with --loadgen")
 	accountCount := flag.Int("concurrency", 5, "Number of accounts for load generator")
 	notesPerAccount := flag.Int("notes-per-account", 3, "Number of notes per account for load generator")
 	requestsPerMin := flag.Int("rpm", 60, "Requests per minute for load generator")
+	scenarioFile := flag.String("scenario", "", "JSON file with a scenario of timed load phases and actions to run with the load generator")
+	scenarioReport := flag.String("scenario-report", "", "File to write the scenario report to as JSON")
+	recordTraffic := flag.String("record-traffic", "", "File to record all account and note requests to as JSON lines, empty to disable")
+	replayTraffic := flag.String("replay-traffic", "", "Re-issue traffic recorded with --record-traffic instead of generating load, comparing responses")
+	arrivals := flag.String("arrivals", string(loadgen.ArrivalsFixed), "How requests of an account are spaced for load generator (fixed or poisson), no effect with --closed-loop")
+	accountSkew := flag.Float64("account-skew", 0, "Zipf exponent of account activity for load generator, 0 for all accounts equally active, no effect with --closed-loop")
+	noteSkew := flag.Float64("note-skew", 0, "Zipf exponent of which notes accounts access for load generator, 0 for all notes equally")
+	diurnalPeriod := flag.Duration("diurnal-period", 0, "Length of a simulated day the request rate follows for load generator, 0 for a constant rate")
+	diurnalAmplitude := flag.Float64("diurnal-amplitude", 0.5, "Share the request rate swings around its average over a simulated day")
+	noteSize := flag.Int("note-size", 0, "Median length of note contents for load generator, 0 for short notes")
+	checkNames := flag.String("checks", "", "Comma-separated consistency checks the load generator runs on top of comparing note contents (read-your-writes, monotonic-reads, cross-account)")
+	listGrace := flag.Duration("list-grace", 0, "How long after creating a note the load generator accepts it missing from lists")
+	closedLoop := flag.Int("closed-loop", 0, "Workers sending operations back to back instead of --rpm for load generator, 0 for open-loop load")
+	targetP99 := flag.Duration("target-p99", 0, "p99 operation latency to stay within by adjusting the --closed-loop workers, 0 for a fixed number of workers")
+	controlInterval := flag.Duration("control-interval", 5*time.Second, "How often the closed-loop controller measures latency and adjusts the workers")
+	loadCurve := flag.String("load-curve", "", "File to write the throughput-versus-latency curve of a closed-loop run to as JSON")
+	runReport := flag.String("run-report", "", "File to write the report of the load generator run to as JSON once it stopped, with a Markdown version next to it")
+	replaySpeed := flag.Float64("replay-speed", 1, "Pace of --replay-traffic relative to the recording, 0 to replay as fast as possible")
+
+	// Verifier flags
+	verifyMode := flag.Bool("verify", false, "Verify consistency of the legacy and shard note stores and exit")
+	verifyFormat := flag.String("verify-format", "text", "Report format for --verify (text or json)")
+	repair := flag.Bool("repair", false, "Include a repair plan in the --verify report")
+
+	// Snapshot flags
+	snapshotName := flag.String("snapshot", "", "Snapshot all stores under the given name and exit")
+	restoreName := flag.String("restore", "", "Restore the snapshot with the given name into .data and exit")
+	listSnapshots := flag.Bool("snapshots", false, "List all snapshots and exit")
 
 	// Clean flag
 	clean := flag.Bool("clean", false, "Delete the .data directory before starting")
@@ -81,18 +150,62 @@ func main() {
 		}
 	}
 
+	workload := loadgen.Workload{
+		Arrivals:         loadgen.ArrivalProcess(*arrivals),
+		AccountSkew:      *accountSkew,
+		NoteSkew:         *noteSkew,
+		DiurnalPeriod:    telemetry.Duration(*diurnalPeriod),
+		DiurnalAmplitude: *diurnalAmplitude,
+		NoteSize:         *noteSize,
+	}
+
+	checks, err := loadgen.ParseConsistencyChecks(*checkNames, *listGrace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	closedLoopConfig := loadgen.ClosedLoop{
+		Concurrency: *closedLoop,
+		TargetP99:   telemetry.Duration(*targetP99),
+		Interval:    telemetry.Duration(*controlInterval),
+	}
+
 	config := Config{
 		CLIMode:         *cliMode,
 		Theme:           *theme,
 		Port:            *port,
 		LogLevel:        *logLevel,
+		TraceFile:       *traceFile,
+		LogFile:         *logFile,
+		StatsDir:        *statsDir,
+		Replay:          *replay,
+		SLOFile:         *sloFile,
+		Seed:            *seed,
 		ProxyMode:       *proxyMode,
 		ProxyPort:       *proxyPort,
 		ProxyID:         *proxyID,
 		EnableLoadGen:   *enableLoadGen,
+		LoadGenMode:     *loadGenMode,
+		Target:          *target,
 		AccountCount:    *accountCount,
 		NotesPerAccount: *notesPerAccount,
 		RequestsPerMin:  *requestsPerMin,
+		Scenario:        *scenarioFile,
+		ScenarioReport:  *scenarioReport,
+		RecordTraffic:   *recordTraffic,
+		ReplayTraffic:   *replayTraffic,
+		ReplaySpeed:     *replaySpeed,
+		Workload:        workload,
+		Checks:          checks,
+		ClosedLoop:      closedLoopConfig,
+		LoadCurve:       *loadCurve,
+		RunReport:       *runReport,
+		VerifyMode:      *verifyMode,
+		VerifyFormat:    *verifyFormat,
+		Repair:          *repair,
+		SnapshotName:    *snapshotName,
+		RestoreName:     *restoreName,
+		ListSnapshots:   *listSnapshots,
 	}
 
 	if err := Run(config); err != nil {
@@ -137,19 +250,20 @@ type ApplicationComponents struct {
 	DeploymentController *proxy.DeploymentController
 	Telemetry            *telemetry.Telemetry
 	HTTPServer           *http.Server
-	Simulator            *Simulator
+	TrafficRecorder      *restapi.TrafficRecorder
+	Simulator            *loadgen.Simulator
 }
 
 // initializeStores creates and initializes the account and note stores
-func initializeStores(tel *telemetry.Telemetry) (store.AccountStore, store.NoteStore, *proxy.DeploymentController, error) {
+func initializeStores(tel *telemetry.Telemetry, seed int64) (store.AccountStore, store.NoteStore, *proxy.DeploymentController, error) {
 	// Create account store first
-	accountStore, err := store.NewAccountStore(store.DefaultStoreOptions("accounts", tel.GetLogger()))
+	accountStore, err := store.NewAccountStore(store.DefaultStoreOptions(constants.AccountStore, tel.GetLogger()))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("could not create account store: %w", err)
 	}
 
 	// Create deployment controller with telemetry and account store
-	deploymentController := proxy.NewDeploymentController(tel, accountStore)
+	deploymentController := proxy.NewDeploymentController(tel, accountStore, proxy.WithSeed(seed))
 
 	// Perform initial deployment
 	if err := deploymentController.Deploy(); err != nil {
@@ -164,8 +278,8 @@ func initializeStores(tel *telemetry.Telemetry) (store.AccountStore, store.NoteS
 }
 
 // setupTelemetry creates and starts the telemetry system
-func setupTelemetry(cliMode bool, logLevel string) *telemetry.Telemetry {
-	var options []telemetry.TelemetryOption
+func setupTelemetry(cliMode bool, logLevel string, extraOptions ...telemetry.TelemetryOption) *telemetry.Telemetry {
+	options := extraOptions
 	if cliMode {
 		options = append(options, telemetry.WithCLIMode(true))
 	}
@@ -179,19 +293,45 @@ func setupTelemetry(cliMode bool, logLevel string) *telemetry.Telemetry {
 	return tel
 }
 
-// createSimulator creates a load generator simulator if enabled
-func createSimulator(config Config, tel *telemetry.Telemetry, port string) *Simulator {
-	if !config.EnableLoadGen {
+// createSimulator creates a load generator simulator against the API at baseURL if enabled.
+// Running a scenario, replaying traffic or closed-loop load enables it.
+func createSimulator(config Config, tel *telemetry.Telemetry, baseURL string, scenario *loadgen.Scenario, traffic []restapi.TrafficRecord) *loadgen.Simulator {
+	if !config.EnableLoadGen && scenario == nil && traffic == nil && config.ClosedLoop.Concurrency == 0 {
 		return nil
 	}
 
-	simOptions := SimulatorOptions{
-		AccountCount:    config.AccountCount,
-		NotesPerAccount: config.NotesPerAccount,
-		RequestsPerMin:  config.RequestsPerMin,
-		ServerPort:      port,
+	simOptions := loadgen.SimulatorOptions{
+		AccountCount:       config.AccountCount,
+		NotesPerAccount:    config.NotesPerAccount,
+		RequestsPerMin:     config.RequestsPerMin,
+		BaseURL:            baseURL,
+		ReuseAccounts:      config.LoadGenMode,
+		Scenario:           scenario,
+		ScenarioReportFile: config.ScenarioReport,
+		Seed:               config.Seed,
+		Traffic:            traffic,
+		TrafficSpeed:       config.ReplaySpeed,
+		Workload:           config.Workload,
+		Checks:             config.Checks,
+		LoadCurveFile:      config.LoadCurve,
+		RunReportFile:      config.RunReport,
+	}
+	if config.ClosedLoop.Concurrency > 0 {
+		simOptions.ClosedLoop = &config.ClosedLoop
+	}
+	if scenario != nil && scenario.Accounts > 0 {
+		simOptions.AccountCount = scenario.Accounts
+	}
+	if scenario != nil && scenario.NotesPerAccount > 0 {
+		simOptions.NotesPerAccount = scenario.NotesPerAccount
 	}
-	return NewSimulator(tel, simOptions)
+	if scenario != nil && scenario.Workload != nil {
+		simOptions.Workload = *scenario.Workload
+	}
+	if scenario != nil && scenario.Checks != nil {
+		simOptions.Checks = *scenario.Checks
+	}
+	return loadgen.NewSimulator(tel, simOptions)
 }
 
 // preparePort ensures the port has the correct format and checks availability
@@ -209,6 +349,70 @@ func preparePort(port string) (string, error) {
 	return port, nil
 }
 
+// loadLoadGenInputs validates the workload and loads the scenario and traffic recording to run, if any
+func loadLoadGenInputs(config Config) (*loadgen.Scenario, []restapi.TrafficRecord, error) {
+	if err := config.Workload.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid workload: %w", err)
+	}
+
+	if config.ClosedLoop.Concurrency > 0 {
+		if err := config.ClosedLoop.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid closed-loop load: %w", err)
+		}
+		if config.Scenario != "" || config.ReplayTraffic != "" {
+			return nil, nil, fmt.Errorf("--closed-loop cannot be combined with --scenario or --replay-traffic")
+		}
+	} else if config.ClosedLoop.Concurrency < 0 {
+		return nil, nil, fmt.Errorf("--closed-loop must not be negative")
+	} else if config.ClosedLoop.TargetP99 != 0 {
+		return nil, nil, fmt.Errorf("--target-p99 requires --closed-loop")
+	}
+
+	var scenario *loadgen.Scenario
+	if config.Scenario != "" {
+		var err error
+		scenario, err = loadgen.LoadScenario(config.Scenario)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if config.ReplayTraffic == "" {
+		return scenario, nil, nil
+	}
+	if scenario != nil {
+		return nil, nil, fmt.Errorf("--replay-traffic cannot be combined with --scenario")
+	}
+	if config.ReplayTraffic == config.RecordTraffic {
+		return nil, nil, fmt.Errorf("--replay-traffic and --record-traffic must be different files")
+	}
+	if config.ReplaySpeed < 0 {
+		return nil, nil, fmt.Errorf("--replay-speed must not be negative")
+	}
+	traffic, err := restapi.LoadTrafficRecording(config.ReplayTraffic)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(traffic) == 0 {
+		return nil, nil, fmt.Errorf("no traffic recorded in %s", config.ReplayTraffic)
+	}
+	return nil, traffic, nil
+}
+
+// resolveSeed returns the seed of a run: the given seed, the seed of the scenario or a random seed, in that order.
+// Runs can only be reproduced with their seed, so it is logged and recorded as an event.
+func resolveSeed(tel *telemetry.Telemetry, seed int64, scenario *loadgen.Scenario) int64 {
+	if seed == 0 && scenario != nil {
+		seed = scenario.Seed
+	}
+	if seed == 0 {
+		seed = rand.Int64()
+	}
+	tel.GetLogger().Info("Using seed, pass --seed to reproduce this run", "seed", seed)
+	tel.RecordEvent("Running with seed %d", seed)
+	return seed
+}
+
 // initializeApplication sets up all application components
 func initializeApplication(config Config) (*ApplicationComponents, error) {
 	port, err := preparePort(config.Port)
@@ -216,10 +420,38 @@ func initializeApplication(config Config) (*ApplicationComponents, error) {
 		return nil, err
 	}
 
+	var slos []telemetry.SLO
+	if config.SLOFile != "" {
+		slos, err = telemetry.LoadSLOs(config.SLOFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scenario, traffic, err := loadLoadGenInputs(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var trafficRecorder *restapi.TrafficRecorder
+	if config.RecordTraffic != "" {
+		trafficRecorder, err = restapi.NewTrafficRecorder(config.RecordTraffic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create telemetry first so it can be passed to all components
-	tel := setupTelemetry(config.CLIMode, config.LogLevel)
+	tel := setupTelemetry(config.CLIMode, config.LogLevel,
+		telemetry.WithTraceFile(config.TraceFile),
+		telemetry.WithLogFile(config.LogFile),
+		telemetry.WithStatsDir(config.StatsDir),
+		telemetry.WithSLOs(slos),
+	)
+
+	config.Seed = resolveSeed(tel, config.Seed, scenario)
 
-	accountStore, noteStore, deploymentController, err := initializeStores(tel)
+	accountStore, noteStore, deploymentController, err := initializeStores(tel, config.Seed)
 	if err != nil {
 		return nil, err
 	}
@@ -233,8 +465,8 @@ func initializeApplication(config Config) (*ApplicationComponents, error) {
 		Telemetry:            tel,
 	}
 
-	httpServer := createHTTPServer(appConfig, port)
-	simulator := createSimulator(config, tel, port)
+	httpServer := createHTTPServer(appConfig, port, trafficRecorder)
+	simulator := createSimulator(config, tel, "http://localhost"+port, scenario, traffic)
 
 	return &ApplicationComponents{
 		AccountStore:         accountStore,
@@ -242,6 +474,7 @@ func initializeApplication(config Config) (*ApplicationComponents, error) {
 		DeploymentController: deploymentController,
 		Telemetry:            tel,
 		HTTPServer:           httpServer,
+		TrafficRecorder:      trafficRecorder,
 		Simulator:            simulator,
 	}, nil
 }
@@ -253,15 +486,40 @@ func Run(config Config) error {
 			return fmt.Errorf("--proxy-port is required when using --proxy")
 		}
 
-		tel := setupTelemetry(false, config.LogLevel)
+		tel := setupTelemetry(false, config.LogLevel,
+			telemetry.WithServiceName(fmt.Sprintf("data-proxy-%d", config.ProxyID)),
+			telemetry.WithTraceFile(config.TraceFile),
+			telemetry.WithJSONLogs(true),
+		)
+		defer tel.Close()
+
+		return runDataProxy(config.ProxyID, config.ProxyPort, tel.GetLogger(), tel.GetTracer(), config.Seed)
+	}
+
+	if config.VerifyMode {
+		return runVerify(config)
+	}
+
+	if config.SnapshotName != "" || config.RestoreName != "" || config.ListSnapshots {
+		return runSnapshot(config)
+	}
+
+	if config.Replay != "" {
+		return runReplay(config)
+	}
 
-		return runDataProxy(config.ProxyID, config.ProxyPort, tel.GetLogger())
+	if config.LoadGenMode {
+		return runLoadGen(config)
 	}
 
 	components, err := initializeApplication(config)
 	if err != nil {
 		return err
 	}
+	defer components.Telemetry.Close()
+	defer components.TrafficRecorder.Close()
+	// Data proxies only receive signals sent to the process group, so shut them down when exiting on our own
+	defer components.DeploymentController.Close()
 
 	if config.CLIMode {
 		options := cli.CLIOptions{
@@ -282,7 +540,7 @@ func Run(config Config) error {
 }
 
 // runDataProxy starts a data proxy server on the specified port
-func runDataProxy(id int, port int, logger *slog.Logger) error {
+func runDataProxy(id int, port int, logger *slog.Logger, tracer *telemetry.Tracer, seed int64) error {
 	// Create context that cancels on signals
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -297,7 +555,7 @@ func runDataProxy(id int, port int, logger *slog.Logger) error {
 	}()
 
 	// Create data proxy with notes shard
-	dataProxy, err := proxy.NewDataProxy(id, port, logger)
+	dataProxy, err := proxy.NewDataProxy(id, port, logger, tracer, seed)
 	if err != nil {
 		return fmt.Errorf("failed to create data proxy: %w", err)
 	}
@@ -306,7 +564,131 @@ func runDataProxy(id int, port int, logger *slog.Logger) error {
 	return dataProxy.Run(ctx)
 }
 
-func runWithCLI(httpServer *http.Server, appConfig *AppConfig, options cli.CLIOptions, simulator *Simulator) error {
+// runReplay opens the TUI against recorded stats, without starting the API or any data proxies
+func runReplay(config Config) error {
+	frames, err := telemetry.LoadRecording(config.Replay)
+	if err != nil {
+		return fmt.Errorf("could not load recording: %w", err)
+	}
+
+	replay := telemetry.NewReplayStatsCollector(frames)
+	tel := setupTelemetry(true, config.LogLevel, telemetry.WithStatsCollector(replay))
+	defer tel.Close()
+
+	return cli.RunCLI(nil, nil, tel, nil, cli.CLIOptions{
+		Theme:  config.Theme,
+		Replay: replay,
+	})
+}
+
+// runLoadGen generates load against the API at the target URL from this process, without starting the API or any data proxies.
+// It runs until interrupted or until a scenario or traffic replay completed, failing if it did not pass.
+func runLoadGen(config Config) error {
+	scenario, traffic, err := loadLoadGenInputs(config)
+	if err != nil {
+		return err
+	}
+
+	tel := setupTelemetry(false, config.LogLevel,
+		telemetry.WithServiceName("loadgen"),
+		telemetry.WithTraceFile(config.TraceFile),
+	)
+	defer tel.Close()
+
+	config.Seed = resolveSeed(tel, config.Seed, scenario)
+	config.EnableLoadGen = true
+	simulator := createSimulator(config, tel, strings.TrimSuffix(config.Target, "/"), scenario, traffic)
+
+	tel.GetLogger().Info("Generating load", "target", config.Target)
+	if err := simulator.Start(); err != nil {
+		return fmt.Errorf("load generator failed to start: %w", err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-stop:
+	case err = <-simulator.Done():
+	}
+	simulator.Stop()
+	return err
+}
+
+// runVerify diffs the note stores offline and prints the report to stdout
+func runVerify(config Config) error {
+	if config.VerifyFormat != "text" && config.VerifyFormat != "json" {
+		return fmt.Errorf("--verify-format must be text or json, got %q", config.VerifyFormat)
+	}
+
+	tel := setupTelemetry(false, config.LogLevel)
+	defer tel.Close()
+
+	report, err := verify.Run(context.Background(), verify.Options{
+		Repair: config.Repair,
+		Logger: tel.GetLogger(),
+	})
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	if config.VerifyFormat == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("found %d inconsistencies", len(report.Findings))
+	}
+	return nil
+}
+
+// runSnapshot creates, restores or lists snapshots of the .data directory
+func runSnapshot(config Config) error {
+	tel := setupTelemetry(false, config.LogLevel)
+	defer tel.Close()
+	opts := snapshot.Options{Logger: tel.GetLogger()}
+
+	switch {
+	case config.SnapshotName != "":
+		manifest, err := snapshot.Create(context.Background(), opts, config.SnapshotName)
+		if err != nil {
+			return fmt.Errorf("could not create snapshot: %w", err)
+		}
+		fmt.Printf("Created snapshot %s with %d stores\n", manifest.Name, len(manifest.Stores))
+	case config.RestoreName != "":
+		manifest, err := snapshot.Restore(opts, config.RestoreName)
+		if err != nil {
+			return fmt.Errorf("could not restore snapshot: %w", err)
+		}
+		fmt.Printf("Restored snapshot %s taken at %s\n", manifest.Name, manifest.CreatedAt.Format(time.RFC3339))
+	default:
+		manifests, err := snapshot.List(opts)
+		if err != nil {
+			return fmt.Errorf("could not list snapshots: %w", err)
+		}
+		if len(manifests) == 0 {
+			fmt.Println("No snapshots")
+		}
+		for _, m := range manifests {
+			var size int64
+			stores := make([]string, 0, len(m.Stores))
+			for _, f := range m.Stores {
+				size += f.Size
+				stores = append(stores, f.Store)
+			}
+			fmt.Printf("%-24s %s  %8d bytes  %s\n", m.Name, m.CreatedAt.Format(time.RFC3339), size, strings.Join(stores, ","))
+		}
+	}
+
+	return nil
+}
+
+func runWithCLI(httpServer *http.Server, appConfig *AppConfig, options cli.CLIOptions, simulator *loadgen.Simulator) error {
 	logger := appConfig.Telemetry.GetLogger()
 
 	// Start server first, then validate health before starting CLI
@@ -379,25 +761,31 @@ func runWithCLI(httpServer *http.Server, appConfig *AppConfig, options cli.CLIOp
 	}
 }
 
-func runHTTPServer(httpServer *http.Server, simulator *Simulator, tel *telemetry.Telemetry) error {
+func runHTTPServer(httpServer *http.Server, simulator *loadgen.Simulator, tel *telemetry.Telemetry) error {
 	// Set up signal handling for graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	stopError := make(chan error, 1)
 	go func() {
-		<-stop
-		// Stop load generator on shutdown signal
+		// Shut down on a signal or once a scenario or traffic replay completed, failing if it did not pass
+		var err error
+		select {
+		case <-stop:
+		case err = <-simulator.Done():
+		}
+
+		// Stop load generator on shutdown
 		if simulator != nil {
 			simulator.Stop()
 		}
-		stopError <- nil // Normal shutdown signal
+		stopError <- err
 	}()
 
 	return runServer(httpServer, stopError, simulator, tel)
 }
 
-func runServer(httpServer *http.Server, shutdownTrigger <-chan error, simulator *Simulator, tel *telemetry.Telemetry) error {
+func runServer(httpServer *http.Server, shutdownTrigger <-chan error, simulator *loadgen.Simulator, tel *telemetry.Telemetry) error {
 	logger := tel.GetLogger()
 
 	// Start HTTP server in background
@@ -438,7 +826,7 @@ func runServer(httpServer *http.Server, shutdownTrigger <-chan error, simulator
 	}
 }
 
-func createHTTPServer(appConfig *AppConfig, port string) *http.Server {
+func createHTTPServer(appConfig *AppConfig, port string, trafficRecorder *restapi.TrafficRecorder) *http.Server {
 	server := restapi.NewServer(
 		restapi.WithAccountStore(appConfig.AccountStore),
 		restapi.WithNoteStore(appConfig.NoteStore),
@@ -448,8 +836,11 @@ func createHTTPServer(appConfig *AppConfig, port string) *http.Server {
 	mux := http.NewServeMux()
 	server.SetupRoutes(mux)
 
-	return &http.Server{
+	httpServer := &http.Server{
 		Addr:    port,
-		Handler: server.LoggingMiddleware(mux),
+		Handler: server.LoggingMiddleware(trafficRecorder.Middleware(mux)),
 	}
+	httpServer.RegisterOnShutdown(server.CloseStreams)
+
+	return httpServer
 }
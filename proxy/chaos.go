@@ -0,0 +1,243 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/constants"
+)
+
+var (
+	// ErrInvalidChaos is returned for chaos configurations and experiments with parameters out of range
+	ErrInvalidChaos = errors.New("invalid chaos experiment")
+	// ErrNoProxyToFault is returned by process faults when no proxy process is running
+	ErrNoProxyToFault = errors.New("no running proxy to fault")
+)
+
+// processFaultTarget labels process faults in stats, next to RPC methods and stores
+const processFaultTarget = "process"
+
+// Chaos returns the faults proxies are configured to inject
+func (dc *DeploymentController) Chaos() chaos.Config {
+	dc.chaosMu.Lock()
+	defer dc.chaosMu.Unlock()
+	return dc.chaos
+}
+
+// SetChaos configures the faults all proxies inject, including proxies launched or restarted later
+func (dc *DeploymentController) SetChaos(ctx context.Context, config chaos.Config) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidChaos, err)
+	}
+
+	dc.chaosMu.Lock()
+	dc.chaos = config
+	dc.chaosVersion++
+	dc.chaosMu.Unlock()
+
+	dc.mu.RLock()
+	proxies := []*DataProxyProcess{dc.current, dc.previous}
+	dc.mu.RUnlock()
+
+	var errs []error
+	for _, proxy := range proxies {
+		if proxy == nil {
+			continue
+		}
+		if err := proxy.ProxyClient.SetChaos(ctx, config); err != nil {
+			errs = append(errs, fmt.Errorf("failed to configure chaos of proxy v%d: %w", proxy.ID, err))
+		}
+	}
+
+	fmt.Fprintf(dc.telemetry.LogCapture, "Configured chaos: %s\n", config)
+	dc.telemetry.RecordEvent("Chaos: %s", config)
+
+	return errors.Join(errs...)
+}
+
+// chaosConfig returns the chaos configuration with its version, which changes every time it is set
+func (dc *DeploymentController) chaosConfig() (chaos.Config, int) {
+	dc.chaosMu.Lock()
+	defer dc.chaosMu.Unlock()
+	return dc.chaos, dc.chaosVersion
+}
+
+// configureChaos passes the current chaos configuration on to a proxy process that just started.
+// It returns the version of the configuration for reconfigureChaos.
+func (dc *DeploymentController) configureChaos(proxy *DataProxyProcess) int {
+	config, version := dc.chaosConfig()
+	if config.Enabled() {
+		dc.sendChaos(proxy, config)
+	}
+	return version
+}
+
+// reconfigureChaos passes chaos configured since a proxy was launched on to it once it became current, as SetChaos
+// only reaches the proxies that are current or previous at the time. It sends again until the configuration stops
+// changing, so an outdated configuration arriving after a newer one from SetChaos is replaced.
+func (dc *DeploymentController) reconfigureChaos(proxy *DataProxyProcess, version int) {
+	for {
+		config, latest := dc.chaosConfig()
+		if latest == version {
+			return
+		}
+		dc.sendChaos(proxy, config)
+		version = latest
+	}
+}
+
+// sendChaos configures the faults of a proxy, logging failures
+func (dc *DeploymentController) sendChaos(proxy *DataProxyProcess, config chaos.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.HealthCheckTimeout)
+	defer cancel()
+
+	if err := proxy.ProxyClient.SetChaos(ctx, config); err != nil {
+		fmt.Fprintf(dc.telemetry.LogCapture, "Failed to configure chaos of proxy v%d: %v\n", proxy.ID, err)
+	}
+}
+
+// KillProxy kills a random running proxy with SIGKILL and returns its ID.
+// The process monitor restarts the current proxy, a killed previous proxy stays down until the rollout completes.
+func (dc *DeploymentController) KillProxy() (int, error) {
+	dc.mu.Lock()
+	target := dc.pickFaultTarget()
+	if target == nil {
+		dc.mu.Unlock()
+		return 0, ErrNoProxyToFault
+	}
+
+	if err := target.kill(); err != nil {
+		dc.mu.Unlock()
+		return 0, fmt.Errorf("failed to kill proxy v%d: %w", target.ID, err)
+	}
+	dc.mu.Unlock()
+
+	_ = dc.telemetry.GetStatsCollector().TrackInjectedFault(chaos.FaultKill, processFaultTarget, target.ID)
+	fmt.Fprintf(dc.telemetry.LogCapture, "Chaos: killed proxy v%d with SIGKILL\n", target.ID)
+	dc.telemetry.RecordEvent("Chaos: killed proxy v%d", target.ID)
+
+	return target.ID, nil
+}
+
+// PauseProxy stops a random running proxy with SIGSTOP for the given duration and returns its ID.
+// Calls to the paused proxy hang until it is resumed with SIGCONT, the process monitor does not restart it meanwhile.
+func (dc *DeploymentController) PauseProxy(duration time.Duration) (int, error) {
+	if duration <= 0 {
+		return 0, fmt.Errorf("%w: pause duration must be positive", ErrInvalidChaos)
+	}
+
+	dc.mu.Lock()
+	target := dc.pickFaultTarget()
+	if target == nil {
+		dc.mu.Unlock()
+		return 0, ErrNoProxyToFault
+	}
+
+	process := target.Process
+	if err := process.Signal(syscall.SIGSTOP); err != nil {
+		dc.mu.Unlock()
+		return 0, fmt.Errorf("failed to pause proxy v%d: %w", target.ID, err)
+	}
+	target.paused = true
+	dc.mu.Unlock()
+
+	_ = dc.telemetry.GetStatsCollector().TrackInjectedFault(chaos.FaultPause, processFaultTarget, target.ID)
+	fmt.Fprintf(dc.telemetry.LogCapture, "Chaos: paused proxy v%d with SIGSTOP for %s\n", target.ID, duration)
+	dc.telemetry.RecordEvent("Chaos: paused proxy v%d for %s", target.ID, duration)
+
+	time.AfterFunc(duration, func() {
+		// Resume before the monitor checks the proxy again, a process shut down meanwhile ignores the signal
+		_ = process.Signal(syscall.SIGCONT)
+
+		dc.mu.Lock()
+		target.paused = false
+		dc.mu.Unlock()
+
+		fmt.Fprintf(dc.telemetry.LogCapture, "Chaos: resumed proxy v%d with SIGCONT\n", target.ID)
+		dc.telemetry.RecordEvent("Chaos: resumed proxy v%d", target.ID)
+	})
+
+	return target.ID, nil
+}
+
+// pickFaultTarget chooses a random running proxy that is not paused. The caller must hold dc.mu.
+func (dc *DeploymentController) pickFaultTarget() *DataProxyProcess {
+	var candidates []*DataProxyProcess
+	for _, proxy := range []*DataProxyProcess{dc.current, dc.previous} {
+		if proxy != nil && proxy.Process != nil && !proxy.paused {
+			candidates = append(candidates, proxy)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[dc.chaosRand.IntN(len(candidates))]
+}
+
+// Partitions returns the partitions between the API and proxies
+func (dc *DeploymentController) Partitions() chaos.Partitions {
+	return dc.network.Partitions()
+}
+
+// SetPartitions replaces the partitions between the API and proxies, an empty set heals the network.
+// Partitions are keyed by proxy ID, so they also apply to proxies deployed later.
+func (dc *DeploymentController) SetPartitions(partitions chaos.Partitions) error {
+	if err := dc.network.SetPartitions(partitions); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidChaos, err)
+	}
+
+	fmt.Fprintf(dc.telemetry.LogCapture, "Configured network partitions: %s\n", partitions)
+	dc.telemetry.RecordEvent("Partitions: %s", partitions)
+	return nil
+}
+
+// PartitionProxy partitions the API from a single proxy, leaving other partitions in place, and returns its ID.
+// The proxy is selected by ID, or as the current or previous proxy at the time of the call.
+func (dc *DeploymentController) PartitionProxy(selector string, partition chaos.Partition) (int, error) {
+	proxyID, err := dc.resolveProxy(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := dc.network.SetPartition(proxyID, partition); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidChaos, err)
+	}
+
+	fmt.Fprintf(dc.telemetry.LogCapture, "Partitioned proxy v%d: %s\n", proxyID, partition)
+	dc.telemetry.RecordEvent("Partitioned proxy v%d: %s", proxyID, partition)
+	return proxyID, nil
+}
+
+// resolveProxy returns the ID of the proxy a selector refers to
+func (dc *DeploymentController) resolveProxy(selector string) (int, error) {
+	var proxy *DataProxyProcess
+	switch selector {
+	case chaos.CurrentProxy:
+		proxy = dc.Current()
+	case chaos.PreviousProxy:
+		proxy = dc.Previous()
+	default:
+		proxyID, err := strconv.Atoi(strings.TrimPrefix(selector, "v"))
+		if err != nil || proxyID <= 0 {
+			return 0, fmt.Errorf("%w: unknown proxy %q", ErrInvalidChaos, selector)
+		}
+		return proxyID, nil
+	}
+
+	if proxy == nil {
+		return 0, fmt.Errorf("%w: no %s proxy", ErrNoProxyToFault, selector)
+	}
+	return proxy.ID, nil
+}
+
+// blackholed returns true if requests to the proxy are dropped, so failed health checks do not mean it crashed
+func (dc *DeploymentController) blackholed(proxy *DataProxyProcess) bool {
+	partition, ok := dc.network.Partitioned(proxy.ID)
+	return ok && partition.Mode == chaos.PartitionBlackhole
+}
@@ -5,22 +5,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
 	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
 )
 
-// AccountDetails contains account information including ID, migration status, and shard
+// AccountDetails contains account information including ID, migration state, and shard
 type AccountDetails struct {
-	AccountID   uuid.UUID `json:"accountId"`
-	IsMigrating bool      `json:"isMigrating"`
-	Shard       *string   `json:"shard,omitempty"`
+	AccountID      uuid.UUID            `json:"accountId"`
+	Shard          *string              `json:"shard,omitempty"`
+	MigrationState store.MigrationState `json:"migrationState"`
+}
+
+// TargetShard returns the shard the account is migrated to, defaulting to the new note store
+func (a AccountDetails) TargetShard() string {
+	if a.Shard != nil && *a.Shard != "" {
+		return *a.Shard
+	}
+	return constants.NewNoteStore
 }
 
 // ProxyClient implements NoteStore interface by sending JSON RPC requests to a data proxy
@@ -29,22 +38,38 @@ type ProxyClient struct {
 	baseURL        string
 	client         *http.Client
 	statsCollector telemetry.StatsCollector
+	rng            *util.Rand // Draws the simulated network delay
 }
 
-// NewProxyClient creates a new proxy client
-func NewProxyClient(id int, addr string, statsCollector telemetry.StatsCollector) *ProxyClient {
+// NewProxyClient creates a new proxy client. Simulated network delays are drawn from the given seed.
+// Requests are subject to the partitions of the network, a nil network never partitions the proxy.
+func NewProxyClient(id int, addr string, statsCollector telemetry.StatsCollector, seed int64, network *chaos.Network) *ProxyClient {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	if network != nil {
+		client.Transport = network.Transport(id, http.DefaultTransport)
+	}
+
 	return &ProxyClient{
-		id:      id,
-		baseURL: addr,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		id:             id,
+		baseURL:        addr,
+		client:         client,
 		statsCollector: statsCollector,
+		rng:            util.NewRand(seed, fmt.Sprintf("proxy client %d", id)),
 	}
 }
 
 // makeJSONRPCRequest sends a JSON RPC request to the proxy server
-func (p *ProxyClient) makeJSONRPCRequest(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+func (p *ProxyClient) makeJSONRPCRequest(ctx context.Context, method string, params interface{}) (result json.RawMessage, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "rpc "+method, telemetry.SpanKindClient)
+	span.SetAttribute("rpc.method", method)
+	span.SetAttribute("proxy.id", p.id)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	request := JSONRPCRequest{
 		Method: method,
 		Params: params,
@@ -56,16 +81,20 @@ func (p *ProxyClient) makeJSONRPCRequest(ctx context.Context, method string, par
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(chaos.WithFaultTarget(ctx, method), "POST", p.baseURL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	telemetry.InjectTraceparent(ctx, req.Header)
+	telemetry.InjectRequestID(ctx, req.Header)
 
 	// Simulate network delay between 1-5ms
-	delay := time.Duration(rand.Intn(constants.MaxNetworkDelayMs)+1) * time.Millisecond
+	_, delaySpan := telemetry.StartSpan(ctx, "network delay", telemetry.SpanKindInternal)
+	delay := time.Duration(p.rng.IntN(constants.MaxNetworkDelayMs)+1) * time.Millisecond
 	time.Sleep(delay)
+	delaySpan.End()
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -124,7 +153,7 @@ func (p *ProxyClient) ListNotesWithMigration(ctx context.Context, accountDetails
 
 // ListNotes implements NoteStore interface
 func (p *ProxyClient) ListNotes(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
-	return p.ListNotesWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false})
+	return p.ListNotesWithMigration(ctx, AccountDetails{AccountID: accountID})
 }
 
 // GetNoteWithMigration calls GetNote with account details
@@ -161,7 +190,7 @@ func (p *ProxyClient) GetNoteWithMigration(ctx context.Context, accountDetails A
 
 // GetNote implements NoteStore interface
 func (p *ProxyClient) GetNote(ctx context.Context, accountID, noteID uuid.UUID) (*store.Note, error) {
-	return p.GetNoteWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false}, noteID)
+	return p.GetNoteWithMigration(ctx, AccountDetails{AccountID: accountID}, noteID)
 }
 
 // CreateNoteWithMigration calls CreateNote with account details
@@ -189,7 +218,7 @@ func (p *ProxyClient) CreateNoteWithMigration(ctx context.Context, accountDetail
 
 // CreateNote implements NoteStore interface
 func (p *ProxyClient) CreateNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	return p.CreateNoteWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false}, note)
+	return p.CreateNoteWithMigration(ctx, AccountDetails{AccountID: accountID}, note)
 }
 
 // UpdateNoteWithMigration calls UpdateNote with account details
@@ -217,7 +246,7 @@ func (p *ProxyClient) UpdateNoteWithMigration(ctx context.Context, accountDetail
 
 // UpdateNote implements NoteStore interface
 func (p *ProxyClient) UpdateNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	return p.UpdateNoteWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false}, note)
+	return p.UpdateNoteWithMigration(ctx, AccountDetails{AccountID: accountID}, note)
 }
 
 // DeleteNoteWithMigration calls DeleteNote with account details
@@ -245,7 +274,7 @@ func (p *ProxyClient) DeleteNoteWithMigration(ctx context.Context, accountDetail
 
 // DeleteNote implements NoteStore interface
 func (p *ProxyClient) DeleteNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	return p.DeleteNoteWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false}, note)
+	return p.DeleteNoteWithMigration(ctx, AccountDetails{AccountID: accountID}, note)
 }
 
 // CountNotesWithMigration calls CountNotes with account details
@@ -269,7 +298,92 @@ func (p *ProxyClient) CountNotesWithMigration(ctx context.Context, accountDetail
 
 // CountNotes implements NoteStore interface
 func (p *ProxyClient) CountNotes(ctx context.Context, accountID uuid.UUID) (int, error) {
-	return p.CountNotesWithMigration(ctx, AccountDetails{AccountID: accountID, IsMigrating: false})
+	return p.CountNotesWithMigration(ctx, AccountDetails{AccountID: accountID})
+}
+
+// ListChangesWithMigration fetches the next batch of note changes after the given cursor
+func (p *ProxyClient) ListChangesWithMigration(ctx context.Context, accountDetails AccountDetails, cursor string, limit int) (batch *ChangeBatch, err error) {
+	if p.statsCollector != nil {
+		start := time.Now()
+		defer func() {
+			status := telemetry.ProxyAccessStatusSuccess
+			if err != nil {
+				status = telemetry.ProxyAccessStatusError
+			}
+			// Track metrics, ignoring errors to avoid disrupting main operation
+			_ = p.statsCollector.TrackProxyAccess("ListChanges", time.Since(start), p.id, status)
+		}()
+	}
+
+	params := map[string]interface{}{
+		"accountDetails": accountDetails,
+		"cursor":         cursor,
+		"limit":          limit,
+	}
+
+	result, err := p.makeJSONRPCRequest(ctx, "ListChanges", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(result, &batch); err != nil {
+		err = fmt.Errorf("failed to unmarshal change batch: %w", err)
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// ApplyMigrationState runs the work required when an account enters its current migration state
+func (p *ProxyClient) ApplyMigrationState(ctx context.Context, accountDetails AccountDetails) (err error) {
+	if p.statsCollector != nil {
+		start := time.Now()
+		defer func() {
+			status := telemetry.ProxyAccessStatusSuccess
+			if err != nil {
+				status = telemetry.ProxyAccessStatusError
+			}
+			// Track metrics, ignoring errors to avoid disrupting main operation
+			_ = p.statsCollector.TrackProxyAccess("ApplyMigrationState", time.Since(start), p.id, status)
+		}()
+	}
+
+	params := map[string]interface{}{
+		"accountDetails": accountDetails,
+	}
+
+	_, err = p.makeJSONRPCRequest(ctx, "ApplyMigrationState", params)
+	return err
+}
+
+// MigrationProgress counts the notes of an account in legacy and in its shard
+func (p *ProxyClient) MigrationProgress(ctx context.Context, accountDetails AccountDetails) (progress *MigrationProgress, err error) {
+	if p.statsCollector != nil {
+		start := time.Now()
+		defer func() {
+			status := telemetry.ProxyAccessStatusSuccess
+			if err != nil {
+				status = telemetry.ProxyAccessStatusError
+			}
+			// Track metrics, ignoring errors to avoid disrupting main operation
+			_ = p.statsCollector.TrackProxyAccess("MigrationProgress", time.Since(start), p.id, status)
+		}()
+	}
+
+	params := map[string]interface{}{
+		"accountDetails": accountDetails,
+	}
+
+	result, err := p.makeJSONRPCRequest(ctx, "MigrationProgress", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &progress); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal migration progress: %w", err)
+	}
+
+	return progress, nil
 }
 
 // GetTotalNotes implements NoteStore interface
@@ -313,3 +427,12 @@ func (p *ProxyClient) ExportShardStats(ctx context.Context) (telemetry.Stats, er
 
 	return stats, nil
 }
+
+// SetChaos configures the faults the proxy injects
+func (p *ProxyClient) SetChaos(ctx context.Context, config chaos.Config) error {
+	params := map[string]interface{}{
+		"config": config,
+	}
+	_, err := p.makeJSONRPCRequest(ctx, "SetChaos", params)
+	return err
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
 	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
 )
 
+// ErrSoakIncomplete is returned when advancing an account to read from its shard before the shadow read soak has passed
+var ErrSoakIncomplete = errors.New("cannot read from shard yet")
+
 // DeploymentStatus represents the current deployment state
 type DeploymentStatus int
 
@@ -51,15 +55,41 @@ type DeploymentController struct {
 	telemetry       *telemetry.Telemetry
 	accountStore    store.AccountStore
 	monitorCancel   context.CancelFunc // Cancel function for monitoring goroutine
+	migrationMu     sync.Mutex         // Serializes migration transitions, so a failed one is reverted before the next starts
+	seed            int64              // Seed of the run, passed on to data proxies
+	routing         *util.Rand         // Chooses between proxies during rollouts
+
+	chaosMu      sync.Mutex
+	chaos        chaos.Config   // Faults all proxies inject
+	chaosVersion int            // Incremented whenever chaos is configured
+	chaosRand    *util.Rand     // Chooses the proxies process faults hit
+	network      *chaos.Network // Partitions requests from the API to proxies
+}
+
+// DeploymentControllerOption defines a functional option for configuring DeploymentController
+type DeploymentControllerOption func(*DeploymentController)
+
+// WithSeed configures the seed that proxy routing and the simulated network delays of all proxies are drawn from
+func WithSeed(seed int64) DeploymentControllerOption {
+	return func(dc *DeploymentController) {
+		dc.seed = seed
+	}
 }
 
 // NewDeploymentController creates a new deployment controller
-func NewDeploymentController(tel *telemetry.Telemetry, accountStore store.AccountStore) *DeploymentController {
-	return &DeploymentController{
+func NewDeploymentController(tel *telemetry.Telemetry, accountStore store.AccountStore, options ...DeploymentControllerOption) *DeploymentController {
+	dc := &DeploymentController{
 		status:       StatusInitial,
 		telemetry:    tel,
 		accountStore: accountStore,
 	}
+	for _, option := range options {
+		option(dc)
+	}
+	dc.routing = util.NewRand(dc.seed, "routing")
+	dc.chaosRand = util.NewRand(dc.seed, "chaos")
+	dc.network = chaos.NewNetwork(tel.GetStatsCollector(), util.NewRand(dc.seed, "network"))
+	return dc
 }
 
 // Current returns the current data proxy process
@@ -141,15 +171,17 @@ func (dc *DeploymentController) Deploy() error {
 		// Initial deployment - no current proxy exists
 		dc.setStatus(StatusRolloutLaunchNew)
 
-		dataProxyProcess, err := LaunchDataProxy(1, dc.telemetry.GetStatsCollector(), dc.telemetry.LogCapture)
+		dataProxyProcess, err := LaunchDataProxy(1, dc.telemetry.GetStatsCollector(), dc.telemetry.LogCapture, dc.telemetry.GetTracer().Path(), dc.seed, dc.network)
 		if err != nil {
 			dc.setStatus(StatusInitial)
 			return fmt.Errorf("failed to launch initial data proxy: %w", err)
 		}
+		dc.configureChaos(dataProxyProcess)
 
 		dc.mu.Lock()
 		dc.current = dataProxyProcess
 		dc.mu.Unlock()
+		dc.telemetry.RecordEvent("Deployed proxy v%d", dataProxyProcess.ID)
 
 		// Start monitoring goroutine for this initial deployment
 		dc.startMonitoring()
@@ -169,11 +201,13 @@ func (dc *DeploymentController) Deploy() error {
 
 	// Launch new proxy with incremented ID
 	newID := previousID + 1
-	newDataProxyProcess, err := LaunchDataProxy(newID, dc.telemetry.GetStatsCollector(), dc.telemetry.LogCapture)
+	dc.telemetry.RecordEvent("Rolling out proxy v%d", newID)
+	newDataProxyProcess, err := LaunchDataProxy(newID, dc.telemetry.GetStatsCollector(), dc.telemetry.LogCapture, dc.telemetry.GetTracer().Path(), dc.seed, dc.network)
 	if err != nil {
 		dc.setStatus(StatusReady)
 		return fmt.Errorf("failed to launch new data proxy: %w", err)
 	}
+	chaosVersion := dc.configureChaos(newDataProxyProcess)
 
 	// Wait for new proxy to be ready before making it current
 	if err := dc.waitForProxyReady(newDataProxyProcess); err != nil {
@@ -186,12 +220,22 @@ func (dc *DeploymentController) Deploy() error {
 	dc.mu.Lock()
 	dc.current = newDataProxyProcess
 	dc.mu.Unlock()
+	dc.telemetry.RecordEvent("Proxy v%d took over from proxy v%d", newID, previousID)
+	dc.reconfigureChaos(newDataProxyProcess, chaosVersion)
+
+	// SLOs already violated before the rollout do not count against the new proxy, and
+	// proxy-scope SLOs only count the requests the new proxy handled
+	violatedBefore := violatedSLOs(dc.telemetry.SLOs.Violations())
+	proxySLOs := dc.telemetry.SLOs.WatchProxy(newID)
 
 	dc.setStatus(StatusRolloutWait)
 
-	// Wait before shutting down previous proxy
+	// Wait before shutting down previous proxy, rolling back if the new proxy violates an SLO
 	go func() {
-		time.Sleep(constants.DeploymentWaitTime)
+		if violation := dc.watchRollout(violatedBefore, proxySLOs); violation != nil {
+			dc.rollback(newDataProxyProcess, *violation)
+			return
+		}
 
 		dc.mu.Lock()
 		prevProxy := dc.previous
@@ -200,6 +244,7 @@ func (dc *DeploymentController) Deploy() error {
 
 		if prevProxy != nil {
 			prevProxy.Shutdown()
+			dc.telemetry.RecordEvent("Shut down proxy v%d", prevProxy.ID)
 		}
 
 		dc.setStatus(StatusReady)
@@ -208,6 +253,63 @@ func (dc *DeploymentController) Deploy() error {
 	return nil
 }
 
+// violatedSLOs returns the names of violated SLOs
+func violatedSLOs(violations []telemetry.SLOStatus) map[string]bool {
+	names := make(map[string]bool, len(violations))
+	for _, violation := range violations {
+		names[violation.SLO.Name] = true
+	}
+	return names
+}
+
+// watchRollout waits for the rollout wait time to pass, returning early with the first SLO
+// violated since the rollout started. Proxy-scope SLOs are only violated by the requests of
+// the new proxy, so the previous proxy still serving requests does not fail the rollout.
+// Without SLOs, it simply waits.
+func (dc *DeploymentController) watchRollout(violatedBefore map[string]bool, proxySLOs *telemetry.ProxySLOWatch) *telemetry.SLOStatus {
+	deadline := time.After(constants.DeploymentWaitTime)
+	ticker := time.NewTicker(telemetry.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-deadline:
+			return nil
+		case <-ticker.C:
+			if violations := proxySLOs.Violations(); len(violations) > 0 {
+				return &violations[0]
+			}
+			for _, violation := range dc.telemetry.SLOs.Violations() {
+				if !violation.SLO.PerProxy() && !violatedBefore[violation.SLO.Name] {
+					return &violation
+				}
+			}
+		}
+	}
+}
+
+// rollback makes the previous proxy current again and shuts down the proxy that violated an SLO
+func (dc *DeploymentController) rollback(failed *DataProxyProcess, violation telemetry.SLOStatus) {
+	dc.mu.Lock()
+	if dc.current != failed || dc.previous == nil {
+		// The controller was closed during the rollout
+		dc.mu.Unlock()
+		return
+	}
+	restored := dc.previous
+	dc.current = restored
+	dc.previous = nil
+	dc.mu.Unlock()
+
+	failed.Shutdown()
+
+	fmt.Fprintf(dc.telemetry.LogCapture, "Rolled back proxy v%d to v%d: SLO %q violated with burn rate %.1f and %.0f%% of its error budget left\n",
+		failed.ID, restored.ID, violation.SLO.Name, violation.BurnRate, violation.BudgetRemaining*100)
+	dc.telemetry.RecordEvent("Rolled back proxy v%d to v%d: SLO %q violated", failed.ID, restored.ID, violation.SLO.Name)
+
+	dc.setStatus(StatusReady)
+}
+
 // Close deployment child proceses and cleans up resources.
 func (dc *DeploymentController) Close() error {
 	// Stop monitoring first
@@ -232,36 +334,185 @@ func (dc *DeploymentController) Close() error {
 }
 
 // getAccountDetails retrieves the account details including migration status and shard
-func (dc *DeploymentController) getAccountDetails(ctx context.Context, accountID uuid.UUID) (AccountDetails, error) {
-	dc.mu.RLock()
-	accountStore := dc.accountStore
-	dc.mu.RUnlock()
+func (dc *DeploymentController) getAccountDetails(ctx context.Context, accountID uuid.UUID) (details AccountDetails, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "getAccountDetails", telemetry.SpanKindInternal)
+	span.SetAttribute("account.id", accountID)
+	defer func() {
+		span.SetAttribute("migration.state", details.MigrationState.Normalize())
+		span.SetError(err)
+		span.End()
+	}()
 
 	// Get account by ID directly
-	account, err := accountStore.GetAccount(ctx, accountID)
+	account, err := dc.getAccountStore().GetAccount(ctx, accountID)
 	if err != nil {
 		if errors.Is(err, store.ErrAccountNotFound) {
 			// Account not found, return default AccountDetails
 			return AccountDetails{
-				AccountID:   accountID,
-				IsMigrating: false,
+				AccountID: accountID,
 			}, nil
 		}
 		return AccountDetails{}, fmt.Errorf("failed to get account: %w", err)
 	}
 
 	return AccountDetails{
-		AccountID:   account.ID,
-		IsMigrating: account.IsMigrating,
-		Shard:       account.Shard,
+		AccountID:      account.ID,
+		Shard:          account.Shard,
+		MigrationState: account.MigrationState.Normalize(),
 	}, nil
 }
 
+// AdvanceMigration moves an account to the next migration phase and runs the work the phase requires.
+// Reads can only be switched to the shard after a soak period without shadow read mismatches.
+func (dc *DeploymentController) AdvanceMigration(ctx context.Context, accountID uuid.UUID) (*store.Account, error) {
+	dc.migrationMu.Lock()
+	defer dc.migrationMu.Unlock()
+
+	account, err := dc.getAccountStore().GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	target, err := account.MigrationState.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if target == store.MigrationStateReadFromNew {
+		if err := dc.checkShadowReadSoak(account); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSoakIncomplete, err)
+		}
+	}
+
+	return dc.transitionMigration(ctx, account, target)
+}
+
+// RollbackMigration moves an account back by one migration phase and runs the work the phase requires
+func (dc *DeploymentController) RollbackMigration(ctx context.Context, accountID uuid.UUID) (*store.Account, error) {
+	dc.migrationMu.Lock()
+	defer dc.migrationMu.Unlock()
+
+	account, err := dc.getAccountStore().GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	target, err := account.MigrationState.Previous()
+	if err != nil {
+		return nil, err
+	}
+
+	return dc.transitionMigration(ctx, account, target)
+}
+
+// MigrationProgress counts the notes of an account in legacy and in its shard
+func (dc *DeploymentController) MigrationProgress(ctx context.Context, accountID uuid.UUID) (*MigrationProgress, error) {
+	account, err := dc.getAccountStore().GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	proxy := dc.selectProxy(ctx)
+	if proxy == nil {
+		return nil, fmt.Errorf("no proxy available")
+	}
+
+	accountDetails := AccountDetails{
+		AccountID:      account.ID,
+		Shard:          account.Shard,
+		MigrationState: account.MigrationState.Normalize(),
+	}
+	return proxy.ProxyClient.MigrationProgress(ctx, accountDetails)
+}
+
+// transitionMigration persists the new migration state, then lets the current proxy apply it.
+// Persisting first makes writes follow the new state while it is applied, e.g. no longer mirroring to a shard being emptied.
+// If the proxy cannot apply the state, the previous state is restored, so the transition and its work can be retried.
+func (dc *DeploymentController) transitionMigration(ctx context.Context, account *store.Account, target store.MigrationState) (*store.Account, error) {
+	dc.mu.RLock()
+	current := dc.current
+	dc.mu.RUnlock()
+
+	if current == nil {
+		return nil, fmt.Errorf("no proxy available to apply migration state %s", target)
+	}
+
+	accountStore := dc.getAccountStore()
+	previous := account.MigrationState.Normalize()
+	account, err := accountStore.TransitionMigrationState(ctx, account.ID, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transition migration state: %w", err)
+	}
+
+	accountDetails := AccountDetails{
+		AccountID:      account.ID,
+		Shard:          account.Shard,
+		MigrationState: account.MigrationState,
+	}
+	if err := current.ProxyClient.ApplyMigrationState(ctx, accountDetails); err != nil {
+		applyErr := fmt.Errorf("failed to apply migration state %s: %w", target, err)
+
+		// Restore the previous state even if the request was cancelled, the account must not stay in a state that was not applied
+		if _, revertErr := accountStore.RevertMigrationState(context.WithoutCancel(ctx), account.ID, target, previous); revertErr != nil {
+			fmt.Fprintf(dc.telemetry.LogCapture, "Account %s stays in migration state %s, which was not applied: %v\n", account.ID, target, revertErr)
+			return nil, fmt.Errorf("%w, could not restore migration state %s: %v", applyErr, previous, revertErr)
+		}
+
+		fmt.Fprintf(dc.telemetry.LogCapture, "Account %s returned to migration state %s: %v\n", account.ID, previous, applyErr)
+		return nil, applyErr
+	}
+
+	fmt.Fprintf(dc.telemetry.LogCapture, "Account %s entered migration state %s\n", account.ID, account.MigrationState)
+	dc.telemetry.RecordEvent("Account %s entered migration state %s", account.ID, account.MigrationState)
+
+	return account, nil
+}
+
+// getAccountStore returns the account store accounts and their migration states are read from
+func (dc *DeploymentController) getAccountStore() store.AccountStore {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.accountStore
+}
+
+// checkShadowReadSoak verifies an account has been verifying for the soak period without shadow read mismatches
+func (dc *DeploymentController) checkShadowReadSoak(account *store.Account) error {
+	if account.MigrationUpdatedAt == nil {
+		return fmt.Errorf("soak period has not started")
+	}
+
+	soakedFor := time.Since(*account.MigrationUpdatedAt)
+	if soakedFor < constants.ShadowReadSoakPeriod {
+		return fmt.Errorf("soak period incomplete: verifying for %s of %s", soakedFor.Round(time.Second), constants.ShadowReadSoakPeriod)
+	}
+
+	stats := dc.telemetry.StatsCollector.Export()
+
+	soakStart := time.Now().Add(-constants.ShadowReadSoakPeriod)
+	verifiedReads := 0
+	for _, shadowRead := range stats.ShadowReads {
+		if shadowRead.AccountID != account.ID.String() {
+			continue
+		}
+
+		verifiedReads += shadowRead.ReadsSince(soakStart)
+		if shadowRead.LastMismatchAt != nil && shadowRead.LastMismatchAt.After(soakStart) {
+			return fmt.Errorf("shadow read mismatch for %s at %s within soak period", shadowRead.Operation, shadowRead.LastMismatchAt.Format(time.TimeOnly))
+		}
+	}
+
+	if verifiedReads == 0 {
+		return fmt.Errorf("no shadow reads verified within soak period")
+	}
+
+	return nil
+}
+
 // NoteStore interface implementation - forwards calls to current/previous proxies
 
 // ListNotes implements NoteStore interface
 func (dc *DeploymentController) ListNotes(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return nil, fmt.Errorf("no proxy available")
 	}
@@ -270,7 +521,7 @@ func (dc *DeploymentController) ListNotes(ctx context.Context, accountID uuid.UU
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.ListNotesWithMigration(ctx, accountDetails)
@@ -278,7 +529,7 @@ func (dc *DeploymentController) ListNotes(ctx context.Context, accountID uuid.UU
 
 // GetNote implements NoteStore interface
 func (dc *DeploymentController) GetNote(ctx context.Context, accountID, noteID uuid.UUID) (*store.Note, error) {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return nil, fmt.Errorf("no proxy available")
 	}
@@ -287,7 +538,7 @@ func (dc *DeploymentController) GetNote(ctx context.Context, accountID, noteID u
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.GetNoteWithMigration(ctx, accountDetails, noteID)
@@ -295,7 +546,7 @@ func (dc *DeploymentController) GetNote(ctx context.Context, accountID, noteID u
 
 // CreateNote implements NoteStore interface
 func (dc *DeploymentController) CreateNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return fmt.Errorf("no proxy available")
 	}
@@ -304,7 +555,7 @@ func (dc *DeploymentController) CreateNote(ctx context.Context, accountID uuid.U
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.CreateNoteWithMigration(ctx, accountDetails, note)
@@ -312,7 +563,7 @@ func (dc *DeploymentController) CreateNote(ctx context.Context, accountID uuid.U
 
 // UpdateNote implements NoteStore interface
 func (dc *DeploymentController) UpdateNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return fmt.Errorf("no proxy available")
 	}
@@ -321,7 +572,7 @@ func (dc *DeploymentController) UpdateNote(ctx context.Context, accountID uuid.U
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.UpdateNoteWithMigration(ctx, accountDetails, note)
@@ -329,7 +580,7 @@ func (dc *DeploymentController) UpdateNote(ctx context.Context, accountID uuid.U
 
 // DeleteNote implements NoteStore interface
 func (dc *DeploymentController) DeleteNote(ctx context.Context, accountID uuid.UUID, note store.Note) error {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return fmt.Errorf("no proxy available")
 	}
@@ -338,7 +589,7 @@ func (dc *DeploymentController) DeleteNote(ctx context.Context, accountID uuid.U
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.DeleteNoteWithMigration(ctx, accountDetails, note)
@@ -346,7 +597,7 @@ func (dc *DeploymentController) DeleteNote(ctx context.Context, accountID uuid.U
 
 // CountNotes implements NoteStore interface
 func (dc *DeploymentController) CountNotes(ctx context.Context, accountID uuid.UUID) (int, error) {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return 0, fmt.Errorf("no proxy available")
 	}
@@ -355,15 +606,32 @@ func (dc *DeploymentController) CountNotes(ctx context.Context, accountID uuid.U
 	accountDetails, err := dc.getAccountDetails(ctx, accountID)
 	if err != nil {
 		// Log error but continue with default values
-		accountDetails = AccountDetails{AccountID: accountID, IsMigrating: false}
+		accountDetails = AccountDetails{AccountID: accountID}
 	}
 
 	return proxy.ProxyClient.CountNotesWithMigration(ctx, accountDetails)
 }
 
+// ListChanges returns the next batch of note changes for an account after the given cursor
+func (dc *DeploymentController) ListChanges(ctx context.Context, accountID uuid.UUID, cursor string, limit int) (*ChangeBatch, error) {
+	proxy := dc.selectProxy(ctx)
+	if proxy == nil {
+		return nil, fmt.Errorf("no proxy available")
+	}
+
+	// Get account details including migration status and shard
+	accountDetails, err := dc.getAccountDetails(ctx, accountID)
+	if err != nil {
+		fmt.Fprintf(dc.telemetry.LogCapture, "Could not get details of account %s, listing changes without them: %v\n", accountID, err)
+		accountDetails = AccountDetails{AccountID: accountID}
+	}
+
+	return proxy.ProxyClient.ListChangesWithMigration(ctx, accountDetails, cursor, limit)
+}
+
 // GetTotalNotes implements NoteStore interface
 func (dc *DeploymentController) GetTotalNotes(ctx context.Context) (int, error) {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return 0, fmt.Errorf("no proxy available")
 	}
@@ -372,15 +640,22 @@ func (dc *DeploymentController) GetTotalNotes(ctx context.Context) (int, error)
 
 // HealthCheck implements NoteStore interface
 func (dc *DeploymentController) HealthCheck(ctx context.Context) error {
-	proxy := dc.selectProxy()
+	proxy := dc.selectProxy(ctx)
 	if proxy == nil {
 		return fmt.Errorf("no proxy available")
 	}
 	return proxy.ProxyClient.HealthCheck(ctx)
 }
 
-// selectProxy chooses which proxy to use for requests
-func (dc *DeploymentController) selectProxy() *DataProxyProcess {
+// selectProxy chooses which proxy to use for requests and records it in the context's ServedBy
+func (dc *DeploymentController) selectProxy(ctx context.Context) *DataProxyProcess {
+	proxy := dc.pickProxy()
+	recordServedBy(ctx, proxy)
+	return proxy
+}
+
+// pickProxy chooses between the current and previous proxy while a rollout is in progress
+func (dc *DeploymentController) pickProxy() *DataProxyProcess {
 	dc.mu.RLock()
 	defer dc.mu.RUnlock()
 
@@ -395,7 +670,7 @@ func (dc *DeploymentController) selectProxy() *DataProxyProcess {
 	}
 
 	// If both are available, randomly choose between them
-	if rand.Intn(2) == 0 {
+	if dc.routing.IntN(2) == 0 {
 		return dc.current
 	}
 	return dc.previous
@@ -438,6 +713,12 @@ func (dc *DeploymentController) StartInstrument() {
 	}()
 }
 
+// proxyStatsSource identifies a proxy's stats when importing them.
+// Restarted proxies keep their ID, the stats epoch tells their snapshots apart.
+func proxyStatsSource(proxyID int) string {
+	return fmt.Sprintf("proxy-%d", proxyID)
+}
+
 // collectProxyStats collects statistics from current and previous proxies
 func (dc *DeploymentController) collectProxyStats() {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.RollingReleaseDelay)
@@ -456,14 +737,14 @@ func (dc *DeploymentController) collectProxyStats() {
 	// Collect stats from current proxy
 	if current != nil {
 		if stats, err := current.ProxyClient.ExportShardStats(ctx); err == nil {
-			telemetry.GetStatsCollector().Import(stats)
+			telemetry.GetStatsCollector().Import(proxyStatsSource(current.ID), stats)
 		}
 	}
 
 	// Collect stats from previous proxy
 	if previous != nil {
 		if stats, err := previous.ProxyClient.ExportShardStats(ctx); err == nil {
-			telemetry.GetStatsCollector().Import(stats)
+			telemetry.GetStatsCollector().Import(proxyStatsSource(previous.ID), stats)
 		}
 	}
 }
@@ -501,8 +782,10 @@ func (dc *DeploymentController) checkAndRestartProcesses() {
 	dc.mu.Lock()
 	defer dc.mu.Unlock()
 
-	// Only check and restart current proxy - ignore previous proxy crashes
-	if dc.current != nil && !dc.current.IsRunning() {
+	// Only check and restart current proxy - ignore previous proxy crashes and proxies paused by chaos experiments.
+	// Blackholed proxies are alive but unreachable, restarting one would launch a second process on its port.
+	if dc.current != nil && !dc.current.paused && !dc.blackholed(dc.current) && !dc.current.IsRunning() {
+		dc.telemetry.RecordEvent("Proxy v%d crashed", dc.current.ID)
 		if dc.current.RestartCount < constants.MaxRestartAttempts {
 			fmt.Fprintf(dc.telemetry.LogCapture, "Current proxy v%d crashed, attempting restart (attempt %d/%d)\n", 
 				dc.current.ID, dc.current.RestartCount+1, constants.MaxRestartAttempts)
@@ -536,6 +819,7 @@ func (dc *DeploymentController) restartProxy(proxy *DataProxyProcess) error {
 	if err := proxy.start(dc.telemetry.GetStatsCollector()); err != nil {
 		return fmt.Errorf("failed to restart proxy process: %w", err)
 	}
+	dc.configureChaos(proxy)
 
 	return nil
 }
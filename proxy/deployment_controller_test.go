@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionMigrationRevertsFailedApply(t *testing.T) {
+	tel := telemetry.New()
+	defer tel.StatsCollector.Stop()
+
+	accountStore, err := store.NewAccountStore(store.StoreOptions{
+		Name:     "accounts",
+		BasePath: t.TempDir(),
+		Config:   store.DefaultDatabaseConfig(),
+	})
+	require.NoError(t, err)
+	defer accountStore.Close()
+
+	// A proxy that fails to apply migration states until told otherwise
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := JSONRPCResponse{ID: 1}
+		if failing.Load() {
+			message := "backfill failed"
+			response.Error = &message
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	dc := NewDeploymentController(tel, accountStore)
+	ctx := context.Background()
+
+	account := store.Account{ID: uuid.New(), Name: "migrating"}
+	require.NoError(t, accountStore.CreateAccount(ctx, account))
+
+	// Without a proxy to apply it, the state is not changed at all
+	_, err = dc.AdvanceMigration(ctx, account.ID)
+	require.Error(t, err)
+
+	dc.current = &DataProxyProcess{ID: 1, ProxyClient: NewProxyClient(1, server.URL, tel.GetStatsCollector(), 0, nil)}
+
+	failing.Store(false)
+	_, err = dc.AdvanceMigration(ctx, account.ID)
+	require.NoError(t, err)
+
+	// A backfill that fails leaves the account dual-writing, so advancing again runs the backfill again
+	failing.Store(true)
+	_, err = dc.AdvanceMigration(ctx, account.ID)
+	require.ErrorContains(t, err, "backfill failed")
+
+	stored, err := accountStore.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.MigrationStateDualWrite, stored.MigrationState)
+
+	failing.Store(false)
+	advanced, err := dc.AdvanceMigration(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.MigrationStateBackfilling, advanced.MigrationState)
+
+	transitions, err := accountStore.ListMigrationTransitions(ctx, account.ID)
+	require.NoError(t, err)
+	var states []store.MigrationState
+	for _, transition := range transitions {
+		states = append(states, transition.To)
+	}
+	require.Equal(t, []store.MigrationState{
+		store.MigrationStateDualWrite,
+		store.MigrationStateBackfilling,
+		store.MigrationStateDualWrite,
+		store.MigrationStateBackfilling,
+	}, states)
+}
+
+// chaosRecorder is a proxy that records the chaos configurations it receives
+type chaosRecorder struct {
+	mu      sync.Mutex
+	configs []chaos.Config
+}
+
+func (r *chaosRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var call struct {
+		Method string `json:"method"`
+		Params struct {
+			Config chaos.Config `json:"config"`
+		} `json:"params"`
+	}
+	json.NewDecoder(req.Body).Decode(&call)
+	if call.Method == "SetChaos" {
+		r.mu.Lock()
+		r.configs = append(r.configs, call.Params.Config)
+		r.mu.Unlock()
+	}
+	json.NewEncoder(w).Encode(JSONRPCResponse{ID: 1})
+}
+
+func (r *chaosRecorder) received() []chaos.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]chaos.Config(nil), r.configs...)
+}
+
+func TestReconfigureChaosAfterTakeover(t *testing.T) {
+	tel := telemetry.New()
+	defer tel.StatsCollector.Stop()
+
+	previous, launched := &chaosRecorder{}, &chaosRecorder{}
+	previousServer, launchedServer := httptest.NewServer(previous), httptest.NewServer(launched)
+	defer previousServer.Close()
+	defer launchedServer.Close()
+
+	dc := NewDeploymentController(tel, nil)
+	dc.current = &DataProxyProcess{ID: 1, ProxyClient: NewProxyClient(1, previousServer.URL, tel.GetStatsCollector(), 0, nil)}
+	newProxy := &DataProxyProcess{ID: 2, ProxyClient: NewProxyClient(2, launchedServer.URL, tel.GetStatsCollector(), 0, nil)}
+
+	// Without chaos, a launched proxy is left alone
+	version := dc.configureChaos(newProxy)
+	require.Empty(t, launched.received())
+
+	// Chaos configured while the new proxy starts only reaches the proxy that is still current
+	config := chaos.Config{StoreBusyRate: 0.5}
+	require.NoError(t, dc.SetChaos(context.Background(), config))
+	require.Equal(t, []chaos.Config{config}, previous.received())
+	require.Empty(t, launched.received())
+
+	dc.current = newProxy
+	dc.reconfigureChaos(newProxy, version)
+	require.Equal(t, []chaos.Config{config}, launched.received())
+
+	// Once caught up, nothing is sent again
+	_, version = dc.chaosConfig()
+	dc.reconfigureChaos(newProxy, version)
+	require.Len(t, launched.received(), 1)
+}
@@ -13,146 +13,203 @@ import (
 )
 
 func (p *DataProxy) init() error {
-	legacyStore, err := store.NewNoteStore(store.DefaultStoreOptions(constants.LegacyNoteStore, p.logger))
+	legacyOptions := store.DefaultStoreOptions(constants.LegacyNoteStore, p.logger)
+	legacyOptions.Faults = p.chaos
+	legacyStore, err := store.NewNoteStore(legacyOptions)
 	if err != nil {
 		return fmt.Errorf("failed to create note store: %w", err)
 	}
 
 	p.legacyNoteStore = legacyStore
 
+	// Shards are opened upfront so accounts can be switched to dual-write at any time
+	p.shardNoteStores = make(map[string]store.NoteStore, len(constants.Shards))
+	for _, shard := range constants.Shards {
+		shardOptions := store.DefaultStoreOptions(shard, p.logger)
+		shardOptions.Faults = p.chaos
+		shardStore, err := store.NewNoteStore(shardOptions)
+		if err != nil {
+			return fmt.Errorf("failed to create note store for shard %q: %w", shard, err)
+		}
+		p.shardNoteStores[shard] = shardStore
+	}
+
 	return nil
 }
 
 // ListNotes lists notes with account details consideration
 func (p *DataProxy) ListNotes(ctx context.Context, accountDetails AccountDetails) ([]uuid.UUID, error) {
-	p.lockWithContentionTracking("ListNotes")
+	if err := p.lockWithContentionTracking(ctx, "ListNotes"); err != nil {
+		return nil, err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
+	storeID, noteStore := p.readStore(ctx, accountDetails)
 
+	storeCtx, span := startStoreSpan(ctx, "ListNotes", storeID)
 	start := time.Now()
-	result, err := p.legacyNoteStore.ListNotes(ctx, accountDetails.AccountID)
+	result, err := noteStore.ListNotes(storeCtx, accountDetails.AccountID)
+	span.SetError(err)
+	span.End()
 	status := telemetry.DataStoreAccessStatusSuccess
 	if err != nil {
 		status = telemetry.DataStoreAccessStatusError
 	}
 	// Track metrics, ignoring errors to avoid disrupting main operation
-	_ = p.statsCollector.TrackDataStoreAccess("ListNotes", time.Since(start), constants.LegacyNoteStore, status)
+	_ = p.statsCollector.TrackDataStoreAccess("ListNotes", time.Since(start), storeID, status)
+
+	if err == nil && accountDetails.MigrationState.ShadowReads() {
+		shadowRead(ctx, p, accountDetails, "ListNotes", func(ctx context.Context, noteStore store.NoteStore) ([]uuid.UUID, error) {
+			return noteStore.ListNotes(ctx, accountDetails.AccountID)
+		}, sameNoteIDs)
+	}
+
 	return result, err
 }
 
 // GetNote gets a note with account details consideration
 func (p *DataProxy) GetNote(ctx context.Context, accountDetails AccountDetails, noteID uuid.UUID) (*store.Note, error) {
-	p.lockWithContentionTracking("GetNote")
+	if err := p.lockWithContentionTracking(ctx, "GetNote"); err != nil {
+		return nil, err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
+	storeID, noteStore := p.readStore(ctx, accountDetails)
 
+	storeCtx, span := startStoreSpan(ctx, "GetNote", storeID)
 	start := time.Now()
-	result, err := p.legacyNoteStore.GetNote(ctx, accountDetails.AccountID, noteID)
+	result, err := noteStore.GetNote(storeCtx, accountDetails.AccountID, noteID)
+	span.SetError(err)
+	span.End()
 	status := telemetry.DataStoreAccessStatusSuccess
 	if err != nil {
 		status = telemetry.DataStoreAccessStatusError
 	}
 	// Track metrics, ignoring errors to avoid disrupting main operation
-	_ = p.statsCollector.TrackDataStoreAccess("GetNote", time.Since(start), constants.LegacyNoteStore, status)
+	_ = p.statsCollector.TrackDataStoreAccess("GetNote", time.Since(start), storeID, status)
+
+	if err == nil && accountDetails.MigrationState.ShadowReads() {
+		shadowRead(ctx, p, accountDetails, "GetNote", func(ctx context.Context, noteStore store.NoteStore) (*store.Note, error) {
+			return noteStore.GetNote(ctx, accountDetails.AccountID, noteID)
+		}, sameNote)
+	}
+
 	return result, err
 }
 
 // CreateNote creates a note with account details consideration
 func (p *DataProxy) CreateNote(ctx context.Context, accountDetails AccountDetails, note store.Note) error {
-	p.lockWithContentionTracking("CreateNote")
+	if err := p.lockWithContentionTracking(ctx, "CreateNote"); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
+	storeID, noteStore := p.writeStore(ctx, accountDetails)
 
+	storeCtx, span := startStoreSpan(ctx, "CreateNote", storeID)
 	start := time.Now()
-	err := p.legacyNoteStore.CreateNote(ctx, accountDetails.AccountID, note)
+	err := noteStore.CreateNote(storeCtx, accountDetails.AccountID, note)
+	span.SetError(err)
+	span.End()
 	status := telemetry.DataStoreAccessStatusSuccess
 	if err != nil {
 		status = telemetry.DataStoreAccessStatusError
 	}
 	// Track metrics, ignoring errors to avoid disrupting main operation
-	_ = p.statsCollector.TrackDataStoreAccess("CreateNote", time.Since(start), constants.LegacyNoteStore, status)
+	_ = p.statsCollector.TrackDataStoreAccess("CreateNote", time.Since(start), storeID, status)
+
+	if err == nil && mirrorsWrites(accountDetails) {
+		p.mirrorNote(ctx, "CreateNote", accountDetails, note.ID)
+	}
 
 	// Report new total count
-	totalCount, err := p.legacyNoteStore.GetTotalNotes(ctx)
+	totalCount, err := noteStore.GetTotalNotes(ctx)
 	if err != nil {
 		return fmt.Errorf("could not retrieve total note count: %w", err)
 	}
-	p.statsCollector.TrackNoteCount(constants.LegacyNoteStore, totalCount)
+	p.statsCollector.TrackNoteCount(storeID, totalCount)
 	return err
 }
 
 // UpdateNote updates a note with account details consideration
 func (p *DataProxy) UpdateNote(ctx context.Context, accountDetails AccountDetails, note store.Note) error {
-	p.lockWithContentionTracking("UpdateNote")
+	if err := p.lockWithContentionTracking(ctx, "UpdateNote"); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
+	storeID, noteStore := p.writeStore(ctx, accountDetails)
 
+	storeCtx, span := startStoreSpan(ctx, "UpdateNote", storeID)
 	start := time.Now()
-	err := p.legacyNoteStore.UpdateNote(ctx, accountDetails.AccountID, note)
+	err := noteStore.UpdateNote(storeCtx, accountDetails.AccountID, note)
+	span.SetError(err)
+	span.End()
 	status := telemetry.DataStoreAccessStatusSuccess
 	if err != nil {
 		status = telemetry.DataStoreAccessStatusError
 	}
 	// Track metrics, ignoring errors to avoid disrupting main operation
-	_ = p.statsCollector.TrackDataStoreAccess("UpdateNote", time.Since(start), constants.LegacyNoteStore, status)
+	_ = p.statsCollector.TrackDataStoreAccess("UpdateNote", time.Since(start), storeID, status)
+
+	if err == nil && mirrorsWrites(accountDetails) {
+		p.mirrorNote(ctx, "UpdateNote", accountDetails, note.ID)
+	}
+
 	return err
 }
 
 // DeleteNote deletes a note with account details consideration
 func (p *DataProxy) DeleteNote(ctx context.Context, accountDetails AccountDetails, note store.Note) error {
-	p.lockWithContentionTracking("DeleteNote")
+	if err := p.lockWithContentionTracking(ctx, "DeleteNote"); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
+	storeID, noteStore := p.writeStore(ctx, accountDetails)
 
+	storeCtx, span := startStoreSpan(ctx, "DeleteNote", storeID)
 	start := time.Now()
-	err := p.legacyNoteStore.DeleteNote(ctx, accountDetails.AccountID, note)
+	err := noteStore.DeleteNote(storeCtx, accountDetails.AccountID, note)
+	span.SetError(err)
+	span.End()
 	status := telemetry.DataStoreAccessStatusSuccess
 	if err != nil {
 		status = telemetry.DataStoreAccessStatusError
 	}
 	// Track metrics, ignoring errors to avoid disrupting main operation
-	_ = p.statsCollector.TrackDataStoreAccess("DeleteNote", time.Since(start), constants.LegacyNoteStore, status)
+	_ = p.statsCollector.TrackDataStoreAccess("DeleteNote", time.Since(start), storeID, status)
+
+	if err == nil && mirrorsWrites(accountDetails) {
+		p.mirrorNote(ctx, "DeleteNote", accountDetails, note.ID)
+	}
 
 	// Report new total count
-	totalCount, err := p.legacyNoteStore.GetTotalNotes(ctx)
+	totalCount, err := noteStore.GetTotalNotes(ctx)
 	if err != nil {
 		return fmt.Errorf("could not retrieve total note count: %w", err)
 	}
-	p.statsCollector.TrackNoteCount(constants.LegacyNoteStore, totalCount)
+	p.statsCollector.TrackNoteCount(storeID, totalCount)
 
 	return err
 }
 
 // CountNotes counts notes with account details consideration
 func (p *DataProxy) CountNotes(ctx context.Context, accountDetails AccountDetails) (int, error) {
-	p.lockWithContentionTracking("CountNotes")
+	if err := p.lockWithContentionTracking(ctx, "CountNotes"); err != nil {
+		return 0, err
+	}
 	defer p.mu.Unlock()
 
-	// TODO: Use accountDetails to conditionally run migration logic and shard routing
-	// For now, this is a placeholder that maintains existing behavior
-	_ = accountDetails
-
-	return p.legacyNoteStore.CountNotes(ctx, accountDetails.AccountID)
+	_, noteStore := p.readStore(ctx, accountDetails)
+	return noteStore.CountNotes(ctx, accountDetails.AccountID)
 }
 
 // GetTotalNotes implements NoteStore interface with locking
 func (p *DataProxy) GetTotalNotes(ctx context.Context) (int, error) {
-	p.lockWithContentionTracking("GetTotalNotes")
+	if err := p.lockWithContentionTracking(ctx, "GetTotalNotes"); err != nil {
+		return 0, err
+	}
 	defer p.mu.Unlock()
 
 	totalCount, err := p.legacyNoteStore.GetTotalNotes(ctx)
@@ -165,9 +222,105 @@ func (p *DataProxy) GetTotalNotes(ctx context.Context) (int, error) {
 	return totalCount, nil
 }
 
+// ListChanges returns the next batch of note changes for an account after the given cursor.
+// Changes of all note stores are merged in the order they happened.
+func (p *DataProxy) ListChanges(ctx context.Context, accountDetails AccountDetails, cursor string, limit int) (*ChangeBatch, error) {
+	if err := p.lockWithContentionTracking(ctx, "ListChanges"); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	position, err := store.ParseChangeCursor(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if limit <= 0 {
+		limit = constants.ChangeStreamBatchSize
+	}
+
+	// Changes of each store, in the order of their sequence numbers
+	storeChanges := make(map[string][]store.NoteChange)
+	for storeID, noteStore := range p.noteStores() {
+		feed, ok := noteStore.(store.ChangeFeed)
+		if !ok {
+			continue
+		}
+
+		storeCtx, span := startStoreSpan(ctx, "ListChanges", storeID)
+		start := time.Now()
+		changes, err := feed.ListChanges(storeCtx, accountDetails.AccountID, position[storeID], limit)
+		span.SetError(err)
+		span.End()
+		status := telemetry.DataStoreAccessStatusSuccess
+		if err != nil {
+			status = telemetry.DataStoreAccessStatusError
+		}
+		// Track metrics, ignoring errors to avoid disrupting main operation
+		_ = p.statsCollector.TrackDataStoreAccess("ListChanges", time.Since(start), storeID, status)
+		if err != nil {
+			return nil, fmt.Errorf("could not list changes of store %q: %w", storeID, err)
+		}
+
+		for i := range changes {
+			changes[i].StoreID = storeID
+		}
+		if len(changes) > 0 {
+			storeChanges[storeID] = changes
+		}
+	}
+
+	// Merge the stores by repeatedly taking the earliest of their next changes. Timestamps are taken before
+	// a write waits for the database lock, so they may be out of order within a store. Taking changes of a store
+	// in sequence order ensures the batch holds a contiguous run of each store and the cursor skips no change.
+	var changes []store.NoteChange
+	next := position.Clone()
+	for len(changes) < limit && len(storeChanges) > 0 {
+		var earliest string
+		for storeID, pending := range storeChanges {
+			if earliest == "" || changedBefore(pending[0], storeChanges[earliest][0]) {
+				earliest = storeID
+			}
+		}
+
+		change := storeChanges[earliest][0]
+		changes = append(changes, change)
+		next[earliest] = change.Seq
+
+		if len(storeChanges[earliest]) == 1 {
+			delete(storeChanges, earliest)
+		} else {
+			storeChanges[earliest] = storeChanges[earliest][1:]
+		}
+	}
+
+	return &ChangeBatch{Changes: changes, Cursor: next.String()}, nil
+}
+
+// changedBefore orders changes of different stores by when they happened, breaking ties by store
+func changedBefore(a, b store.NoteChange) bool {
+	if !a.ChangedAt.Equal(b.ChangedAt) {
+		return a.ChangedAt.Before(b.ChangedAt)
+	}
+	return a.StoreID < b.StoreID
+}
+
+// noteStores returns all note stores managed by the proxy, keyed by store ID
+func (p *DataProxy) noteStores() map[string]store.NoteStore {
+	noteStores := map[string]store.NoteStore{
+		constants.LegacyNoteStore: p.legacyNoteStore,
+	}
+	for shard, shardStore := range p.shardNoteStores {
+		noteStores[shard] = shardStore
+	}
+	return noteStores
+}
+
 // HealthCheck implements NoteStore interface with locking
 func (p *DataProxy) HealthCheck(ctx context.Context) error {
-	p.lockWithContentionTracking("HealthCheck")
+	if err := p.lockWithContentionTracking(ctx, "HealthCheck"); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 	return p.legacyNoteStore.HealthCheck(ctx)
 }
@@ -177,10 +330,34 @@ func (p *DataProxy) Ready(ctx context.Context) error {
 	return p.HealthCheck(ctx)
 }
 
-// lockWithContentionTracking attempts to acquire the lock
-func (p *DataProxy) lockWithContentionTracking(operation string) {
+// lockWithContentionTracking attempts to acquire the lock until the context is done
+func (p *DataProxy) lockWithContentionTracking(ctx context.Context, operation string) error {
+	_, span := telemetry.StartSpan(ctx, "lock wait", telemetry.SpanKindInternal)
+	defer span.End()
+
+	attempts := 1
+	defer func() { span.SetAttribute("lock.attempts", attempts) }()
+
 	for !p.mu.TryLock() {
 		_ = p.statsCollector.TrackProxyAccess(operation, 0, p.proxyID, telemetry.ProxyAccessStatusContention)
-		time.Sleep(5 * time.Millisecond)
+
+		timer := time.NewTimer(5 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			span.SetError(ctx.Err())
+			return ctx.Err()
+		case <-timer.C:
+		}
+		attempts++
 	}
+	return nil
+}
+
+// startStoreSpan traces a single call to a note store
+func startStoreSpan(ctx context.Context, operation, storeID string) (context.Context, *telemetry.Span) {
+	ctx, span := telemetry.StartSpan(ctx, "sqlite "+operation, telemetry.SpanKindClient)
+	span.SetAttribute("db.system", "sqlite")
+	span.SetAttribute("db.store", storeID)
+	return ctx, span
 }
@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// changeFeedStore is a note store that only serves a fixed change outbox
+type changeFeedStore struct {
+	store.NoteStore
+	changes []store.NoteChange
+}
+
+func (s *changeFeedStore) ListChanges(ctx context.Context, accountID uuid.UUID, afterSeq int64, limit int) ([]store.NoteChange, error) {
+	var changes []store.NoteChange
+	for _, change := range s.changes {
+		if change.Seq > afterSeq && len(changes) < limit {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func TestListChangesKeepsStoreOrder(t *testing.T) {
+	statsCollector := telemetry.NewStatsCollector()
+	defer statsCollector.Stop()
+
+	base := time.UnixMilli(1_000_000)
+	at := func(offset int) time.Time { return base.Add(time.Duration(offset) * time.Millisecond) }
+
+	// The second legacy change was timestamped before the first one, as if it waited less for the lock
+	p := &DataProxy{
+		proxyID: 1,
+		legacyNoteStore: &changeFeedStore{changes: []store.NoteChange{
+			{Seq: 1, ChangedAt: at(20)},
+			{Seq: 2, ChangedAt: at(10)},
+		}},
+		shardNoteStores: map[string]store.NoteStore{
+			"shard1": &changeFeedStore{changes: []store.NoteChange{
+				{Seq: 1, ChangedAt: at(15)},
+			}},
+		},
+		statsCollector: statsCollector,
+	}
+
+	var delivered []string
+	cursor := ""
+	for range 3 {
+		batch, err := p.ListChanges(context.Background(), AccountDetails{AccountID: uuid.New()}, cursor, 2)
+		require.NoError(t, err)
+		for _, change := range batch.Changes {
+			delivered = append(delivered, store.ChangeCursor{change.StoreID: change.Seq}.String())
+		}
+		cursor = batch.Cursor
+	}
+
+	// Every change is delivered once, and each store's changes in sequence order
+	require.Equal(t, []string{"shard1:1", constants.LegacyNoteStore + ":1", constants.LegacyNoteStore + ":2"}, delivered)
+}
+
+func TestLockWithContentionTrackingGivesUp(t *testing.T) {
+	statsCollector := telemetry.NewStatsCollector()
+	defer statsCollector.Stop()
+
+	p := &DataProxy{proxyID: 1, statsCollector: statsCollector}
+	p.mu.Lock()
+
+	// A caller whose context is done stops waiting for the lock instead of holding on to it later
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, p.lockWithContentionTracking(ctx, "ShadowRead"), context.DeadlineExceeded)
+
+	p.mu.Unlock()
+	require.NoError(t, p.lockWithContentionTracking(context.Background(), "ShadowRead"))
+	p.mu.Unlock()
+}
@@ -0,0 +1,341 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+// readStore returns the note store reads of an account are served from
+func (p *DataProxy) readStore(ctx context.Context, accountDetails AccountDetails) (string, store.NoteStore) {
+	if accountDetails.MigrationState.ReadsShard() {
+		return p.shardStore(ctx, accountDetails)
+	}
+	return constants.LegacyNoteStore, p.legacyNoteStore
+}
+
+// writeStore returns the note store writes of an account go to first.
+// While both legacy and the shard are written, writes are mirrored to the shard afterwards.
+func (p *DataProxy) writeStore(ctx context.Context, accountDetails AccountDetails) (string, store.NoteStore) {
+	if !accountDetails.MigrationState.WritesLegacy() {
+		return p.shardStore(ctx, accountDetails)
+	}
+	return constants.LegacyNoteStore, p.legacyNoteStore
+}
+
+// mirrorsWrites reports whether writes to legacy must be mirrored to the shard
+func mirrorsWrites(accountDetails AccountDetails) bool {
+	return accountDetails.MigrationState.WritesLegacy() && accountDetails.MigrationState.WritesShard()
+}
+
+// shardStore returns the target shard of an account, falling back to legacy for unknown shards
+func (p *DataProxy) shardStore(ctx context.Context, accountDetails AccountDetails) (string, store.NoteStore) {
+	shard := accountDetails.TargetShard()
+	if shardStore, ok := p.shardNoteStores[shard]; ok {
+		return shard, shardStore
+	}
+	p.logger.WarnContext(ctx, "Unknown shard, falling back to legacy", "accountID", accountDetails.AccountID, "shard", shard)
+	return constants.LegacyNoteStore, p.legacyNoteStore
+}
+
+// ApplyMigrationState runs the work required when an account enters a migration state:
+// backfilling copies existing legacy notes to the shard, cleanup removes them from legacy,
+// and rolling back removes the notes copied to the shard.
+func (p *DataProxy) ApplyMigrationState(ctx context.Context, accountDetails AccountDetails) error {
+	if err := p.lockWithContentionTracking(ctx, "ApplyMigrationState"); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	shard, shardStore := p.shardStore(ctx, accountDetails)
+	if shard == constants.LegacyNoteStore {
+		return fmt.Errorf("unknown shard %q", accountDetails.TargetShard())
+	}
+
+	var operation, storeID string
+	var run func() (int, error)
+
+	switch accountDetails.MigrationState {
+	case store.MigrationStateBackfilling:
+		operation, storeID = "Backfill", shard
+		run = func() (int, error) {
+			noteIDs, err := p.legacyNoteStore.ListNotes(ctx, accountDetails.AccountID)
+			if err != nil {
+				return 0, err
+			}
+			for i, noteID := range noteIDs {
+				if err := p.copyNote(ctx, shardStore, accountDetails.AccountID, noteID); err != nil {
+					return i, fmt.Errorf("could not copy note %s: %w", noteID, err)
+				}
+			}
+			return len(noteIDs), nil
+		}
+
+	case store.MigrationStateCleanup:
+		operation, storeID = "Cleanup", constants.LegacyNoteStore
+		run = func() (int, error) {
+			return deleteAccountNotes(ctx, p.legacyNoteStore, accountDetails.AccountID)
+		}
+
+	case store.MigrationStateRollingBack:
+		operation, storeID = "Rollback", shard
+		run = func() (int, error) {
+			return deleteAccountNotes(ctx, shardStore, accountDetails.AccountID)
+		}
+
+	default:
+		return nil
+	}
+
+	start := time.Now()
+	count, err := run()
+	status := telemetry.DataStoreAccessStatusSuccess
+	if err != nil {
+		status = telemetry.DataStoreAccessStatusError
+	}
+	// Track metrics, ignoring errors to avoid disrupting main operation
+	_ = p.statsCollector.TrackDataStoreAccess(operation, time.Since(start), storeID, status)
+
+	if err != nil {
+		return fmt.Errorf("%s failed after %d notes: %w", strings.ToLower(operation), count, err)
+	}
+
+	p.logger.InfoContext(ctx, "Applied migration state",
+		"accountID", accountDetails.AccountID,
+		"state", accountDetails.MigrationState,
+		"shard", shard,
+		"notes", count,
+	)
+
+	// Report new total counts of both affected stores
+	for affectedStoreID, noteStore := range map[string]store.NoteStore{constants.LegacyNoteStore: p.legacyNoteStore, shard: shardStore} {
+		if totalCount, err := noteStore.GetTotalNotes(ctx); err == nil {
+			_ = p.statsCollector.TrackNoteCount(affectedStoreID, totalCount)
+		}
+	}
+
+	return nil
+}
+
+// MigrationProgress is how many notes of an account are stored in legacy and in its shard
+type MigrationProgress struct {
+	AccountID      uuid.UUID            `json:"accountId"`
+	MigrationState store.MigrationState `json:"migrationState"`
+	Shard          string               `json:"shard,omitempty"` // Empty until a shard is assigned
+	LegacyNotes    int                  `json:"legacyNotes"`
+	ShardNotes     int                  `json:"shardNotes"`
+}
+
+// MigrationProgress counts the notes of an account in legacy and in its shard, if it has one
+func (p *DataProxy) MigrationProgress(ctx context.Context, accountDetails AccountDetails) (*MigrationProgress, error) {
+	if err := p.lockWithContentionTracking(ctx, "MigrationProgress"); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	progress := &MigrationProgress{
+		AccountID:      accountDetails.AccountID,
+		MigrationState: accountDetails.MigrationState.Normalize(),
+	}
+
+	legacyNotes, err := p.legacyNoteStore.CountNotes(ctx, accountDetails.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("could not count legacy notes: %w", err)
+	}
+	progress.LegacyNotes = legacyNotes
+
+	if accountDetails.Shard == nil || *accountDetails.Shard == "" {
+		return progress, nil
+	}
+
+	shard, shardStore := p.shardStore(ctx, accountDetails)
+	if shard == constants.LegacyNoteStore {
+		return nil, fmt.Errorf("unknown shard %q", accountDetails.TargetShard())
+	}
+	shardNotes, err := shardStore.CountNotes(ctx, accountDetails.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("could not count notes in shard %s: %w", shard, err)
+	}
+	progress.Shard = shard
+	progress.ShardNotes = shardNotes
+
+	return progress, nil
+}
+
+// deleteAccountNotes removes all notes of an account from a note store
+func deleteAccountNotes(ctx context.Context, noteStore store.NoteStore, accountID uuid.UUID) (int, error) {
+	noteIDs, err := noteStore.ListNotes(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, noteID := range noteIDs {
+		if err := noteStore.DeleteNote(ctx, accountID, store.Note{ID: noteID, Creator: accountID}); err != nil {
+			return i, fmt.Errorf("could not delete note %s: %w", noteID, err)
+		}
+	}
+
+	return len(noteIDs), nil
+}
+
+// mirrorNote copies the current legacy state of a note to the target shard of a dual-writing account.
+// Legacy remains the source of truth, so failures are logged and tracked but do not fail the request.
+func (p *DataProxy) mirrorNote(ctx context.Context, operation string, accountDetails AccountDetails, noteID uuid.UUID) {
+	shard := accountDetails.TargetShard()
+	shardStore, ok := p.shardNoteStores[shard]
+	if !ok {
+		p.logger.WarnContext(ctx, "Unknown shard, skipping dual-write", "accountID", accountDetails.AccountID, "shard", shard)
+		return
+	}
+
+	storeCtx, span := startStoreSpan(ctx, operation, shard)
+	span.SetAttribute("migration.dual_write", true)
+	start := time.Now()
+	err := p.copyNote(storeCtx, shardStore, accountDetails.AccountID, noteID)
+	span.SetError(err)
+	span.End()
+	status := telemetry.DataStoreAccessStatusSuccess
+	if err != nil {
+		status = telemetry.DataStoreAccessStatusError
+	}
+	// Track metrics, ignoring errors to avoid disrupting main operation
+	_ = p.statsCollector.TrackDataStoreAccess(operation, time.Since(start), shard, status)
+
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Dual-write to shard failed",
+			"operation", operation,
+			"accountID", accountDetails.AccountID,
+			"noteID", noteID,
+			"shard", shard,
+			"error", err,
+		)
+		return
+	}
+
+	if totalCount, err := shardStore.GetTotalNotes(ctx); err == nil {
+		_ = p.statsCollector.TrackNoteCount(shard, totalCount)
+	}
+}
+
+// copyNote makes the shard match the legacy state of a note, creating, updating or deleting it as needed
+func (p *DataProxy) copyNote(ctx context.Context, shardStore store.NoteStore, accountID, noteID uuid.UUID) error {
+	legacyNote, err := p.legacyNoteStore.GetNote(ctx, accountID, noteID)
+	if err != nil {
+		return err
+	}
+
+	if legacyNote == nil {
+		return shardStore.DeleteNote(ctx, accountID, store.Note{ID: noteID, Creator: accountID})
+	}
+
+	shardNote, err := shardStore.GetNote(ctx, accountID, noteID)
+	if err != nil {
+		return err
+	}
+
+	if shardNote == nil {
+		return shardStore.CreateNote(ctx, accountID, *legacyNote)
+	}
+
+	return shardStore.UpdateNote(ctx, accountID, *legacyNote)
+}
+
+// shadowRead runs a read against both legacy and the target shard in the background and compares the results.
+// Both stores are read while holding the proxy lock, so writes through this proxy cannot cause false mismatches.
+// While a rollout routes the account to two proxies, the other proxy may write between both reads and cause one.
+// At most MaxShadowReads run at once, reads arriving while all are busy are not shadowed to bound the added lock contention.
+// Only the request ID of the calling request is kept, as the read outlives it.
+func shadowRead[T any](requestCtx context.Context, p *DataProxy, accountDetails AccountDetails, operation string, read func(ctx context.Context, noteStore store.NoteStore) (T, error), equal func(legacy, shard T) bool) {
+	shard := accountDetails.TargetShard()
+	shardStore, ok := p.shardNoteStores[shard]
+	if !ok {
+		return
+	}
+
+	select {
+	case p.shadowReads <- struct{}{}:
+	default:
+		return
+	}
+
+	go func() {
+		defer func() { <-p.shadowReads }()
+
+		ctx := telemetry.WithRequestID(context.Background(), telemetry.RequestIDFromContext(requestCtx))
+		ctx, cancel := context.WithTimeout(ctx, constants.ShadowReadTimeout)
+		defer cancel()
+
+		// Give up on the comparison rather than hold a shadow read slot past the timeout
+		if err := p.lockWithContentionTracking(ctx, "ShadowRead"); err != nil {
+			p.logger.DebugContext(ctx, "Skipped shadow read waiting for the lock", "operation", operation, "accountID", accountDetails.AccountID, "error", err)
+			return
+		}
+		defer p.mu.Unlock()
+
+		legacyResult, err := read(ctx, p.legacyNoteStore)
+		if err != nil {
+			p.logger.ErrorContext(ctx, "Shadow read from legacy failed", "operation", operation, "accountID", accountDetails.AccountID, "error", err)
+			return
+		}
+
+		start := time.Now()
+		shardResult, err := read(ctx, shardStore)
+		status := telemetry.DataStoreAccessStatusSuccess
+		if err != nil {
+			status = telemetry.DataStoreAccessStatusError
+		}
+		// Track metrics, ignoring errors to avoid disrupting main operation
+		_ = p.statsCollector.TrackDataStoreAccess("Shadow"+operation, time.Since(start), shard, status)
+		if err != nil {
+			p.logger.ErrorContext(ctx, "Shadow read from shard failed", "operation", operation, "accountID", accountDetails.AccountID, "shard", shard, "error", err)
+			return
+		}
+
+		match := equal(legacyResult, shardResult)
+		_ = p.statsCollector.TrackShadowRead(accountDetails.AccountID.String(), operation, match)
+
+		if !match {
+			p.logger.WarnContext(ctx, "Shadow read mismatch",
+				"operation", operation,
+				"accountID", accountDetails.AccountID,
+				"shard", shard,
+				"legacy", legacyResult,
+				"shardValue", shardResult,
+			)
+		}
+	}()
+}
+
+// sameNoteIDs compares note listings regardless of order
+func sameNoteIDs(legacy, shard []uuid.UUID) bool {
+	if len(legacy) != len(shard) {
+		return false
+	}
+
+	sortedLegacy := slices.Clone(legacy)
+	sortedShard := slices.Clone(shard)
+	compare := func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) }
+	slices.SortFunc(sortedLegacy, compare)
+	slices.SortFunc(sortedShard, compare)
+
+	return slices.Equal(sortedLegacy, sortedShard)
+}
+
+// sameNote compares the stored state of a note, treating two missing notes as equal
+func sameNote(legacy, shard *store.Note) bool {
+	if legacy == nil || shard == nil {
+		return legacy == nil && shard == nil
+	}
+
+	return legacy.Content == shard.Content &&
+		legacy.CreatedAt.Equal(shard.CreatedAt) &&
+		legacy.UpdatedAt.Equal(shard.UpdatedAt)
+}
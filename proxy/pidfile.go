@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// pidDir returns the directory data proxies register their process IDs in
+func pidDir(basePath string) string {
+	return filepath.Join(basePath, ".data", "proxies")
+}
+
+// writePIDFile registers the current process as a running data proxy.
+// The file stays locked while the process runs, so a process reusing the ID after the proxy exited is never mistaken for it.
+// The returned function removes the registration again.
+func writePIDFile(basePath string, proxyID int) (func(), error) {
+	dir := pidDir(basePath)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("could not create pid directory: %w", err)
+	}
+
+	// Files are keyed by process ID so a restarted proxy never removes its successor's file
+	path := filepath.Join(dir, fmt.Sprintf("%d.pid", os.Getpid()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("could not write pid file: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not lock pid file: %w", err)
+	}
+	if _, err := file.WriteString(strconv.Itoa(proxyID)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not write pid file: %w", err)
+	}
+
+	return func() {
+		_ = os.Remove(path)
+		_ = file.Close()
+	}, nil
+}
+
+// RunningProxies returns the process IDs of data proxies that are still alive.
+// The operating system releases the lock of a proxy's file when it exits, so files left behind
+// by proxies that did not shut down cleanly are ignored, even if their process ID was reused.
+func RunningProxies(basePath string) ([]int, error) {
+	entries, err := os.ReadDir(pidDir(basePath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read pid directory: %w", err)
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".pid")
+		if !ok {
+			continue
+		}
+
+		pid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+
+		locked, err := pidFileLocked(filepath.Join(pidDir(basePath), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids, nil
+}
+
+// pidFileLocked reports whether a running proxy holds the lock of its pid file
+func pidFileLocked(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not open pid file: %w", err)
+	}
+	defer file.Close()
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not check lock of pid file: %w", err)
+	}
+	return false, syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
 	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
 )
@@ -23,8 +24,12 @@ type DataProxyProcess struct {
 	ProxyClient  *ProxyClient
 	LaunchedAt   time.Time
 	RestartCount int
-	Port         int    // Store port for restart purposes
-	binaryPath   string // Path to the built binary for restarts (private)
+	Port         int            // Store port for restart purposes
+	binaryPath   string         // Path to the built binary for restarts (private)
+	traceFile    string         // File the proxy exports spans to
+	seed         int64          // Seed of the run, passed on to the proxy
+	paused       bool           // Stopped by a chaos experiment, guarded by the deployment controller
+	network      *chaos.Network // Partitions requests to the proxy
 }
 
 // freePort returns a free port on the system
@@ -75,6 +80,8 @@ func (dpp *DataProxyProcess) start(statsCollector telemetry.StatsCollector) erro
 		"--proxy",
 		"--proxy-port", fmt.Sprintf("%d", dpp.Port),
 		"--proxy-id", fmt.Sprintf("%d", dpp.ID),
+		"--trace-file", dpp.traceFile,
+		"--seed", fmt.Sprintf("%d", dpp.seed),
 	)
 	
 	// Get current working directory for process context
@@ -84,9 +91,10 @@ func (dpp *DataProxyProcess) start(statsCollector telemetry.StatsCollector) erro
 	}
 	cmd.Dir = workDir
 
-	// Pipe stdout and stderr to log capture
-	cmd.Stdout = dpp.LogCapture
-	cmd.Stderr = dpp.LogCapture
+	// Pipe stdout and stderr to log capture, decoding the JSON logs of the proxy
+	output := dpp.LogCapture.SourceWriter(telemetry.ProxyLogSource(dpp.ID))
+	cmd.Stdout = output
+	cmd.Stderr = output
 
 	// Start the process
 	if err := cmd.Start(); err != nil {
@@ -98,7 +106,7 @@ func (dpp *DataProxyProcess) start(statsCollector telemetry.StatsCollector) erro
 	dpp.LaunchedAt = time.Now()
 	
 	baseURL := fmt.Sprintf("http://localhost:%d", dpp.Port)
-	dpp.ProxyClient = NewProxyClient(dpp.ID, baseURL, statsCollector)
+	dpp.ProxyClient = NewProxyClient(dpp.ID, baseURL, statsCollector, dpp.seed, dpp.network)
 
 	// Wait for proxy to be ready using shared health check
 	ready := false
@@ -119,8 +127,10 @@ func (dpp *DataProxyProcess) start(statsCollector telemetry.StatsCollector) erro
 	return nil
 }
 
-// LaunchDataProxy starts a child process running a data proxy
-func LaunchDataProxy(id int, statsCollector telemetry.StatsCollector, logCapture *telemetry.LogCapture) (*DataProxyProcess, error) {
+// LaunchDataProxy starts a child process running a data proxy.
+// The proxy appends its spans to traceFile, an empty path disables exporting them.
+// Simulated network delays on both ends are drawn from seed, requests to the proxy are partitioned by network.
+func LaunchDataProxy(id int, statsCollector telemetry.StatsCollector, logCapture *telemetry.LogCapture, traceFile string, seed int64, network *chaos.Network) (*DataProxyProcess, error) {
 	// Get a free port for the proxy
 	port, err := freePort()
 	if err != nil {
@@ -140,6 +150,9 @@ func LaunchDataProxy(id int, statsCollector telemetry.StatsCollector, logCapture
 		RestartCount: 0,
 		Port:         port,
 		binaryPath:   binaryPath,
+		traceFile:    traceFile,
+		seed:         seed,
+		network:      network,
 	}
 
 	// Start the proxy process and wait for readiness
@@ -206,7 +219,9 @@ func (dpp *DataProxyProcess) healthCheck(timeout time.Duration) bool {
 	return dpp.ProxyClient.Ready(ctx) == nil
 }
 
-// IsRunning checks if the process is still running using HTTP health check
+// IsRunning checks if the process is still running using HTTP health check. A process that does not respond is
+// killed, so it does not linger next to a restarted one. Paused proxies fail the health check while alive, callers
+// must skip them, as the process monitor does.
 func (dpp *DataProxyProcess) IsRunning() bool {
 	if dpp.Process == nil {
 		return false
@@ -214,10 +229,25 @@ func (dpp *DataProxyProcess) IsRunning() bool {
 	
 	// Use a short timeout for crash detection (500ms)
 	if !dpp.healthCheck(500 * time.Millisecond) {
-		// Process is not responding or dead, clear the reference
+		// Process is not responding or dead, kill and reap it before clearing the reference
+		_ = dpp.kill()
 		dpp.Process = nil
 		return false
 	}
 	
 	return true
 }
+
+// kill sends SIGKILL to the process and reaps it in the background, as nothing else waits for it anymore
+func (dpp *DataProxyProcess) kill() error {
+	process := dpp.Process
+	if process == nil {
+		return nil
+	}
+	if err := process.Signal(syscall.SIGKILL); err != nil {
+		return err
+	}
+	dpp.Process = nil
+	go process.Wait()
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
+	"github.com/brunoscheufler/gopherconuk25/util"
 )
 
 // DataProxy implements the NoteStore interface with synchronization
@@ -17,15 +20,27 @@ type DataProxy struct {
 	port    int
 
 	legacyNoteStore store.NoteStore
+	shardNoteStores map[string]store.NoteStore
 
 	statsCollector telemetry.StatsCollector
+	tracer         *telemetry.Tracer
 	mu             sync.Mutex
 	server         *http.Server
 	logger         *slog.Logger
+	rng            *util.Rand      // Draws the simulated network delay
+	chaos          *chaos.Injector // Injects the faults configured by the deployment controller
+	shadowReads    chan struct{}   // Slots of the shadow reads running in the background
 }
 
-// NewDataProxy creates a new DataProxy instance with a SQLite note store
-func NewDataProxy(id int, port int, logger *slog.Logger) (*DataProxy, error) {
+// ChangeBatch is a page of note changes together with the cursor to resume from
+type ChangeBatch struct {
+	Changes []store.NoteChange `json:"changes"`
+	Cursor  string             `json:"cursor"`
+}
+
+// NewDataProxy creates a new DataProxy instance with a SQLite note store.
+// Spans of traced calls are exported using the given tracer, simulated network delays are drawn from the seed.
+func NewDataProxy(id int, port int, logger *slog.Logger, tracer *telemetry.Tracer, seed int64) (*DataProxy, error) {
 	// Create a local stats collector for data store tracking
 	statsCollector := telemetry.NewStatsCollector()
 
@@ -33,7 +48,11 @@ func NewDataProxy(id int, port int, logger *slog.Logger) (*DataProxy, error) {
 		proxyID:        id,
 		port:           port,
 		statsCollector: statsCollector,
+		tracer:         tracer,
 		logger:         logger,
+		rng:            util.NewRand(seed, fmt.Sprintf("proxy %d", id)),
+		chaos:          chaos.NewInjector(id, statsCollector, util.NewRand(seed, fmt.Sprintf("chaos %d", id))),
+		shadowReads:    make(chan struct{}, constants.MaxShadowReads),
 	}
 
 	err := p.init()
@@ -44,7 +63,13 @@ func NewDataProxy(id int, port int, logger *slog.Logger) (*DataProxy, error) {
 	return p, nil
 }
 
-// Run starts the data proxy server
+// Run starts the data proxy server and registers it as running until it stops
 func (p *DataProxy) Run(ctx context.Context) error {
+	removePIDFile, err := writePIDFile("", p.proxyID)
+	if err != nil {
+		return err
+	}
+	defer removePIDFile()
+
 	return p.startServer(ctx)
 }
@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ServedBy records which data proxy served the note store calls made with a context.
+// With two proxies running during a rollout, this tells which version answered a request.
+type ServedBy struct {
+	proxyID atomic.Int64
+}
+
+type servedByContextKey struct{}
+
+// WithServedBy returns a context that records the proxy selected for note store calls made with it
+func WithServedBy(ctx context.Context) (context.Context, *ServedBy) {
+	servedBy := &ServedBy{}
+	return context.WithValue(ctx, servedByContextKey{}, servedBy), servedBy
+}
+
+// ProxyID returns the ID of the proxy that served the latest call, or zero if no proxy was selected
+func (s *ServedBy) ProxyID() int {
+	return int(s.proxyID.Load())
+}
+
+// recordServedBy stores the selected proxy in the context's ServedBy, if any
+func recordServedBy(ctx context.Context, proxy *DataProxyProcess) {
+	servedBy, ok := ctx.Value(servedByContextKey{}).(*ServedBy)
+	if !ok || proxy == nil {
+		return
+	}
+	servedBy.proxyID.Store(int64(proxy.ID))
+}
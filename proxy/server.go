@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
 	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
 )
 
 // JSONRPCRequest represents a JSON RPC request
@@ -28,10 +31,19 @@ type JSONRPCResponse struct {
 	ID     int         `json:"id"`
 }
 
+// controlMethods are never faulted, so crash detection, stats collection and chaos configuration keep working
+var controlMethods = map[string]bool{
+	"HealthCheck":      true,
+	"Ready":            true,
+	"ExportShardStats": true,
+	"SetChaos":         true,
+}
+
 // startServer starts the HTTP server and handles JSON RPC requests
 func (p *DataProxy) startServer(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", p.handleJSONRPC)
+	mux.Handle("GET /metrics", telemetry.PrometheusHandler(p.statsCollector))
 
 	p.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", p.port),
@@ -64,19 +76,48 @@ func (p *DataProxy) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Logs of the call carry the ID of the API request it was made for
+	ctx := r.Context()
+	if requestID := r.Header.Get(telemetry.RequestIDHeader); telemetry.ValidRequestID(requestID) {
+		ctx = telemetry.WithRequestID(ctx, requestID)
+	}
+
+	// Only calls made on behalf of a traced request are traced, background calls like stats exports are not
+	var span *telemetry.Span
+	if remote, ok := telemetry.ExtractTraceparent(r.Header); ok {
+		ctx, span = p.tracer.Start(ctx, "JSON-RPC", telemetry.SpanKindServer, remote)
+		span.SetAttribute("proxy.id", p.proxyID)
+		defer span.End()
+	}
+
 	// Simulate network delay between 1-5ms
-	delay := time.Duration(rand.Intn(constants.MaxNetworkDelayMs)+1) * time.Millisecond
+	_, delaySpan := telemetry.StartSpan(ctx, "network delay", telemetry.SpanKindInternal)
+	delay := time.Duration(p.rng.IntN(constants.MaxNetworkDelayMs)+1) * time.Millisecond
 	time.Sleep(delay)
+	delaySpan.End()
 
 	var req JSONRPCRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorMsg := fmt.Sprintf("Invalid JSON: %v", err)
+		span.SetError(err)
 		p.sendError(w, req.ID, errorMsg)
 		return
 	}
 
-	result, err := p.handleMethod(r.Context(), req.Method, req.Params)
+	span.SetName(req.Method)
+	span.SetAttribute("rpc.method", req.Method)
+
+	if !controlMethods[req.Method] {
+		if err := p.injectFaults(ctx, span, req.Method); err != nil {
+			p.sendError(w, req.ID, err.Error())
+			return
+		}
+	}
+
+	result, err := p.handleMethod(ctx, req.Method, req.Params)
 	if err != nil {
+		span.SetError(err)
+		p.logger.DebugContext(ctx, "JSON-RPC call failed", "method", req.Method, "error", err)
 		errorMsg := err.Error()
 		p.sendError(w, req.ID, errorMsg)
 		return
@@ -91,6 +132,58 @@ func (p *DataProxy) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// injectFaults injects the faults chaos experiments configured for a method, labeling them on the span and in the logs.
+// Timeouts hang until the caller gives up or ChaosTimeout passed, then drop the connection without a response.
+func (p *DataProxy) injectFaults(ctx context.Context, span *telemetry.Span, method string) error {
+	decision := p.chaos.RPC(method)
+	faults := decision.Faults()
+	if len(faults) == 0 {
+		return nil
+	}
+
+	span.SetAttribute("chaos.faults", strings.Join(faults, ","))
+	// Added latency alone is logged at debug level, as it is injected into every call
+	level := slog.LevelDebug
+	if decision.Error || decision.Timeout {
+		level = slog.LevelWarn
+	}
+	p.logger.Log(ctx, level, "Injected fault", "method", method, "faults", faults)
+
+	if decision.Latency > 0 {
+		_, latencySpan := telemetry.StartSpan(ctx, "chaos latency", telemetry.SpanKindInternal)
+		timer := time.NewTimer(decision.Latency)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+		latencySpan.End()
+
+		// The caller gave up while waiting
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if decision.Timeout {
+		timer := time.NewTimer(constants.ChaosTimeout)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		span.SetError(fmt.Errorf("%s timed out: %w", method, chaos.ErrInjected))
+		panic(http.ErrAbortHandler)
+	}
+
+	if decision.Error {
+		err := fmt.Errorf("%s failed: %w", method, chaos.ErrInjected)
+		span.SetError(err)
+		return err
+	}
+	return nil
+}
+
 func (p *DataProxy) sendError(w http.ResponseWriter, id int, errorMsg string) {
 	response := JSONRPCResponse{
 		Error: &errorMsg,
@@ -167,6 +260,36 @@ func (p *DataProxy) handleMethod(ctx context.Context, method string, params any)
 	case "GetTotalNotes":
 		return p.GetTotalNotes(ctx)
 
+	case "MigrationProgress":
+		var args struct {
+			AccountDetails AccountDetails `json:"accountDetails"`
+		}
+		if err := p.unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		return p.MigrationProgress(ctx, args.AccountDetails)
+
+	case "ApplyMigrationState":
+		var args struct {
+			AccountDetails AccountDetails `json:"accountDetails"`
+		}
+		if err := p.unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		err := p.ApplyMigrationState(ctx, args.AccountDetails)
+		return nil, err
+
+	case "ListChanges":
+		var args struct {
+			AccountDetails AccountDetails `json:"accountDetails"`
+			Cursor         string         `json:"cursor"`
+			Limit          int            `json:"limit"`
+		}
+		if err := p.unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		return p.ListChanges(ctx, args.AccountDetails, args.Cursor, args.Limit)
+
 	case "HealthCheck":
 		err := p.HealthCheck(ctx)
 		return nil, err
@@ -178,6 +301,19 @@ func (p *DataProxy) handleMethod(ctx context.Context, method string, params any)
 	case "ExportShardStats":
 		return p.statsCollector.Export(), nil
 
+	case "SetChaos":
+		var args struct {
+			Config chaos.Config `json:"config"`
+		}
+		if err := p.unmarshalParams(params, &args); err != nil {
+			return nil, err
+		}
+		if err := p.chaos.SetConfig(args.Config); err != nil {
+			return nil, err
+		}
+		p.logger.InfoContext(ctx, "Configured chaos", "config", args.Config.String())
+		return nil, nil
+
 	default:
 		return nil, fmt.Errorf("unknown method: %s", method)
 	}
@@ -7,15 +7,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/proxy"
 	"github.com/brunoscheufler/gopherconuk25/store"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
 	"github.com/google/uuid"
 )
 
 type RestAPIClient struct {
 	baseURL    string
 	httpClient *http.Client
+	onResponse func(ResponseInfo) // Observes every response, nil if unset
 }
 
 func NewRestAPIClient(baseURL string) *RestAPIClient {
@@ -27,6 +32,53 @@ func NewRestAPIClient(baseURL string) *RestAPIClient {
 	}
 }
 
+// ResponseInfo holds details about the response to a request made with a context from WithResponseInfo
+type ResponseInfo struct {
+	RequestID string // ID the server handled the request under
+	ServedBy  int    // ID of the data proxy that served the request, zero if none
+	Status    int    // HTTP status code of the response
+}
+
+type responseInfoContextKey struct{}
+
+// WithResponseInfo returns a context that captures details about the response of the next request made with it
+func WithResponseInfo(ctx context.Context) (context.Context, *ResponseInfo) {
+	info := &ResponseInfo{}
+	return context.WithValue(ctx, responseInfoContextKey{}, info), info
+}
+
+// OnResponse registers a function observing the details of every response, e.g. to count the proxies serving requests.
+// It must be set before the client is used and may be called concurrently.
+func (c *RestAPIClient) OnResponse(observe func(ResponseInfo)) {
+	c.onResponse = observe
+}
+
+// recordResponseInfo fills the context's ResponseInfo, if any, from the response headers and passes it to the observer
+func (c *RestAPIClient) recordResponseInfo(ctx context.Context, resp *http.Response) {
+	info := ResponseInfo{
+		RequestID: resp.Header.Get(telemetry.RequestIDHeader),
+		Status:    resp.StatusCode,
+	}
+	info.ServedBy, _ = strconv.Atoi(resp.Header.Get(ServedByHeader))
+
+	if target, ok := ctx.Value(responseInfoContextKey{}).(*ResponseInfo); ok {
+		*target = info
+	}
+	if c.onResponse != nil {
+		c.onResponse(info)
+	}
+}
+
+// APIError is returned for responses with an error status
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.Status, e.Message)
+}
+
 func (c *RestAPIClient) doRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
 	var reqBody io.Reader
 	if body != nil {
@@ -52,6 +104,7 @@ func (c *RestAPIClient) doRequest(ctx context.Context, method, path string, body
 		return fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	c.recordResponseInfo(ctx, resp)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -61,9 +114,9 @@ func (c *RestAPIClient) doRequest(ctx context.Context, method, path string, body
 	if resp.StatusCode >= 400 {
 		var errResp ErrorResponse
 		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			return fmt.Errorf("API error (%d): %s", resp.StatusCode, errResp.Error)
+			return &APIError{Status: resp.StatusCode, Message: errResp.Error}
 		}
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+		return &APIError{Status: resp.StatusCode, Message: string(respBody)}
 	}
 
 	if result != nil && len(respBody) > 0 {
@@ -96,6 +149,91 @@ func (c *RestAPIClient) UpdateAccount(ctx context.Context, account store.Account
 	return &result, err
 }
 
+func (c *RestAPIClient) GetAccount(ctx context.Context, accountID uuid.UUID) (*store.Account, error) {
+	var result store.Account
+	path := fmt.Sprintf("/accounts/%s", accountID.String())
+	err := c.doRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// Migration operations
+
+func (c *RestAPIClient) AdvanceMigration(ctx context.Context, accountID uuid.UUID) (*store.Account, error) {
+	var result store.Account
+	path := fmt.Sprintf("/accounts/%s/migration/advance", accountID.String())
+	err := c.doRequest(ctx, "POST", path, nil, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) RollbackMigration(ctx context.Context, accountID uuid.UUID) (*store.Account, error) {
+	var result store.Account
+	path := fmt.Sprintf("/accounts/%s/migration/rollback", accountID.String())
+	err := c.doRequest(ctx, "POST", path, nil, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) GetMigrationProgress(ctx context.Context, accountID uuid.UUID) (*proxy.MigrationProgress, error) {
+	var result proxy.MigrationProgress
+	path := fmt.Sprintf("/accounts/%s/migration", accountID.String())
+	err := c.doRequest(ctx, "GET", path, nil, &result)
+	return &result, err
+}
+
+// Deployment operations
+
+func (c *RestAPIClient) Deploy(ctx context.Context) error {
+	return c.doRequest(ctx, "POST", "/deploy", nil, nil)
+}
+
+// Chaos operations
+
+func (c *RestAPIClient) GetChaos(ctx context.Context) (*chaos.Config, error) {
+	var result chaos.Config
+	err := c.doRequest(ctx, "GET", "/chaos", nil, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) SetChaos(ctx context.Context, config chaos.Config) (*chaos.Config, error) {
+	var result chaos.Config
+	err := c.doRequest(ctx, "PUT", "/chaos", config, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) KillProxy(ctx context.Context) (*ProcessFaultResponse, error) {
+	var result ProcessFaultResponse
+	err := c.doRequest(ctx, "POST", "/chaos/kill", nil, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) PauseProxy(ctx context.Context, duration time.Duration) (*ProcessFaultResponse, error) {
+	var result ProcessFaultResponse
+	err := c.doRequest(ctx, "POST", "/chaos/pause", PauseProxyRequest{Duration: telemetry.Duration(duration)}, &result)
+	return &result, err
+}
+
+func (c *RestAPIClient) GetPartitions(ctx context.Context) (chaos.Partitions, error) {
+	var result chaos.Partitions
+	err := c.doRequest(ctx, "GET", "/chaos/partitions", nil, &result)
+	return result, err
+}
+
+func (c *RestAPIClient) SetPartitions(ctx context.Context, partitions chaos.Partitions) (chaos.Partitions, error) {
+	var result chaos.Partitions
+	err := c.doRequest(ctx, "PUT", "/chaos/partitions", partitions, &result)
+	return result, err
+}
+
+func (c *RestAPIClient) HealPartitions(ctx context.Context) error {
+	return c.doRequest(ctx, "DELETE", "/chaos/partitions", nil, nil)
+}
+
+// PartitionProxy partitions the API from a proxy selected by ID, "current" or "previous"
+func (c *RestAPIClient) PartitionProxy(ctx context.Context, proxy string, partition chaos.Partition) (*PartitionProxyResponse, error) {
+	var result PartitionProxyResponse
+	err := c.doRequest(ctx, "PUT", "/chaos/partitions/"+proxy, partition, &result)
+	return &result, err
+}
+
 // Note operations
 
 func (c *RestAPIClient) ListNotes(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
@@ -131,3 +269,37 @@ func (c *RestAPIClient) DeleteNote(ctx context.Context, accountID, noteID uuid.U
 	return c.doRequest(ctx, "DELETE", path, nil, nil)
 }
 
+
+// Raw requests
+
+// Send issues a request with a raw JSON body and returns the status and body of any response, including errors
+func (c *RestAPIClient) Send(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "LoadGenerator")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	c.recordResponseInfo(ctx, resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return resp.StatusCode, respBody, nil
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/constants"
 	"github.com/brunoscheufler/gopherconuk25/proxy"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
@@ -22,6 +27,10 @@ type Server struct {
 	deploymentController *proxy.DeploymentController
 	telemetry            *telemetry.Telemetry
 	logger               *slog.Logger
+
+	// streamCtx is cancelled on shutdown to end long-lived change streams
+	streamCtx    context.Context
+	closeStreams context.CancelFunc
 }
 
 // AppConfig groups common application dependencies to reduce parameter lists
@@ -91,15 +100,25 @@ func NewServer(options ...ServerOption) *Server {
 		option(config)
 	}
 
+	streamCtx, closeStreams := context.WithCancel(context.Background())
+
 	return &Server{
 		accountStore:         config.accountStore,
 		noteStore:            config.noteStore,
 		deploymentController: config.deploymentController,
 		telemetry:            config.telemetry,
 		logger:               config.telemetry.GetLogger(),
+		streamCtx:            streamCtx,
+		closeStreams:         closeStreams,
 	}
 }
 
+// CloseStreams ends all open change streams. http.Server.Shutdown does not wait for
+// long-lived responses, so this should be registered using RegisterOnShutdown.
+func (s *Server) CloseStreams() {
+	s.closeStreams()
+}
+
 // NewServerFromConfig creates a server from AppConfig (backward compatibility)
 func NewServerFromConfig(appConfig *AppConfig) *Server {
 	return NewServer(WithAppConfig(appConfig))
@@ -109,34 +128,82 @@ func (s *Server) SetupRoutes(mux *http.ServeMux) {
 	// Health check endpoint
 	mux.HandleFunc("GET /healthz", s.handleHealthCheck)
 
+	// Prometheus metrics
+	mux.Handle("GET /metrics", telemetry.PrometheusHandler(s.telemetry.GetStatsCollector()))
+
+	// Consistency misses detected by the simulator, newest first
+	mux.HandleFunc("GET /consistency-misses", s.handleListConsistencyMisses)
+
 	// Deployment management
 	mux.HandleFunc("POST /deploy", s.handleDeploy)
 
+	// Chaos experiments
+	mux.HandleFunc("GET /chaos", s.handleGetChaos)
+	mux.HandleFunc("PUT /chaos", s.handleSetChaos)
+	mux.HandleFunc("POST /chaos/kill", s.handleKillProxy)
+	mux.HandleFunc("POST /chaos/pause", s.handlePauseProxy)
+	mux.HandleFunc("GET /chaos/partitions", s.handleGetPartitions)
+	mux.HandleFunc("PUT /chaos/partitions", s.handleSetPartitions)
+	mux.HandleFunc("DELETE /chaos/partitions", s.handleHealPartitions)
+	mux.HandleFunc("PUT /chaos/partitions/{proxy}", s.handlePartitionProxy)
+
 	// Account management
 	mux.HandleFunc("GET /accounts", s.handleListAccounts)
 	mux.HandleFunc("GET /accounts/{id}", s.handleGetAccount)
 	mux.HandleFunc("POST /accounts", s.handleCreateAccount)
 	mux.HandleFunc("PUT /accounts/{id}", s.handleUpdateAccount)
 
+	// Migration management
+	mux.HandleFunc("GET /accounts/{id}/migration", s.handleGetMigrationProgress)
+	mux.HandleFunc("POST /accounts/{id}/migration/advance", s.handleAdvanceMigration)
+	mux.HandleFunc("POST /accounts/{id}/migration/rollback", s.handleRollbackMigration)
+
 	// Note management
 	mux.HandleFunc("GET /accounts/{accountId}/notes", s.handleListNotes)
 	mux.HandleFunc("GET /accounts/{accountId}/notes/{noteId}", s.handleGetNote)
 	mux.HandleFunc("POST /accounts/{accountId}/notes", s.handleCreateNote)
 	mux.HandleFunc("PUT /accounts/{accountId}/notes/{noteId}", s.handleUpdateNote)
 	mux.HandleFunc("DELETE /accounts/{accountId}/notes/{noteId}", s.handleDeleteNote)
+
+	// Change stream
+	mux.HandleFunc("GET /accounts/{accountId}/changes", s.handleStreamChanges)
 }
 
-// responseWriter captures the status code for metrics
+// ServedByHeader carries the ID of the data proxy that served a request in responses
+const ServedByHeader = "X-Served-By"
+
+// responseWriter captures the status code for metrics and adds the proxy that served the request
 type responseWriter struct {
 	http.ResponseWriter
-	status int
+	status      int
+	servedBy    *proxy.ServedBy
+	wroteHeader bool
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		if proxyID := rw.servedBy.ProxyID(); proxyID != 0 {
+			rw.Header().Set(ServedByHeader, strconv.Itoa(proxyID))
+		}
+	}
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Write sends the header first if the handler did not, so the served by header is not skipped
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController can flush streamed responses
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // normalizeAPIPath converts paths with dynamic values to their patterns
 // e.g. "/accounts/123" -> "/accounts/{id}"
 //
@@ -149,6 +216,9 @@ func normalizeAPIPath(path string) string {
 	}{
 		{regexp.MustCompile(`^/accounts/[^/]+/notes/[^/]+$`), "/accounts/{accountId}/notes/{noteId}"},
 		{regexp.MustCompile(`^/accounts/[^/]+/notes$`), "/accounts/{accountId}/notes"},
+		{regexp.MustCompile(`^/accounts/[^/]+/changes$`), "/accounts/{accountId}/changes"},
+		{regexp.MustCompile(`^/accounts/[^/]+/migration/advance$`), "/accounts/{id}/migration/advance"},
+		{regexp.MustCompile(`^/accounts/[^/]+/migration/rollback$`), "/accounts/{id}/migration/rollback"},
 		{regexp.MustCompile(`^/accounts/[^/]+$`), "/accounts/{id}"},
 	}
 
@@ -162,29 +232,65 @@ func normalizeAPIPath(path string) string {
 	return path
 }
 
+// streamingRoutes stay open until the client disconnects. Their duration is not latency,
+// so they are left out of API request metrics and the SLOs evaluated on them.
+var streamingRoutes = map[string]bool{
+	"/accounts/{accountId}/changes": true,
+}
+
 func (s *Server) LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(rw, r)
+		route := normalizeAPIPath(r.URL.Path)
+
+		// Accept the caller's request ID so its logs can be correlated with ours
+		requestID := r.Header.Get(telemetry.RequestIDHeader)
+		if !telemetry.ValidRequestID(requestID) {
+			requestID = telemetry.NewRequestID()
+		}
+		w.Header().Set(telemetry.RequestIDHeader, requestID)
+
+		// Every request starts a trace unless the caller already propagated one
+		remote, _ := telemetry.ExtractTraceparent(r.Header)
+		ctx := telemetry.WithRequestID(r.Context(), requestID)
+		ctx, span := s.telemetry.GetTracer().Start(ctx, r.Method+" "+route, telemetry.SpanKindServer, remote)
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.request_id", requestID)
+		span.SetAttribute("http.route", route)
+
+		ctx, servedBy := proxy.WithServedBy(ctx)
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, servedBy: servedBy}
+		next.ServeHTTP(rw, r.WithContext(ctx))
 		duration := time.Since(start)
 
+		span.SetAttribute("http.status_code", rw.status)
+		if proxyID := servedBy.ProxyID(); proxyID != 0 {
+			span.SetAttribute("proxy.id", proxyID)
+		}
+		if rw.status >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(rw.status)))
+		}
+		span.End()
+
 		// Track API request metrics with normalized path patterns
-		if err := s.telemetry.GetStatsCollector().TrackAPIRequest(
-			r.Method,
-			normalizeAPIPath(r.URL.Path),
-			duration,
-			rw.status,
-		); err != nil {
-			// Log the error but don't fail the request
-			s.logger.Info("Failed to track API request metric", "error", err.Error())
+		if !streamingRoutes[route] {
+			if err := s.telemetry.GetStatsCollector().TrackAPIRequest(
+				r.Method,
+				route,
+				duration,
+				rw.status,
+			); err != nil {
+				// Log the error but don't fail the request
+				s.logger.InfoContext(ctx, "Failed to track API request metric", "error", err.Error())
+			}
 		}
 
-		s.logger.Info("HTTP request",
+		s.logger.InfoContext(ctx, "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"duration", duration,
 			"status", rw.status,
+			"traceID", span.Context().TraceID.String(),
 		)
 	})
 }
@@ -228,17 +334,170 @@ func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"deployment started","timestamp":"` + time.Now().UTC().Format(time.RFC3339) + `"}`))
 }
 
+// handleGetChaos returns the faults proxies are configured to inject
+func (s *Server) handleGetChaos(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, s.deploymentController.Chaos())
+}
+
+// handleSetChaos configures the faults proxies inject, an empty configuration turns chaos off
+func (s *Server) handleSetChaos(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	var config chaos.Config
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := s.deploymentController.SetChaos(r.Context(), config); err != nil {
+		if errors.Is(err, proxy.ErrInvalidChaos) {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.logger.ErrorContext(r.Context(), "Failed to configure chaos", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to configure chaos: "+err.Error())
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, config)
+}
+
+// handleKillProxy kills a random proxy with SIGKILL
+func (s *Server) handleKillProxy(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	proxyID, err := s.deploymentController.KillProxy()
+	s.writeProcessFault(w, r, ProcessFaultResponse{ProxyID: proxyID, Fault: chaos.FaultKill}, err)
+}
+
+// handlePauseProxy pauses a random proxy with SIGSTOP for the requested duration, defaulting to ChaosPauseDuration
+func (s *Server) handlePauseProxy(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	req := PauseProxyRequest{Duration: telemetry.Duration(constants.ChaosPauseDuration)}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	proxyID, err := s.deploymentController.PauseProxy(time.Duration(req.Duration))
+	s.writeProcessFault(w, r, ProcessFaultResponse{ProxyID: proxyID, Fault: chaos.FaultPause, Duration: req.Duration}, err)
+}
+
+// handleGetPartitions returns the partitions between the API and proxies
+func (s *Server) handleGetPartitions(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, s.deploymentController.Partitions())
+}
+
+// handleSetPartitions replaces all partitions between the API and proxies
+func (s *Server) handleSetPartitions(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	var partitions chaos.Partitions
+	if err := json.NewDecoder(r.Body).Decode(&partitions); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := s.deploymentController.SetPartitions(partitions); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, s.deploymentController.Partitions())
+}
+
+// handleHealPartitions removes all partitions between the API and proxies
+func (s *Server) handleHealPartitions(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	if err := s.deploymentController.SetPartitions(nil); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "Failed to heal partitions: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePartitionProxy partitions the API from one proxy, selected by ID or as the current or previous proxy
+func (s *Server) handlePartitionProxy(w http.ResponseWriter, r *http.Request) {
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	var partition chaos.Partition
+	if err := json.NewDecoder(r.Body).Decode(&partition); err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	proxyID, err := s.deploymentController.PartitionProxy(r.PathValue("proxy"), partition)
+	if err != nil {
+		s.writeFaultError(w, r, string(partition.Mode), err)
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, PartitionProxyResponse{ProxyID: proxyID, Partition: partition})
+}
+
+// writeProcessFault responds with the proxy a process fault hit, or why it could not be injected
+func (s *Server) writeProcessFault(w http.ResponseWriter, r *http.Request, response ProcessFaultResponse, err error) {
+	if err != nil {
+		s.writeFaultError(w, r, response.Fault, err)
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, response)
+}
+
+// writeFaultError responds with why a fault could not be injected
+func (s *Server) writeFaultError(w http.ResponseWriter, r *http.Request, fault string, err error) {
+	switch {
+	case errors.Is(err, proxy.ErrInvalidChaos):
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, proxy.ErrNoProxyToFault):
+		s.writeError(w, http.StatusConflict, err.Error())
+	default:
+		s.logger.ErrorContext(r.Context(), "Failed to inject fault", "fault", fault, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "Failed to inject fault: "+err.Error())
+	}
+}
+
 func (s *Server) writeError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
 }
 
-func (s *Server) writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		s.logger.Error("Failed to encode JSON", "error", err)
+		s.logger.ErrorContext(r.Context(), "Failed to encode JSON", "error", err)
 	}
 }
 
@@ -261,8 +520,8 @@ func (s *Server) validateNote(note store.Note) error {
 	if note.Content == "" {
 		return errors.New("note content is required")
 	}
-	if len(note.Content) > 10000 {
-		return errors.New("note content too long (max 10000 characters)")
+	if len(note.Content) > constants.MaxNoteContentLength {
+		return fmt.Errorf("note content too long (max %d characters)", constants.MaxNoteContentLength)
 	}
 	return nil
 }
@@ -289,8 +548,13 @@ func (s *Server) parseNoteID(w http.ResponseWriter, idStr string) (uuid.UUID, bo
 
 // validateAccountExists checks if an account exists and handles error response internally
 func (s *Server) validateAccountExists(w http.ResponseWriter, r *http.Request, accountID uuid.UUID) bool {
-	_, err := s.accountStore.GetAccount(r.Context(), accountID)
+	ctx, span := telemetry.StartSpan(r.Context(), "validateAccountExists", telemetry.SpanKindInternal)
+	defer span.End()
+	span.SetAttribute("account.id", accountID)
+
+	_, err := s.accountStore.GetAccount(ctx, accountID)
 	if err != nil {
+		span.SetError(err)
 		if errors.Is(err, store.ErrAccountNotFound) {
 			s.writeError(w, http.StatusNotFound, "Account not found")
 			return false
@@ -307,7 +571,7 @@ func (s *Server) handleListAccounts(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, http.StatusInternalServerError, "Failed to list accounts")
 		return
 	}
-	s.writeJSON(w, http.StatusOK, accounts)
+	s.writeJSON(w, r, http.StatusOK, accounts)
 }
 
 func (s *Server) handleGetAccount(w http.ResponseWriter, r *http.Request) {
@@ -327,7 +591,7 @@ func (s *Server) handleGetAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, account)
+	s.writeJSON(w, r, http.StatusOK, account)
 }
 
 func (s *Server) handleCreateAccount(w http.ResponseWriter, r *http.Request) {
@@ -352,7 +616,7 @@ func (s *Server) handleCreateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusCreated, account)
+	s.writeJSON(w, r, http.StatusCreated, account)
 }
 
 func (s *Server) handleUpdateAccount(w http.ResponseWriter, r *http.Request) {
@@ -385,7 +649,7 @@ func (s *Server) handleUpdateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, account)
+	s.writeJSON(w, r, http.StatusOK, account)
 }
 
 func (s *Server) handleListNotes(w http.ResponseWriter, r *http.Request) {
@@ -406,7 +670,7 @@ func (s *Server) handleListNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, notes)
+	s.writeJSON(w, r, http.StatusOK, notes)
 }
 
 func (s *Server) handleGetNote(w http.ResponseWriter, r *http.Request) {
@@ -438,7 +702,7 @@ func (s *Server) handleGetNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, note)
+	s.writeJSON(w, r, http.StatusOK, note)
 }
 
 func (s *Server) handleCreateNote(w http.ResponseWriter, r *http.Request) {
@@ -484,7 +748,7 @@ func (s *Server) handleCreateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusCreated, note)
+	s.writeJSON(w, r, http.StatusCreated, note)
 }
 
 func (s *Server) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
@@ -524,7 +788,7 @@ func (s *Server) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.noteStore.UpdateNote(r.Context(), accountID, note); err != nil {
-		s.logger.Error("Failed to update note", "error", err, "accountID", accountID, "noteID", noteID)
+		s.logger.ErrorContext(r.Context(), "Failed to update note", "error", err, "accountID", accountID, "noteID", noteID)
 		if errors.Is(err, store.ErrNoteNotFound) {
 			s.writeError(w, http.StatusNotFound, "Note not found")
 			return
@@ -533,7 +797,7 @@ func (s *Server) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, note)
+	s.writeJSON(w, r, http.StatusOK, note)
 }
 
 func (s *Server) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
@@ -567,3 +831,197 @@ func (s *Server) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// handleGetMigrationProgress counts the notes of an account in legacy and in its shard
+func (s *Server) handleGetMigrationProgress(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.parseAccountID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	progress, err := s.deploymentController.MigrationProgress(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, store.ErrAccountNotFound) {
+			s.writeError(w, http.StatusNotFound, "Account not found")
+			return
+		}
+		s.logger.ErrorContext(r.Context(), "Failed to get migration progress", "error", err, "accountID", accountID)
+		s.writeError(w, http.StatusInternalServerError, "Failed to get migration progress")
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, progress)
+}
+
+// handleAdvanceMigration moves an account to the next migration phase
+func (s *Server) handleAdvanceMigration(w http.ResponseWriter, r *http.Request) {
+	s.handleMigrationTransition(w, r, s.deploymentController.AdvanceMigration)
+}
+
+// handleRollbackMigration moves an account back by one migration phase
+func (s *Server) handleRollbackMigration(w http.ResponseWriter, r *http.Request) {
+	s.handleMigrationTransition(w, r, s.deploymentController.RollbackMigration)
+}
+
+// handleMigrationTransition runs a migration transition of the deployment controller and responds with the updated account
+func (s *Server) handleMigrationTransition(w http.ResponseWriter, r *http.Request, transition func(context.Context, uuid.UUID) (*store.Account, error)) {
+	accountID, ok := s.parseAccountID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	account, err := transition(r.Context(), accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrAccountNotFound):
+			s.writeError(w, http.StatusNotFound, "Account not found")
+		case errors.Is(err, store.ErrInvalidMigrationTransition), errors.Is(err, proxy.ErrSoakIncomplete):
+			s.writeError(w, http.StatusConflict, err.Error())
+		default:
+			s.logger.ErrorContext(r.Context(), "Failed to transition migration", "error", err, "accountID", accountID)
+			s.writeError(w, http.StatusInternalServerError, "Failed to transition migration")
+		}
+		return
+	}
+
+	s.writeJSON(w, r, http.StatusOK, account)
+}
+
+// handleStreamChanges streams note changes of an account as Server-Sent Events.
+// Every event carries the cursor after the change as its ID, so clients can resume
+// using the Last-Event-ID header or the cursor query parameter.
+func (s *Server) handleStreamChanges(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := r.PathValue("accountId")
+	accountID, ok := s.parseAccountID(w, accountIDStr)
+	if !ok {
+		return
+	}
+
+	if s.deploymentController == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "Deployment controller not available")
+		return
+	}
+
+	// Validate that the account exists before streaming changes
+	if !s.validateAccountExists(w, r, accountID) {
+		return
+	}
+
+	cursorStr := r.Header.Get("Last-Event-ID")
+	if cursorStr == "" {
+		cursorStr = r.URL.Query().Get("cursor")
+	}
+
+	cursor, err := store.ParseChangeCursor(cursorStr)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		s.logger.ErrorContext(r.Context(), "Change stream not supported", "error", err)
+		return
+	}
+
+	pollTicker := time.NewTicker(constants.ChangeStreamPollInterval)
+	defer pollTicker.Stop()
+
+	heartbeatTicker := time.NewTicker(constants.ChangeStreamHeartbeat)
+	defer heartbeatTicker.Stop()
+
+	for {
+		batch, err := s.deploymentController.ListChanges(r.Context(), accountID, cursor.String(), constants.ChangeStreamBatchSize)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			// Keep the stream open, the next poll will retry from the same cursor
+			s.logger.ErrorContext(r.Context(), "Failed to list changes", "error", err, "accountID", accountID)
+		} else {
+			for _, change := range batch.Changes {
+				cursor[change.StoreID] = change.Seq
+
+				data, err := json.Marshal(change)
+				if err != nil {
+					s.logger.ErrorContext(r.Context(), "Failed to encode change", "error", err)
+					return
+				}
+
+				if _, err := fmt.Fprintf(w, "id: %s\nevent: change\ndata: %s\n\n", cursor.String(), data); err != nil {
+					return
+				}
+			}
+
+			if len(batch.Changes) > 0 {
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+
+			// Drain backlogs without waiting for the next poll
+			if len(batch.Changes) == constants.ChangeStreamBatchSize {
+				continue
+			}
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.streamCtx.Done():
+			return
+		case <-heartbeatTicker.C:
+			// Comments keep idle connections from being closed by intermediaries
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-pollTicker.C:
+		}
+	}
+}
+
+// handleListConsistencyMisses lists recent consistency misses, optionally filtered by account and limited in number
+func (s *Server) handleListConsistencyMisses(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("accountId")
+
+	limit := telemetry.ConsistencyMissBufferSize
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	misses := make([]telemetry.ConsistencyMiss, 0)
+	for _, miss := range s.telemetry.GetStatsCollector().RecentConsistencyMisses() {
+		if len(misses) == limit {
+			break
+		}
+		if accountID != "" && miss.AccountID != accountID {
+			continue
+		}
+		misses = append(misses, miss)
+	}
+
+	s.writeJSON(w, r, http.StatusOK, misses)
+}
@@ -2,8 +2,14 @@ package restapi
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/brunoscheufler/gopherconuk25/chaos"
 	"github.com/brunoscheufler/gopherconuk25/proxy"
 	"github.com/brunoscheufler/gopherconuk25/store"
 	"github.com/brunoscheufler/gopherconuk25/telemetry"
@@ -17,6 +23,9 @@ func (m *mockAccountStore) ListAccounts(ctx context.Context) ([]store.Account, e
 func (m *mockAccountStore) GetAccount(ctx context.Context, accountID uuid.UUID) (*store.Account, error) { return nil, nil }
 func (m *mockAccountStore) CreateAccount(ctx context.Context, account store.Account) error { return nil }
 func (m *mockAccountStore) UpdateAccount(ctx context.Context, account store.Account) error { return nil }
+func (m *mockAccountStore) TransitionMigrationState(ctx context.Context, accountID uuid.UUID, target store.MigrationState) (*store.Account, error) { return nil, nil }
+func (m *mockAccountStore) RevertMigrationState(ctx context.Context, accountID uuid.UUID, failed, restored store.MigrationState) (*store.Account, error) { return nil, nil }
+func (m *mockAccountStore) ListMigrationTransitions(ctx context.Context, accountID uuid.UUID) ([]store.MigrationTransition, error) { return nil, nil }
 func (m *mockAccountStore) HealthCheck(ctx context.Context) error { return nil }
 func (m *mockAccountStore) Close() error { return nil }
 
@@ -73,4 +82,179 @@ func TestNewServer_Options(t *testing.T) {
 	
 	require.NotNil(t, compatServer, "Compat server should be created")
 	require.Equal(t, mockAccountStore, compatServer.accountStore, "Compat account store should be set")
-}
\ No newline at end of file
+}
+
+func TestLoggingMiddlewareRequestID(t *testing.T) {
+	mockTelemetry := telemetry.New()
+	defer mockTelemetry.StatsCollector.Stop()
+
+	server := NewServer(WithTelemetry(mockTelemetry))
+
+	var seen string
+	handler := server.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = telemetry.RequestIDFromContext(r.Context())
+	}))
+
+	// Request IDs sent by clients are kept
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(telemetry.RequestIDHeader, "client-request-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, "client-request-1", seen)
+	require.Equal(t, "client-request-1", rec.Header().Get(telemetry.RequestIDHeader))
+
+	// Missing or invalid request IDs are replaced
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(telemetry.RequestIDHeader, "not valid")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.NotEqual(t, "not valid", seen)
+	require.True(t, telemetry.ValidRequestID(seen))
+	require.Equal(t, seen, rec.Header().Get(telemetry.RequestIDHeader))
+}
+
+func TestLoggingMiddlewareSkipsStreams(t *testing.T) {
+	mockTelemetry := telemetry.New()
+	defer mockTelemetry.StatsCollector.Stop()
+
+	server := NewServer(WithTelemetry(mockTelemetry))
+	handler := server.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	accountID := uuid.New()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/"+accountID.String()+"/changes", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/"+accountID.String(), nil))
+
+	// Only the regular request is tracked, change streams would distort API latency
+	requests := mockTelemetry.StatsCollector.Export().APIRequests
+	require.Len(t, requests, 1)
+	for _, stats := range requests {
+		require.Equal(t, "/accounts/{id}", stats.Route)
+	}
+}
+
+func TestListConsistencyMisses(t *testing.T) {
+	mockTelemetry := telemetry.New()
+	defer mockTelemetry.StatsCollector.Stop()
+
+	collector := mockTelemetry.GetStatsCollector()
+	require.NoError(t, collector.TrackConsistencyMiss(telemetry.ConsistencyMiss{AccountID: "account-1", NoteID: "note-1", Check: telemetry.ConsistencyCheckRead}))
+	require.NoError(t, collector.TrackConsistencyMiss(telemetry.ConsistencyMiss{AccountID: "account-2", NoteID: "note-2", Check: telemetry.ConsistencyCheckListMissing}))
+	require.NoError(t, collector.TrackConsistencyMiss(telemetry.ConsistencyMiss{AccountID: "account-1", NoteID: "note-3", Check: telemetry.ConsistencyCheckListMismatch}))
+
+	server := NewServer(WithTelemetry(mockTelemetry))
+	mux := http.NewServeMux()
+	server.SetupRoutes(mux)
+	handler := server.LoggingMiddleware(mux)
+
+	list := func(query string) (int, []telemetry.ConsistencyMiss) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/consistency-misses"+query, nil))
+		require.Empty(t, rec.Header().Get(ServedByHeader), "Expected no served by header without a proxy call")
+
+		var misses []telemetry.ConsistencyMiss
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&misses))
+		}
+		return rec.Code, misses
+	}
+
+	status, misses := list("")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, misses, 3)
+	require.Equal(t, "note-3", misses[0].NoteID, "Expected newest miss first")
+
+	_, misses = list("?accountId=account-1&limit=1")
+	require.Len(t, misses, 1)
+	require.Equal(t, "note-3", misses[0].NoteID)
+
+	_, misses = list("?accountId=account-1")
+	require.Len(t, misses, 2)
+
+	status, _ = list("?limit=0")
+	require.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestChaosRoutes(t *testing.T) {
+	mockTelemetry := telemetry.New()
+	defer mockTelemetry.StatsCollector.Stop()
+	deploymentController := proxy.NewDeploymentController(mockTelemetry, &mockAccountStore{})
+	defer deploymentController.Close()
+
+	server := NewServer(WithTelemetry(mockTelemetry), WithDeploymentController(deploymentController))
+	mux := http.NewServeMux()
+	server.SetupRoutes(mux)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	// Without proxies, the configuration is kept for proxies launched later
+	rec := send(http.MethodPut, "/chaos", `{"methods":{"*":{"latency":"20ms","errorRate":0.1}},"storeBusyRate":0.2}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodGet, "/chaos", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var config chaos.Config
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&config))
+	require.Equal(t, 0.2, config.StoreBusyRate)
+	require.Equal(t, telemetry.Duration(20*time.Millisecond), config.Methods[chaos.AllMethods].Latency)
+
+	rec = send(http.MethodPut, "/chaos", `{"storeBusyRate":2}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, config, deploymentController.Chaos(), "Expected invalid configurations to be rejected")
+
+	// Process faults need a running proxy
+	rec = send(http.MethodPost, "/chaos/kill", "")
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = send(http.MethodPost, "/chaos/pause", "")
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = send(http.MethodPost, "/chaos/pause", `{"duration":"-1s"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPartitionRoutes(t *testing.T) {
+	mockTelemetry := telemetry.New()
+	defer mockTelemetry.StatsCollector.Stop()
+	deploymentController := proxy.NewDeploymentController(mockTelemetry, &mockAccountStore{})
+	defer deploymentController.Close()
+
+	server := NewServer(WithTelemetry(mockTelemetry), WithDeploymentController(deploymentController))
+	mux := http.NewServeMux()
+	server.SetupRoutes(mux)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(http.MethodPut, "/chaos/partitions", `{"1":{"mode":"blackhole"},"2":{"mode":"delay","delay":"50ms"}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = send(http.MethodPut, "/chaos/partitions/v3", `{"mode":"duplicate","rate":0.5}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var partitioned PartitionProxyResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&partitioned))
+	require.Equal(t, PartitionProxyResponse{ProxyID: 3, Partition: chaos.Partition{Mode: chaos.PartitionDuplicate, Rate: 0.5}}, partitioned)
+
+	rec = send(http.MethodGet, "/chaos/partitions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var partitions chaos.Partitions
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&partitions))
+	require.Equal(t, "v1: blackhole, v2: delay 50ms, v3: duplicate (50%)", partitions.String())
+
+	// Selecting proxies by role needs a running proxy, invalid partitions are rejected
+	rec = send(http.MethodPut, "/chaos/partitions/previous", `{"mode":"blackhole"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = send(http.MethodPut, "/chaos/partitions/v2", `{"mode":"reorder"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = send(http.MethodPut, "/chaos/partitions", `{"0":{"mode":"blackhole"}}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, deploymentController.Partitions(), 3, "Expected invalid partitions to be rejected")
+
+	rec = send(http.MethodDelete, "/chaos/partitions", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, deploymentController.Partitions())
+}
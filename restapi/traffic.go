@@ -0,0 +1,240 @@
+package restapi
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
+// volatileResponseFields are timestamps set by the server, which differ between a recording and its replay
+var volatileResponseFields = []string{"createdAt", "updatedAt", "migrationUpdatedAt"}
+
+// TrafficRecord is a request to the API and its response, as recorded to a traffic file
+type TrafficRecord struct {
+	Time         time.Time          `json:"time"`
+	Method       string             `json:"method"`
+	Path         string             `json:"path"`
+	Body         json.RawMessage    `json:"body,omitempty"`       // Request body, omitted if it was empty or not JSON
+	ResourceID   string             `json:"resourceId,omitempty"` // ID of the account or note a request created
+	Duration     telemetry.Duration `json:"duration"`
+	Status       int                `json:"status"`
+	ResponseHash string             `json:"responseHash"`
+	RequestID    string             `json:"requestId,omitempty"`
+	ServedBy     int                `json:"servedBy,omitempty"` // ID of the data proxy that served the request, zero if none
+}
+
+// TrafficRecorder appends every account and note request handled by the API to a JSON lines file
+type TrafficRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewTrafficRecorder opens path for appending recorded traffic, creating it if needed
+func NewTrafficRecorder(path string) (*TrafficRecorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create traffic directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("could not open traffic file: %w", err)
+	}
+	return &TrafficRecorder{file: file}, nil
+}
+
+// Close stops recording and closes the traffic file
+func (t *TrafficRecorder) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// recordingWriter keeps a copy of the response body so it can be hashed
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer so http.ResponseController keeps working
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Middleware records requests to accounts and notes. Change streams are not recorded, as they never complete.
+// It must run inside LoggingMiddleware to record request IDs and the proxy that served a request.
+func (t *TrafficRecorder) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !recordTraffic(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		record := TrafficRecord{
+			Time:         start,
+			Method:       r.Method,
+			Path:         r.URL.RequestURI(),
+			Duration:     telemetry.Duration(time.Since(start)),
+			Status:       rw.status,
+			ResponseHash: HashResponseBody(rw.body.Bytes()),
+			RequestID:    w.Header().Get(telemetry.RequestIDHeader),
+		}
+		if json.Valid(body) {
+			record.Body = body
+		}
+		if r.Method == http.MethodPost && rw.status == http.StatusCreated {
+			record.ResourceID = createdID(rw.body.Bytes())
+		}
+		record.ServedBy, _ = strconv.Atoi(w.Header().Get(ServedByHeader))
+
+		// Recording is best effort and must not fail the request
+		_ = t.write(record)
+	})
+}
+
+// recordTraffic returns true for requests worth replaying
+func recordTraffic(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/accounts") && normalizeAPIPath(r.URL.Path) != "/accounts/{accountId}/changes"
+}
+
+// createdID returns the ID of the account or note in a create response
+func createdID(body []byte) string {
+	var resource struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return ""
+	}
+	return resource.ID
+}
+
+func (t *TrafficRecorder) write(record TrafficRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not encode traffic record: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil
+	}
+	if _, err := t.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write traffic record: %w", err)
+	}
+	return nil
+}
+
+// LoadTrafficRecording reads all records of a traffic file in the order they were recorded
+func LoadTrafficRecording(path string) ([]TrafficRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open traffic file: %w", err)
+	}
+	defer file.Close()
+
+	var records []TrafficRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record TrafficRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("could not decode traffic record on line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read traffic file: %w", err)
+	}
+
+	return records, nil
+}
+
+// HashResponseBody hashes a response body so responses can be compared across runs.
+// Timestamps set by the server are ignored in JSON bodies, other bodies are hashed as is.
+func HashResponseBody(body []byte) string {
+	var value any
+	if err := json.Unmarshal(body, &value); err == nil {
+		stripVolatileFields(value)
+		if normalized, err := json.Marshal(value); err == nil {
+			body = normalized
+		}
+	}
+
+	hash := sha256.Sum256(body)
+	return fmt.Sprintf("%x", hash)
+}
+
+// stripVolatileFields removes server-set timestamps from decoded JSON, including nested objects
+func stripVolatileFields(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, field := range volatileResponseFields {
+			delete(v, field)
+		}
+		for _, nested := range v {
+			stripVolatileFields(nested)
+		}
+	case []any:
+		for _, nested := range v {
+			stripVolatileFields(nested)
+		}
+	}
+}
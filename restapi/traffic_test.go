@@ -0,0 +1,67 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficRecorderMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder, err := NewTrafficRecorder(path)
+	require.NoError(t, err)
+
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ServedByHeader, "2")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"11111111-1111-1111-1111-111111111111","name":"test","createdAt":"2025-01-01T00:00:00Z"}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"name":"test"}`)),
+		httptest.NewRequest(http.MethodGet, "/accounts/11111111-1111-1111-1111-111111111111/notes", nil),
+		httptest.NewRequest(http.MethodGet, "/accounts/11111111-1111-1111-1111-111111111111/changes", nil),
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+	}
+	for _, req := range requests {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.NoError(t, recorder.Close())
+
+	records, err := LoadTrafficRecording(path)
+	require.NoError(t, err)
+	require.Len(t, records, 2, "Change streams and routes outside of accounts should not be recorded")
+
+	create := records[0]
+	require.Equal(t, http.MethodPost, create.Method)
+	require.Equal(t, "/accounts", create.Path)
+	require.JSONEq(t, `{"name":"test"}`, string(create.Body))
+	require.Equal(t, http.StatusCreated, create.Status)
+	require.Equal(t, "11111111-1111-1111-1111-111111111111", create.ResourceID)
+	require.Equal(t, 2, create.ServedBy)
+
+	list := records[1]
+	require.Equal(t, http.StatusOK, list.Status)
+	require.Empty(t, list.Body)
+	require.Empty(t, list.ResourceID)
+	require.Equal(t, HashResponseBody([]byte(`[]`)), list.ResponseHash)
+}
+
+func TestHashResponseBodyIgnoresTimestamps(t *testing.T) {
+	recorded := HashResponseBody([]byte(`{"id":"a","content":"hello","createdAt":"2025-01-01T00:00:00Z","updatedAt":"2025-01-01T00:00:00Z"}`))
+	replayed := HashResponseBody([]byte(`{"updatedAt":"2025-06-01T12:00:00Z","content":"hello","id":"a","createdAt":"2025-06-01T12:00:00Z"}`))
+	require.Equal(t, recorded, replayed, "Timestamps and key order should not change the hash")
+
+	changed := HashResponseBody([]byte(`{"id":"a","content":"goodbye","createdAt":"2025-01-01T00:00:00Z"}`))
+	require.NotEqual(t, recorded, changed)
+
+	require.NotEqual(t, HashResponseBody([]byte("not json")), HashResponseBody([]byte("other")))
+}
@@ -1,5 +1,28 @@
 package restapi
 
+import (
+	"github.com/brunoscheufler/gopherconuk25/chaos"
+	"github.com/brunoscheufler/gopherconuk25/telemetry"
+)
+
 type ErrorResponse struct {
 	Error string `json:"error"`
-}
\ No newline at end of file
+}
+
+// PauseProxyRequest configures how long a proxy is paused, defaulting to ChaosPauseDuration
+type PauseProxyRequest struct {
+	Duration telemetry.Duration `json:"duration,omitempty"`
+}
+
+// PartitionProxyResponse names the proxy the API was partitioned from and the partition
+type PartitionProxyResponse struct {
+	ProxyID   int             `json:"proxyId"`
+	Partition chaos.Partition `json:"partition"`
+}
+
+// ProcessFaultResponse names the proxy a process fault was injected into
+type ProcessFaultResponse struct {
+	ProxyID  int                `json:"proxyId"`
+	Fault    string             `json:"fault"`
+	Duration telemetry.Duration `json:"duration,omitempty"`
+}
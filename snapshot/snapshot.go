@@ -0,0 +1,397 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/proxy"
+	"github.com/brunoscheufler/gopherconuk25/store"
+)
+
+const (
+	dataDir      = ".data"
+	snapshotDir  = ".snapshots"
+	backupDir    = ".backups"
+	manifestFile = "manifest.json"
+)
+
+var (
+	ErrSnapshotExists   = errors.New("snapshot already exists")
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrInvalidName      = errors.New("invalid snapshot name")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrProxiesRunning   = errors.New("data proxies are running")
+)
+
+// validName restricts snapshot names to safe directory names
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// StoreFile describes the copy of a single database within a snapshot
+type StoreFile struct {
+	Store  string `json:"store"`
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a snapshot and is stored alongside its database files
+type Manifest struct {
+	Name      string      `json:"name"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Stores    []StoreFile `json:"stores"`
+}
+
+// Options configures where snapshots are read from and written to
+type Options struct {
+	// BasePath is the directory containing the .data and .snapshots directories, defaulting to the working directory
+	BasePath string
+	Logger   *slog.Logger
+}
+
+func (o Options) logger() *slog.Logger {
+	if o.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return o.Logger
+}
+
+func (o Options) snapshotPath(name string) string {
+	return filepath.Join(o.BasePath, snapshotDir, name)
+}
+
+// storeNames lists every database captured by a snapshot
+func storeNames() []string {
+	return append([]string{constants.AccountStore, constants.LegacyNoteStore}, constants.Shards...)
+}
+
+// Create captures every store into a new snapshot with the given name.
+// Each database is copied with VACUUM INTO, so its copy is consistent on its own. The databases are copied one after another,
+// so while the application runs, a snapshot can include a write to one store but miss a related write to another,
+// e.g. a new account without its first notes or a note mirrored to a shard after the shard was copied.
+// Snapshots taken while the application is stopped are consistent across all stores.
+// Note stores that were never created are skipped, the accounts database is required.
+func Create(ctx context.Context, opts Options, name string) (*Manifest, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	target := opts.snapshotPath(name)
+	if _, err := os.Stat(target); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotExists, name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return nil, fmt.Errorf("could not create snapshot directory: %w", err)
+	}
+
+	// Write into a temporary directory first so incomplete snapshots are never listed
+	tmpDir, err := os.MkdirTemp(filepath.Dir(target), ".tmp-"+name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest := &Manifest{
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+
+	for _, storeName := range storeNames() {
+		file, err := snapshotStore(ctx, opts, storeName, tmpDir)
+		if err != nil {
+			if errors.Is(err, store.ErrDatabaseNotFound) && storeName != constants.AccountStore {
+				opts.logger().Debug("skipping missing store in snapshot", "store", storeName)
+				continue
+			}
+			return nil, err
+		}
+		manifest.Stores = append(manifest.Stores, *file)
+	}
+
+	if err := writeManifest(tmpDir, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmpDir, target); err != nil {
+		return nil, fmt.Errorf("could not finalize snapshot: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// snapshotStore copies a single database into dir
+func snapshotStore(ctx context.Context, opts Options, storeName, dir string) (*StoreFile, error) {
+	storeOpts := store.DefaultStoreOptions(storeName, opts.logger())
+	storeOpts.BasePath = opts.BasePath
+	storeOpts.Config.ReadOnly = true
+
+	var s io.Closer
+	var err error
+	if storeName == constants.AccountStore {
+		s, err = store.NewAccountStore(storeOpts)
+	} else {
+		s, err = store.NewNoteStore(storeOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open store %q: %w", storeName, err)
+	}
+	defer s.Close()
+
+	snapshotter, ok := s.(store.Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("store %q does not support snapshots", storeName)
+	}
+
+	file := storeName + ".db"
+	path := filepath.Join(dir, file)
+	if err := snapshotter.SnapshotTo(ctx, path); err != nil {
+		return nil, fmt.Errorf("could not snapshot store %q: %w", storeName, err)
+	}
+
+	size, sum, err := checksum(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoreFile{Store: storeName, File: file, Size: size, SHA256: sum}, nil
+}
+
+// List returns all snapshots, oldest first
+func List(opts Options) ([]Manifest, error) {
+	entries, err := os.ReadDir(filepath.Join(opts.BasePath, snapshotDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read snapshot directory: %w", err)
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() || !validName.MatchString(entry.Name()) {
+			continue
+		}
+
+		manifest, err := readManifest(opts.snapshotPath(entry.Name()))
+		if err != nil {
+			opts.logger().Warn("skipping unreadable snapshot", "name", entry.Name(), "error", err)
+			continue
+		}
+		manifests = append(manifests, *manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].CreatedAt.Before(manifests[j].CreatedAt)
+	})
+
+	return manifests, nil
+}
+
+// Restore replaces the databases in .data with the copies of a snapshot.
+// The snapshot is verified and staged next to .data before any database is replaced. The databases of all stores,
+// including stores missing from the snapshot, are moved to .snapshots/.backups together with their WAL files.
+// Everything else in .data, e.g. traces and telemetry recordings, is left in place.
+// Restoring is refused while data proxies are running.
+func Restore(opts Options, name string) (*Manifest, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	pids, err := proxy.RunningProxies(opts.BasePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pids) > 0 {
+		return nil, fmt.Errorf("%w: stop the application before restoring (pids %v)", ErrProxiesRunning, pids)
+	}
+
+	source := opts.snapshotPath(name)
+	manifest, err := readManifest(source)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+		}
+		return nil, err
+	}
+
+	// Stage a verified copy of the snapshot on the same file system as .data so replacing databases is a rename
+	dataPath := filepath.Join(opts.BasePath, dataDir)
+	if err := os.MkdirAll(dataPath, 0750); err != nil {
+		return nil, fmt.Errorf("could not create %s: %w", dataDir, err)
+	}
+	staging, err := os.MkdirTemp(dataPath, ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	for _, f := range manifest.Stores {
+		if f.File != filepath.Base(f.File) {
+			return nil, fmt.Errorf("invalid file %q in manifest of snapshot %s", f.File, name)
+		}
+		if err := copyVerified(filepath.Join(source, f.File), filepath.Join(staging, f.File), f); err != nil {
+			return nil, err
+		}
+	}
+
+	backup := filepath.Join(opts.BasePath, snapshotDir, backupDir, time.Now().Format("20060102-150405.000000000"))
+	backedUp, err := backUpStores(dataPath, backup)
+	if err != nil {
+		return nil, errors.Join(err, restoreBackup(dataPath, backup, backedUp))
+	}
+
+	for _, f := range manifest.Stores {
+		if err := os.Rename(filepath.Join(staging, f.File), filepath.Join(dataPath, f.File)); err != nil {
+			// Put the previous databases back so a failed restore leaves everything as it was
+			for _, restored := range manifest.Stores {
+				_ = os.Remove(filepath.Join(dataPath, restored.File))
+			}
+			if restoreErr := restoreBackup(dataPath, backup, backedUp); restoreErr != nil {
+				return nil, fmt.Errorf("could not restore snapshot: %w (previous data remains at %s)", err, backup)
+			}
+			return nil, fmt.Errorf("could not restore snapshot: %w", err)
+		}
+	}
+
+	if len(backedUp) > 0 {
+		opts.logger().Info("restored snapshot", "name", name, "backup", backup)
+	}
+
+	return manifest, nil
+}
+
+// storeFiles lists the files SQLite keeps for each store, the database and its write-ahead log
+func storeFiles() []string {
+	var files []string
+	for _, storeName := range storeNames() {
+		for _, suffix := range []string{".db", ".db-wal", ".db-shm"} {
+			files = append(files, storeName+suffix)
+		}
+	}
+	return files
+}
+
+// backUpStores moves the files of all stores from the data directory into the backup directory.
+// It returns the files moved, so they can be put back if restoring fails.
+func backUpStores(dataPath, backup string) ([]string, error) {
+	var moved []string
+	for _, file := range storeFiles() {
+		if _, err := os.Stat(filepath.Join(dataPath, file)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return moved, fmt.Errorf("could not back up %s: %w", file, err)
+		}
+
+		if err := os.MkdirAll(backup, 0750); err != nil {
+			return moved, fmt.Errorf("could not create backup directory: %w", err)
+		}
+		if err := os.Rename(filepath.Join(dataPath, file), filepath.Join(backup, file)); err != nil {
+			return moved, fmt.Errorf("could not back up %s: %w", file, err)
+		}
+		moved = append(moved, file)
+	}
+	return moved, nil
+}
+
+// restoreBackup moves backed up store files back into the data directory
+func restoreBackup(dataPath, backup string, files []string) error {
+	var errs []error
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(backup, file), filepath.Join(dataPath, file)); err != nil {
+			errs = append(errs, fmt.Errorf("could not put back %s: %w", file, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// copyVerified copies src to dst and checks the copy against the manifest entry
+func copyVerified(src, dst string, expected StoreFile) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("could not open snapshot file %s: %w", expected.File, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", dst, err)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), in)
+	if err != nil {
+		out.Close()
+		return fmt.Errorf("could not copy snapshot file %s: %w", expected.File, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("could not sync %s: %w", dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("could not close %s: %w", dst, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if size != expected.Size || sum != expected.SHA256 {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, expected.File)
+	}
+
+	return nil
+}
+
+// checksum returns the size and SHA-256 of a file
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), data, 0640); err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	return nil
+}
+
+func readManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not decode manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
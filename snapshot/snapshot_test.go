@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+)
+
+func openStores(t *testing.T, basePath string) (store.AccountStore, store.NoteStore) {
+	logger := slog.New(slog.DiscardHandler)
+
+	accountOpts := store.DefaultStoreOptions(constants.AccountStore, logger)
+	accountOpts.BasePath = basePath
+	accountStore, err := store.NewAccountStore(accountOpts)
+	require.NoError(t, err)
+
+	noteOpts := store.DefaultStoreOptions(constants.LegacyNoteStore, logger)
+	noteOpts.BasePath = basePath
+	noteStore, err := store.NewNoteStore(noteOpts)
+	require.NoError(t, err)
+
+	return accountStore, noteStore
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	opts := Options{BasePath: basePath}
+
+	accountStore, noteStore := openStores(t, basePath)
+	account := store.Account{ID: uuid.New(), Name: "before"}
+	require.NoError(t, accountStore.CreateAccount(ctx, account))
+	now := time.Now()
+	note := store.Note{ID: uuid.New(), Creator: account.ID, CreatedAt: now, UpdatedAt: now, Content: "snapshotted"}
+	require.NoError(t, noteStore.CreateNote(ctx, account.ID, note))
+
+	// Snapshots are taken while the stores are open
+	manifest, err := Create(ctx, opts, "baseline")
+	require.NoError(t, err)
+	require.Equal(t, "baseline", manifest.Name)
+	require.Len(t, manifest.Stores, 2, "shards that were never created are skipped")
+	require.Equal(t, constants.AccountStore, manifest.Stores[0].Store)
+	require.Equal(t, constants.LegacyNoteStore, manifest.Stores[1].Store)
+
+	_, err = Create(ctx, opts, "baseline")
+	require.ErrorIs(t, err, ErrSnapshotExists)
+	_, err = Create(ctx, opts, "../escape")
+	require.ErrorIs(t, err, ErrInvalidName)
+
+	// Change state after the snapshot, including a shard the snapshot does not contain and files that are not stores
+	require.NoError(t, noteStore.DeleteNote(ctx, account.ID, note))
+	require.NoError(t, accountStore.CreateAccount(ctx, store.Account{ID: uuid.New(), Name: "after"}))
+	require.NoError(t, accountStore.Close())
+	require.NoError(t, noteStore.Close())
+	shardFile := filepath.Join(basePath, dataDir, constants.NewNoteStore+".db")
+	require.NoError(t, os.WriteFile(shardFile, []byte("created after the snapshot"), 0640))
+	tracesFile := filepath.Join(basePath, dataDir, "traces.jsonl")
+	require.NoError(t, os.WriteFile(tracesFile, []byte("{}\n"), 0640))
+
+	snapshots, err := List(opts)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.Equal(t, "baseline", snapshots[0].Name)
+
+	restored, err := Restore(opts, "baseline")
+	require.NoError(t, err)
+	require.Equal(t, manifest.Stores, restored.Stores)
+
+	// The previous databases are kept as a backup, files that are not stores stay in place
+	backups, err := os.ReadDir(filepath.Join(basePath, snapshotDir, backupDir))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup := filepath.Join(basePath, snapshotDir, backupDir, backups[0].Name())
+	require.FileExists(t, filepath.Join(backup, constants.AccountStore+".db"))
+	require.FileExists(t, filepath.Join(backup, constants.NewNoteStore+".db"))
+	require.NoFileExists(t, shardFile)
+	require.FileExists(t, tracesFile)
+
+	accountStore, noteStore = openStores(t, basePath)
+	defer accountStore.Close()
+	defer noteStore.Close()
+
+	accounts, err := accountStore.ListAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, "before", accounts[0].Name)
+
+	restoredNote, err := noteStore.GetNote(ctx, account.ID, note.ID)
+	require.NoError(t, err)
+	require.Equal(t, "snapshotted", restoredNote.Content)
+
+	_, err = Restore(opts, "missing")
+	require.ErrorIs(t, err, ErrSnapshotNotFound)
+}
+
+func TestRestoreRejectsCorruptSnapshot(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	opts := Options{BasePath: basePath}
+
+	accountStore, noteStore := openStores(t, basePath)
+	require.NoError(t, accountStore.CreateAccount(ctx, store.Account{ID: uuid.New(), Name: "current"}))
+	require.NoError(t, accountStore.Close())
+	require.NoError(t, noteStore.Close())
+
+	_, err := Create(ctx, opts, "corrupt")
+	require.NoError(t, err)
+
+	file := filepath.Join(basePath, snapshotDir, "corrupt", constants.AccountStore+".db")
+	require.NoError(t, os.WriteFile(file, []byte("not a database"), 0640))
+
+	_, err = Restore(opts, "corrupt")
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// The current data is left untouched
+	_, err = os.Stat(filepath.Join(basePath, dataDir, constants.AccountStore+".db"))
+	require.NoError(t, err)
+}
+
+func TestRestoreRefusedWhileProxiesRun(t *testing.T) {
+	basePath := t.TempDir()
+
+	// A file left behind by a proxy that crashed is ignored, even though its process ID is alive again
+	pidDir := filepath.Join(basePath, dataDir, "proxies")
+	pidFile := filepath.Join(pidDir, strconv.Itoa(os.Getpid())+".pid")
+	require.NoError(t, os.MkdirAll(pidDir, 0750))
+	require.NoError(t, os.WriteFile(pidFile, []byte("1"), 0640))
+
+	_, err := Restore(Options{BasePath: basePath}, "any")
+	require.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	// Register the test process itself as a running proxy by holding the lock of its file
+	file, err := os.Open(pidFile)
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, syscall.Flock(int(file.Fd()), syscall.LOCK_EX))
+
+	_, err = Restore(Options{BasePath: basePath}, "any")
+	require.ErrorIs(t, err, ErrProxiesRunning)
+}
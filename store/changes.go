@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangeOperation describes the kind of write captured in a note change
+type ChangeOperation string
+
+const (
+	ChangeOperationCreate ChangeOperation = "create"
+	ChangeOperationUpdate ChangeOperation = "update"
+	ChangeOperationDelete ChangeOperation = "delete"
+)
+
+// NoteChange is a single entry of a note store's change outbox
+type NoteChange struct {
+	Seq       int64           `json:"seq"`
+	StoreID   string          `json:"storeId,omitempty"`
+	Operation ChangeOperation `json:"operation"`
+	AccountID uuid.UUID       `json:"accountId"`
+	NoteID    uuid.UUID       `json:"noteId"`
+	Note      *Note           `json:"note,omitempty"` // State after the change, nil for deletes
+	ChangedAt time.Time       `json:"changedAt"`
+}
+
+// ChangeFeed exposes the change outbox of a note store.
+// Changes are written in the same transaction as the note write they describe.
+type ChangeFeed interface {
+	// ListChanges returns up to limit changes of an account with a sequence number greater than afterSeq, ordered by sequence number.
+	ListChanges(ctx context.Context, accountID uuid.UUID, afterSeq int64, limit int) ([]NoteChange, error)
+}
+
+// ChangeCursor holds the last consumed sequence number per note store.
+// It allows resuming change streams that span multiple stores.
+type ChangeCursor map[string]int64
+
+// String encodes the cursor as a stable, comma-separated list of store:seq pairs
+func (c ChangeCursor) String() string {
+	storeIDs := make([]string, 0, len(c))
+	for storeID := range c {
+		storeIDs = append(storeIDs, storeID)
+	}
+	sort.Strings(storeIDs)
+
+	parts := make([]string, 0, len(storeIDs))
+	for _, storeID := range storeIDs {
+		parts = append(parts, storeID+":"+strconv.FormatInt(c[storeID], 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Clone returns a copy of the cursor that can be advanced independently
+func (c ChangeCursor) Clone() ChangeCursor {
+	clone := make(ChangeCursor, len(c))
+	for storeID, seq := range c {
+		clone[storeID] = seq
+	}
+	return clone
+}
+
+// ParseChangeCursor decodes a cursor previously encoded with ChangeCursor.String.
+// An empty string yields an empty cursor, starting at the beginning of every store.
+func ParseChangeCursor(s string) (ChangeCursor, error) {
+	cursor := make(ChangeCursor)
+	if strings.TrimSpace(s) == "" {
+		return cursor, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		storeID, seqStr, found := strings.Cut(part, ":")
+		if !found || storeID == "" {
+			return nil, fmt.Errorf("invalid cursor segment %q", part)
+		}
+
+		seq, err := strconv.ParseInt(seqStr, 10, 64)
+		if err != nil || seq < 0 {
+			return nil, fmt.Errorf("invalid sequence number in cursor segment %q", part)
+		}
+
+		cursor[storeID] = seq
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeCursorRoundTrip(t *testing.T) {
+	cursor := ChangeCursor{"new": 3, "legacy": 12}
+	require.Equal(t, "legacy:12,new:3", cursor.String())
+
+	parsed, err := ParseChangeCursor(cursor.String())
+	require.NoError(t, err)
+	require.Equal(t, cursor, parsed)
+
+	empty, err := ParseChangeCursor("")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+	require.Equal(t, "", empty.String())
+
+	for _, invalid := range []string{"legacy", ":3", "legacy:abc", "legacy:-1", "legacy:1,"} {
+		_, err := ParseChangeCursor(invalid)
+		require.Error(t, err, "cursor %q should be rejected", invalid)
+	}
+}
+
+func TestNoteChangesOutbox(t *testing.T) {
+	_, noteStore, _ := setupTestStores(t, "test_note_changes")
+	defer noteStore.Close()
+
+	feed, ok := noteStore.(ChangeFeed)
+	require.True(t, ok, "SQLite note store should expose a change feed")
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	otherAccountID := uuid.New()
+	now := time.Now()
+
+	note := Note{ID: uuid.New(), Creator: accountID, CreatedAt: now, UpdatedAt: now, Content: "first"}
+	require.NoError(t, noteStore.CreateNote(ctx, accountID, note))
+
+	note.Content = "second"
+	note.UpdatedAt = now.Add(time.Second)
+	require.NoError(t, noteStore.UpdateNote(ctx, accountID, note))
+
+	// Stale updates are not applied and must not be recorded
+	stale := note
+	stale.Content = "stale"
+	stale.UpdatedAt = now
+	require.NoError(t, noteStore.UpdateNote(ctx, accountID, stale))
+
+	require.NoError(t, noteStore.DeleteNote(ctx, accountID, note))
+
+	otherNote := Note{ID: uuid.New(), Creator: otherAccountID, CreatedAt: now, UpdatedAt: now, Content: "other"}
+	require.NoError(t, noteStore.CreateNote(ctx, otherAccountID, otherNote))
+
+	changes, err := feed.ListChanges(ctx, accountID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	require.Equal(t, ChangeOperationCreate, changes[0].Operation)
+	require.Equal(t, "first", changes[0].Note.Content)
+
+	require.Equal(t, ChangeOperationUpdate, changes[1].Operation)
+	require.Equal(t, "second", changes[1].Note.Content)
+
+	require.Equal(t, ChangeOperationDelete, changes[2].Operation)
+	require.Nil(t, changes[2].Note)
+	require.Equal(t, note.ID, changes[2].NoteID)
+
+	require.Less(t, changes[0].Seq, changes[1].Seq)
+	require.Less(t, changes[1].Seq, changes[2].Seq)
+
+	// Resuming after a sequence number only returns later changes
+	resumed, err := feed.ListChanges(ctx, accountID, changes[0].Seq, 1)
+	require.NoError(t, err)
+	require.Len(t, resumed, 1)
+	require.Equal(t, changes[1].Seq, resumed[0].Seq)
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MigrationState is the phase an account is in while its notes are migrated from legacy to its shard
+type MigrationState string
+
+const (
+	// MigrationStateNotStarted serves the account from legacy only
+	MigrationStateNotStarted MigrationState = "not_started"
+	// MigrationStateDualWrite mirrors writes to the shard and shadow-reads from it
+	MigrationStateDualWrite MigrationState = "dual_write"
+	// MigrationStateBackfilling copies existing legacy notes to the shard while dual-writing
+	MigrationStateBackfilling MigrationState = "backfilling"
+	// MigrationStateVerifying keeps dual-writing and shadow-reading until the soak period has passed
+	MigrationStateVerifying MigrationState = "verifying"
+	// MigrationStateReadFromNew serves reads from the shard while still writing to legacy
+	MigrationStateReadFromNew MigrationState = "read_from_new"
+	// MigrationStateCleanup stops writing to legacy and removes the account's legacy notes
+	MigrationStateCleanup MigrationState = "cleanup"
+	// MigrationStateDone serves the account from the shard only
+	MigrationStateDone MigrationState = "done"
+	// MigrationStateRollingBack removes the account's shard notes after aborting a migration
+	MigrationStateRollingBack MigrationState = "rolling_back"
+)
+
+// MigrationStates lists all migration states in phase order
+var MigrationStates = []MigrationState{
+	MigrationStateNotStarted,
+	MigrationStateDualWrite,
+	MigrationStateBackfilling,
+	MigrationStateVerifying,
+	MigrationStateReadFromNew,
+	MigrationStateCleanup,
+	MigrationStateDone,
+	MigrationStateRollingBack,
+}
+
+// ErrInvalidMigrationTransition is returned for transitions not allowed by the migration state machine
+var ErrInvalidMigrationTransition = errors.New("invalid migration transition")
+
+// MigrationTransition records a single change of an account's migration state
+type MigrationTransition struct {
+	AccountID uuid.UUID      `json:"accountId"`
+	From      MigrationState `json:"from"`
+	To        MigrationState `json:"to"`
+	At        time.Time      `json:"at"`
+}
+
+// Normalize maps the zero value to MigrationStateNotStarted
+func (s MigrationState) Normalize() MigrationState {
+	if s == "" {
+		return MigrationStateNotStarted
+	}
+	return s
+}
+
+// Valid reports whether s is a known migration state
+func (s MigrationState) Valid() bool {
+	for _, state := range MigrationStates {
+		if s.Normalize() == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the phase following s
+func (s MigrationState) Next() (MigrationState, error) {
+	switch s.Normalize() {
+	case MigrationStateNotStarted:
+		return MigrationStateDualWrite, nil
+	case MigrationStateDualWrite:
+		return MigrationStateBackfilling, nil
+	case MigrationStateBackfilling:
+		return MigrationStateVerifying, nil
+	case MigrationStateVerifying:
+		return MigrationStateReadFromNew, nil
+	case MigrationStateReadFromNew:
+		return MigrationStateCleanup, nil
+	case MigrationStateCleanup:
+		return MigrationStateDone, nil
+	case MigrationStateRollingBack:
+		return MigrationStateNotStarted, nil
+	default:
+		return "", fmt.Errorf("%w: cannot advance from %s", ErrInvalidMigrationTransition, s)
+	}
+}
+
+// Previous returns the phase to roll back to from s.
+// Once legacy notes are being removed in cleanup, a migration can no longer be rolled back.
+func (s MigrationState) Previous() (MigrationState, error) {
+	switch s.Normalize() {
+	case MigrationStateDualWrite:
+		return MigrationStateRollingBack, nil
+	case MigrationStateBackfilling:
+		return MigrationStateDualWrite, nil
+	case MigrationStateVerifying:
+		return MigrationStateBackfilling, nil
+	case MigrationStateReadFromNew:
+		return MigrationStateVerifying, nil
+	default:
+		return "", fmt.Errorf("%w: cannot roll back from %s", ErrInvalidMigrationTransition, s)
+	}
+}
+
+// CanTransitionTo reports whether moving from s to target advances or rolls back exactly one phase
+func (s MigrationState) CanTransitionTo(target MigrationState) bool {
+	if next, err := s.Next(); err == nil && next == target {
+		return true
+	}
+	if previous, err := s.Previous(); err == nil && previous == target {
+		return true
+	}
+	return false
+}
+
+// WritesLegacy reports whether writes go to the legacy store
+func (s MigrationState) WritesLegacy() bool {
+	switch s.Normalize() {
+	case MigrationStateCleanup, MigrationStateDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// WritesShard reports whether writes go to the account's shard
+func (s MigrationState) WritesShard() bool {
+	switch s.Normalize() {
+	case MigrationStateNotStarted, MigrationStateRollingBack:
+		return false
+	default:
+		return true
+	}
+}
+
+// ReadsShard reports whether reads are served from the account's shard
+func (s MigrationState) ReadsShard() bool {
+	switch s.Normalize() {
+	case MigrationStateReadFromNew, MigrationStateCleanup, MigrationStateDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShadowReads reports whether reads served from legacy are verified against the shard
+func (s MigrationState) ShadowReads() bool {
+	switch s.Normalize() {
+	case MigrationStateDualWrite, MigrationStateBackfilling, MigrationStateVerifying:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsMigrating reports whether the account is between starting and completing a migration
+func (s MigrationState) IsMigrating() bool {
+	switch s.Normalize() {
+	case MigrationStateNotStarted, MigrationStateDone:
+		return false
+	default:
+		return true
+	}
+}
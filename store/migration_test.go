@@ -0,0 +1,78 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationStateAdvance(t *testing.T) {
+	expected := []MigrationState{
+		MigrationStateDualWrite,
+		MigrationStateBackfilling,
+		MigrationStateVerifying,
+		MigrationStateReadFromNew,
+		MigrationStateCleanup,
+		MigrationStateDone,
+	}
+
+	state := MigrationStateNotStarted
+	for _, next := range expected {
+		advanced, err := state.Next()
+		require.NoError(t, err)
+		require.Equal(t, next, advanced)
+		require.True(t, state.CanTransitionTo(next))
+		state = advanced
+	}
+
+	_, err := MigrationStateDone.Next()
+	require.ErrorIs(t, err, ErrInvalidMigrationTransition)
+
+	next, err := MigrationStateRollingBack.Next()
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateNotStarted, next)
+}
+
+func TestMigrationStateRollback(t *testing.T) {
+	rollbacks := map[MigrationState]MigrationState{
+		MigrationStateDualWrite:   MigrationStateRollingBack,
+		MigrationStateBackfilling: MigrationStateDualWrite,
+		MigrationStateVerifying:   MigrationStateBackfilling,
+		MigrationStateReadFromNew: MigrationStateVerifying,
+	}
+	for from, to := range rollbacks {
+		previous, err := from.Previous()
+		require.NoError(t, err)
+		require.Equal(t, to, previous)
+	}
+
+	for _, state := range []MigrationState{MigrationStateNotStarted, MigrationStateCleanup, MigrationStateDone, MigrationStateRollingBack} {
+		_, err := state.Previous()
+		require.ErrorIs(t, err, ErrInvalidMigrationTransition, "rollback from %s should be refused", state)
+	}
+
+	require.False(t, MigrationStateNotStarted.CanTransitionTo(MigrationStateVerifying), "phases cannot be skipped")
+}
+
+func TestMigrationStateRouting(t *testing.T) {
+	// The zero value behaves like a migration that has not started
+	var zero MigrationState
+	require.Equal(t, MigrationStateNotStarted, zero.Normalize())
+	require.True(t, zero.Valid())
+	require.True(t, zero.WritesLegacy())
+	require.False(t, zero.WritesShard())
+	require.False(t, zero.ReadsShard())
+
+	require.True(t, MigrationStateVerifying.WritesLegacy())
+	require.True(t, MigrationStateVerifying.WritesShard())
+	require.True(t, MigrationStateVerifying.ShadowReads())
+
+	require.True(t, MigrationStateReadFromNew.WritesLegacy())
+	require.True(t, MigrationStateReadFromNew.ReadsShard())
+	require.False(t, MigrationStateReadFromNew.ShadowReads())
+
+	require.False(t, MigrationStateDone.WritesLegacy())
+	require.True(t, MigrationStateDone.ReadsShard())
+
+	require.False(t, MigrationState("unknown").Valid())
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -20,7 +21,7 @@ type sqliteAccountStore struct {
 }
 
 func (s *sqliteAccountStore) ListAccounts(ctx context.Context) ([]Account, error) {
-	query := `SELECT id, name, is_migrating, shard FROM accounts`
+	query := `SELECT id, name, shard, migration_state, migration_updated_at FROM accounts`
 
 	var rows *sql.Rows
 	err := util.Retry(ctx, defaultRetryConfig, func() error {
@@ -38,11 +39,13 @@ func (s *sqliteAccountStore) ListAccounts(ctx context.Context) ([]Account, error
 		var account Account
 		var idStr string
 		var shardStr *string
-		err := rows.Scan(&idStr, &account.Name, &account.IsMigrating, &shardStr)
+		var migrationUpdatedAtMillis sql.NullInt64
+		err := rows.Scan(&idStr, &account.Name, &shardStr, &account.MigrationState, &migrationUpdatedAtMillis)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan account: %w", err)
 		}
 		account.Shard = shardStr
+		account.MigrationUpdatedAt = timeFromNullMillis(migrationUpdatedAtMillis)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan account: %w", err)
 		}
@@ -63,14 +66,15 @@ func (s *sqliteAccountStore) ListAccounts(ctx context.Context) ([]Account, error
 }
 
 func (s *sqliteAccountStore) GetAccount(ctx context.Context, accountID uuid.UUID) (*Account, error) {
-	query := `SELECT id, name, is_migrating, shard FROM accounts WHERE id = ?`
+	query := `SELECT id, name, shard, migration_state, migration_updated_at FROM accounts WHERE id = ?`
 
 	var account Account
 	var idStr string
 	var shardStr *string
+	var migrationUpdatedAtMillis sql.NullInt64
 	err := util.Retry(ctx, defaultRetryConfig, func() error {
 		row := s.db.QueryRowContext(ctx, query, accountID.String())
-		return row.Scan(&idStr, &account.Name, &account.IsMigrating, &shardStr)
+		return row.Scan(&idStr, &account.Name, &shardStr, &account.MigrationState, &migrationUpdatedAtMillis)
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -84,6 +88,7 @@ func (s *sqliteAccountStore) GetAccount(ctx context.Context, accountID uuid.UUID
 		return nil, fmt.Errorf("failed to parse account ID: %w", err)
 	}
 	account.Shard = shardStr
+	account.MigrationUpdatedAt = timeFromNullMillis(migrationUpdatedAtMillis)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrAccountNotFound
@@ -100,10 +105,14 @@ func (s *sqliteAccountStore) GetAccount(ctx context.Context, accountID uuid.UUID
 }
 
 func (s *sqliteAccountStore) CreateAccount(ctx context.Context, a Account) error {
-	query := `INSERT INTO accounts (id, name, is_migrating, shard) VALUES (?, ?, ?, ?)`
+	query := `INSERT INTO accounts (id, name, shard, migration_state, migration_updated_at) VALUES (?, ?, ?, ?, ?)`
+
+	if !a.MigrationState.Valid() {
+		return fmt.Errorf("%w: unknown state %q", ErrInvalidMigrationTransition, a.MigrationState)
+	}
 
 	err := util.Retry(ctx, defaultRetryConfig, func() error {
-		_, execErr := s.db.ExecContext(ctx, query, a.ID.String(), a.Name, a.IsMigrating, a.Shard)
+		_, execErr := s.db.ExecContext(ctx, query, a.ID.String(), a.Name, a.Shard, string(a.MigrationState.Normalize()), nullMillisFromTime(a.MigrationUpdatedAt))
 		return execErr
 	})
 	if err != nil {
@@ -112,13 +121,15 @@ func (s *sqliteAccountStore) CreateAccount(ctx context.Context, a Account) error
 	return nil
 }
 
+// UpdateAccount updates the name and shard of an account.
+// The migration state can only be changed through TransitionMigrationState.
 func (s *sqliteAccountStore) UpdateAccount(ctx context.Context, a Account) error {
-	query := `UPDATE accounts SET name = ?, is_migrating = ?, shard = ? WHERE id = ?`
+	query := `UPDATE accounts SET name = ?, shard = ? WHERE id = ?`
 
 	var result sql.Result
 	err := util.Retry(ctx, defaultRetryConfig, func() error {
 		var execErr error
-		result, execErr = s.db.ExecContext(ctx, query, a.Name, a.IsMigrating, a.Shard, a.ID.String())
+		result, execErr = s.db.ExecContext(ctx, query, a.Name, a.Shard, a.ID.String())
 		return execErr
 	})
 	if err != nil {
@@ -142,16 +153,143 @@ func (s *sqliteAccountStore) HealthCheck(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
+// TransitionMigrationState moves an account to the target migration state and records the transition.
+// Only advancing or rolling back a single phase is allowed.
+func (s *sqliteAccountStore) TransitionMigrationState(ctx context.Context, accountID uuid.UUID, target MigrationState) (*Account, error) {
+	err := util.Retry(ctx, defaultRetryConfig, func() error {
+		return withTx(ctx, s.db, func(tx *sql.Tx) error {
+			var current MigrationState
+			row := tx.QueryRowContext(ctx, `SELECT migration_state FROM accounts WHERE id = ?`, accountID.String())
+			if err := row.Scan(&current); err != nil {
+				if err == sql.ErrNoRows {
+					return ErrAccountNotFound
+				}
+				return err
+			}
+
+			if !current.CanTransitionTo(target) {
+				return fmt.Errorf("%w: %s to %s", ErrInvalidMigrationTransition, current.Normalize(), target)
+			}
+
+			now := time.Now().UnixMilli()
+
+			update := `UPDATE accounts SET migration_state = ?, migration_updated_at = ? WHERE id = ?`
+			if _, err := tx.ExecContext(ctx, update, string(target), now, accountID.String()); err != nil {
+				return err
+			}
+
+			insert := `INSERT INTO account_migration_transitions (account_id, from_state, to_state, transitioned_at) VALUES (?, ?, ?, ?)`
+			_, err := tx.ExecContext(ctx, insert, accountID.String(), string(current.Normalize()), string(target), now)
+			return err
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrInvalidMigrationTransition) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to transition migration state: %w", err)
+	}
+
+	return s.GetAccount(ctx, accountID)
+}
+
+// RevertMigrationState moves an account that is in the failed state back to the state it was in before.
+// Unlike TransitionMigrationState, this is allowed for any pair of states, as the failed transition never took effect.
+func (s *sqliteAccountStore) RevertMigrationState(ctx context.Context, accountID uuid.UUID, failed, restored MigrationState) (*Account, error) {
+	err := util.Retry(ctx, defaultRetryConfig, func() error {
+		return withTx(ctx, s.db, func(tx *sql.Tx) error {
+			var current MigrationState
+			row := tx.QueryRowContext(ctx, `SELECT migration_state FROM accounts WHERE id = ?`, accountID.String())
+			if err := row.Scan(&current); err != nil {
+				if err == sql.ErrNoRows {
+					return ErrAccountNotFound
+				}
+				return err
+			}
+
+			if current.Normalize() != failed {
+				return fmt.Errorf("%w: cannot revert %s, account is in %s", ErrInvalidMigrationTransition, failed, current.Normalize())
+			}
+
+			now := time.Now().UnixMilli()
+
+			update := `UPDATE accounts SET migration_state = ?, migration_updated_at = ? WHERE id = ?`
+			if _, err := tx.ExecContext(ctx, update, string(restored), now, accountID.String()); err != nil {
+				return err
+			}
+
+			insert := `INSERT INTO account_migration_transitions (account_id, from_state, to_state, transitioned_at) VALUES (?, ?, ?, ?)`
+			_, err := tx.ExecContext(ctx, insert, accountID.String(), string(failed), string(restored), now)
+			return err
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrInvalidMigrationTransition) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to revert migration state: %w", err)
+	}
+
+	return s.GetAccount(ctx, accountID)
+}
+
+// ListMigrationTransitions returns the migration history of an account, oldest first
+func (s *sqliteAccountStore) ListMigrationTransitions(ctx context.Context, accountID uuid.UUID) ([]MigrationTransition, error) {
+	query := `SELECT from_state, to_state, transitioned_at FROM account_migration_transitions WHERE account_id = ? ORDER BY id`
+
+	var rows *sql.Rows
+	err := util.Retry(ctx, defaultRetryConfig, func() error {
+		var queryErr error
+		rows, queryErr = s.db.QueryContext(ctx, query, accountID.String())
+		return queryErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migration transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []MigrationTransition
+	for rows.Next() {
+		transition := MigrationTransition{AccountID: accountID}
+		var transitionedAtMillis int64
+		if err := rows.Scan(&transition.From, &transition.To, &transitionedAtMillis); err != nil {
+			return nil, fmt.Errorf("failed to scan migration transition: %w", err)
+		}
+		transition.At = time.UnixMilli(transitionedAtMillis)
+		transitions = append(transitions, transition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return transitions, nil
+}
+
 type sqliteNoteStore struct {
+	name   string
 	logger *slog.Logger
 	db     *sql.DB
+	faults FaultInjector // Optional, fails query attempts on purpose
+}
+
+// retry runs a query with the default retry configuration, letting the fault injector fail attempts first
+func (s *sqliteNoteStore) retry(ctx context.Context, operation func() error) error {
+	return util.Retry(ctx, defaultRetryConfig, func() error {
+		if s.faults != nil {
+			if err := s.faults.StoreFault(s.name); err != nil {
+				return err
+			}
+		}
+		return operation()
+	})
 }
 
 func (s *sqliteNoteStore) ListNotes(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
 	query := `SELECT id FROM notes WHERE creator = ?`
 
 	var rows *sql.Rows
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
+	err := s.retry(ctx, func() error {
 		var queryErr error
 		rows, queryErr = s.db.QueryContext(ctx, query, accountID.String())
 		return queryErr
@@ -191,7 +329,7 @@ func (s *sqliteNoteStore) GetNote(ctx context.Context, accountID, noteID uuid.UU
 	var idStr, creatorStr string
 	var createdAtMillis, updatedAtMillis int64
 
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
+	err := s.retry(ctx, func() error {
 		row := s.db.QueryRowContext(ctx, query, noteID.String(), accountID.String())
 		return row.Scan(&idStr, &creatorStr, &createdAtMillis, &updatedAtMillis, &note.Content)
 	})
@@ -221,15 +359,20 @@ func (s *sqliteNoteStore) GetNote(ctx context.Context, accountID, noteID uuid.UU
 func (s *sqliteNoteStore) CreateNote(ctx context.Context, accountID uuid.UUID, note Note) error {
 	query := `INSERT INTO notes (id, creator, created_at, updated_at, content) VALUES (?, ?, ?, ?, ?)`
 
-	s.logger.Debug("creating note",
+	s.logger.DebugContext(ctx, "creating note",
 		"id", note.ID.String(),
 		"created_at", note.CreatedAt.Format(time.StampMilli),
 		"creator", note.Creator.String(),
 	)
 
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
-		_, execErr := s.db.ExecContext(ctx, query, note.ID.String(), accountID.String(), note.CreatedAt.UnixMilli(), note.UpdatedAt.UnixMilli(), note.Content)
-		return execErr
+	err := s.retry(ctx, func() error {
+		return withTx(ctx, s.db, func(tx *sql.Tx) error {
+			_, execErr := tx.ExecContext(ctx, query, note.ID.String(), accountID.String(), note.CreatedAt.UnixMilli(), note.UpdatedAt.UnixMilli(), note.Content)
+			if execErr != nil {
+				return execErr
+			}
+			return recordNoteChange(ctx, tx, ChangeOperationCreate, accountID, note.ID)
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create note: %w", err)
@@ -240,25 +383,33 @@ func (s *sqliteNoteStore) CreateNote(ctx context.Context, accountID uuid.UUID, n
 func (s *sqliteNoteStore) UpdateNote(ctx context.Context, accountID uuid.UUID, note Note) error {
 	query := `UPDATE notes SET content = ?, updated_at = ? WHERE id = ? AND creator = ? AND updated_at < ?`
 
-	s.logger.Debug("updating note",
+	s.logger.DebugContext(ctx, "updating note",
 		"id", note.ID.String(),
 		"updated_at", note.UpdatedAt.Format(time.StampMilli),
 		"creator", note.Creator.String(),
 	)
 
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
-		var execErr error
-		_, execErr = s.db.ExecContext(ctx, query,
-			note.Content,
-			note.UpdatedAt.UnixMilli(),
-			note.ID.String(),
-			accountID.String(),
-			note.UpdatedAt.UnixMilli(),
-		)
-		if execErr != nil {
-			s.logger.Error("received exec error after update", "error", execErr)
-		}
-		return execErr
+	err := s.retry(ctx, func() error {
+		return withTx(ctx, s.db, func(tx *sql.Tx) error {
+			result, execErr := tx.ExecContext(ctx, query,
+				note.Content,
+				note.UpdatedAt.UnixMilli(),
+				note.ID.String(),
+				accountID.String(),
+				note.UpdatedAt.UnixMilli(),
+			)
+			if execErr != nil {
+				s.logger.ErrorContext(ctx, "received exec error after update", "error", execErr)
+				return execErr
+			}
+
+			// Stale updates are dropped by the query above and must not show up as changes
+			rowsAffected, execErr := result.RowsAffected()
+			if execErr != nil || rowsAffected == 0 {
+				return execErr
+			}
+			return recordNoteChange(ctx, tx, ChangeOperationUpdate, accountID, note.ID)
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update note: %w", err)
@@ -270,10 +421,20 @@ func (s *sqliteNoteStore) UpdateNote(ctx context.Context, accountID uuid.UUID, n
 func (s *sqliteNoteStore) DeleteNote(ctx context.Context, accountID uuid.UUID, note Note) error {
 	query := `DELETE FROM notes WHERE id = ? AND creator = ?`
 
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
-		var execErr error
-		_, execErr = s.db.ExecContext(ctx, query, note.ID.String(), accountID.String())
-		return execErr
+	err := s.retry(ctx, func() error {
+		return withTx(ctx, s.db, func(tx *sql.Tx) error {
+			result, execErr := tx.ExecContext(ctx, query, note.ID.String(), accountID.String())
+			if execErr != nil {
+				return execErr
+			}
+
+			// Deleting a missing note is a no-op and must not show up as a change
+			rowsAffected, execErr := result.RowsAffected()
+			if execErr != nil || rowsAffected == 0 {
+				return execErr
+			}
+			return recordNoteChange(ctx, tx, ChangeOperationDelete, accountID, note.ID)
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete note: %w", err)
@@ -286,7 +447,7 @@ func (s *sqliteNoteStore) CountNotes(ctx context.Context, accountID uuid.UUID) (
 	query := `SELECT COUNT(*) FROM notes WHERE creator = ?`
 
 	var count int
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
+	err := s.retry(ctx, func() error {
 		return s.db.QueryRowContext(ctx, query, accountID.String()).Scan(&count)
 	})
 	if err != nil {
@@ -299,7 +460,7 @@ func (s *sqliteNoteStore) GetTotalNotes(ctx context.Context) (int, error) {
 	query := `SELECT COUNT(*) FROM notes`
 
 	var count int
-	err := util.Retry(ctx, defaultRetryConfig, func() error {
+	err := s.retry(ctx, func() error {
 		return s.db.QueryRowContext(ctx, query).Scan(&count)
 	})
 	if err != nil {
@@ -313,6 +474,171 @@ func (s *sqliteNoteStore) HealthCheck(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
+// ScanNotes implements the NoteScanner interface for sqliteNoteStore
+func (s *sqliteNoteStore) ScanNotes(ctx context.Context, fn func(note Note) error) error {
+	query := `SELECT id, creator, created_at, updated_at, content FROM notes ORDER BY creator, id`
+
+	var rows *sql.Rows
+	err := s.retry(ctx, func() error {
+		var queryErr error
+		rows, queryErr = s.db.QueryContext(ctx, query)
+		return queryErr
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query notes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var note Note
+		var idStr, creatorStr string
+		var createdAtMillis, updatedAtMillis int64
+		if err := rows.Scan(&idStr, &creatorStr, &createdAtMillis, &updatedAtMillis, &note.Content); err != nil {
+			return fmt.Errorf("failed to scan note: %w", err)
+		}
+
+		note.ID, err = uuid.Parse(idStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse note ID: %w", err)
+		}
+
+		note.Creator, err = uuid.Parse(creatorStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse creator ID: %w", err)
+		}
+
+		note.CreatedAt = time.UnixMilli(createdAtMillis)
+		note.UpdatedAt = time.UnixMilli(updatedAtMillis)
+
+		if err := fn(note); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// ListChanges implements the ChangeFeed interface for sqliteNoteStore
+func (s *sqliteNoteStore) ListChanges(ctx context.Context, accountID uuid.UUID, afterSeq int64, limit int) ([]NoteChange, error) {
+	query := `SELECT seq, operation, note_id, account_id, created_at, updated_at, content, changed_at
+	FROM note_changes WHERE account_id = ? AND seq > ? ORDER BY seq LIMIT ?`
+
+	var rows *sql.Rows
+	err := s.retry(ctx, func() error {
+		var queryErr error
+		rows, queryErr = s.db.QueryContext(ctx, query, accountID.String(), afterSeq, limit)
+		return queryErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query note changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []NoteChange
+	for rows.Next() {
+		var change NoteChange
+		var operation, noteIDStr, accountIDStr string
+		var createdAtMillis, updatedAtMillis sql.NullInt64
+		var content sql.NullString
+		var changedAtMillis int64
+
+		err := rows.Scan(&change.Seq, &operation, &noteIDStr, &accountIDStr, &createdAtMillis, &updatedAtMillis, &content, &changedAtMillis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan note change: %w", err)
+		}
+
+		change.Operation = ChangeOperation(operation)
+		change.ChangedAt = time.UnixMilli(changedAtMillis)
+
+		change.NoteID, err = uuid.Parse(noteIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse note ID: %w", err)
+		}
+
+		change.AccountID, err = uuid.Parse(accountIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse account ID: %w", err)
+		}
+
+		if change.Operation != ChangeOperationDelete {
+			change.Note = &Note{
+				ID:        change.NoteID,
+				Creator:   change.AccountID,
+				CreatedAt: time.UnixMilli(createdAtMillis.Int64),
+				UpdatedAt: time.UnixMilli(updatedAtMillis.Int64),
+				Content:   content.String,
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return changes, nil
+}
+
+// withTx runs fn in a transaction, committing if fn succeeds and rolling back otherwise
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// recordNoteChange appends a change to the outbox as part of the surrounding write transaction.
+// For creates and updates, the note is captured as it is stored after the write.
+func recordNoteChange(ctx context.Context, tx *sql.Tx, operation ChangeOperation, accountID, noteID uuid.UUID) error {
+	changedAt := time.Now().UnixMilli()
+
+	if operation == ChangeOperationDelete {
+		query := `INSERT INTO note_changes (operation, note_id, account_id, changed_at) VALUES (?, ?, ?, ?)`
+		_, err := tx.ExecContext(ctx, query, string(operation), noteID.String(), accountID.String(), changedAt)
+		return err
+	}
+
+	query := `INSERT INTO note_changes (operation, note_id, account_id, created_at, updated_at, content, changed_at)
+	SELECT ?, id, creator, created_at, updated_at, content, ? FROM notes WHERE id = ? AND creator = ?`
+	_, err := tx.ExecContext(ctx, query, string(operation), changedAt, noteID.String(), accountID.String())
+	return err
+}
+
+// SnapshotTo implements the Snapshotter interface for sqliteAccountStore
+func (s *sqliteAccountStore) SnapshotTo(ctx context.Context, path string) error {
+	return vacuumInto(ctx, s.db, path)
+}
+
+// SnapshotTo implements the Snapshotter interface for sqliteNoteStore
+func (s *sqliteNoteStore) SnapshotTo(ctx context.Context, path string) error {
+	return vacuumInto(ctx, s.db, path)
+}
+
+// vacuumInto writes a transactionally consistent copy of the database to path, which must not exist yet.
+// See https://www.sqlite.org/lang_vacuum.html#vacuuminto
+func vacuumInto(ctx context.Context, db *sql.DB, path string) error {
+	err := util.Retry(ctx, defaultRetryConfig, func() error {
+		_, err := db.ExecContext(ctx, "VACUUM INTO ?", path)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to vacuum into %s: %w", path, err)
+	}
+	return nil
+}
+
 // Close implements the Store interface for sqliteAccountStore
 func (s *sqliteAccountStore) Close() error {
 	return s.db.Close()
@@ -329,6 +655,7 @@ type StoreOptions struct {
 	BasePath string
 	Config   DatabaseConfig
 	Logger   *slog.Logger
+	Faults   FaultInjector // Fails note store queries on purpose, nil to inject no faults
 }
 
 // DefaultStoreOptions returns sensible defaults for store creation
@@ -351,11 +678,20 @@ func NewAccountStore(opts StoreOptions) (AccountStore, error) {
 		return nil, fmt.Errorf("could not create sqlite db: %w", err)
 	}
 
+	if opts.Config.ReadOnly {
+		return &sqliteAccountStore{db}, nil
+	}
+
 	if err := createAccountsTable(db); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("could not create accounts table: %w", err)
 	}
 
+	if err := createMigrationTransitionsTable(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create migration transitions table: %w", err)
+	}
+
 	return &sqliteAccountStore{db}, nil
 }
 
@@ -370,14 +706,30 @@ func NewNoteStore(opts StoreOptions) (NoteStore, error) {
 		return nil, fmt.Errorf("could not create sqlite db: %w", err)
 	}
 
+	if opts.Config.ReadOnly {
+		return &sqliteNoteStore{
+			name:   opts.Name,
+			logger: logger,
+			db:     db,
+			faults: opts.Faults,
+		}, nil
+	}
+
 	if err := createNotesTable(db); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("could not create notes table: %w", err)
 	}
 
+	if err := createNoteChangesTable(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create note changes table: %w", err)
+	}
+
 	return &sqliteNoteStore{
+		name:   opts.Name,
 		logger: logger,
 		db:     db,
+		faults: opts.Faults,
 	}, nil
 }
 
@@ -395,19 +747,138 @@ func createNotesTable(db *sql.DB) error {
 	return err
 }
 
+// createNoteChangesTable creates the outbox capturing every note write.
+// AUTOINCREMENT guarantees sequence numbers are never reused, so consumers can resume safely.
+func createNoteChangesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS note_changes (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		operation TEXT NOT NULL,
+		note_id TEXT NOT NULL,
+		account_id TEXT NOT NULL,
+		created_at INTEGER,
+		updated_at INTEGER,
+		content TEXT,
+		changed_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS note_changes_account_seq ON note_changes (account_id, seq);`
+
+	_, err := db.Exec(query)
+	return err
+}
+
 func createAccountsTable(db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS accounts (
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
-		is_migrating BOOLEAN NOT NULL DEFAULT 0,
-		shard TEXT
+		shard TEXT,
+		migration_state TEXT NOT NULL DEFAULT 'not_started',
+		migration_updated_at INTEGER
 	);`
 
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	// Account databases created before migration states lack these columns
+	columns := []struct{ name, definition string }{
+		{"migration_state", "TEXT NOT NULL DEFAULT 'not_started'"},
+		{"migration_updated_at", "INTEGER"},
+	}
+	addedMigrationState := false
+	for _, column := range columns {
+		added, err := addColumnIfMissing(db, "accounts", column.name, column.definition)
+		if err != nil {
+			return fmt.Errorf("could not add column %q: %w", column.name, err)
+		}
+		if column.name == "migration_state" {
+			addedMigrationState = added
+		}
+	}
+
+	// Accounts flagged as migrating before migration states keep mirroring writes to their shard
+	if addedMigrationState {
+		hasFlag, err := hasColumn(db, "accounts", "is_migrating")
+		if err != nil {
+			return fmt.Errorf("could not check for column %q: %w", "is_migrating", err)
+		}
+		if hasFlag {
+			update := `UPDATE accounts SET migration_state = ? WHERE is_migrating = 1`
+			if _, err := db.Exec(update, string(MigrationStateDualWrite)); err != nil {
+				return fmt.Errorf("could not carry over migrating accounts: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func createMigrationTransitionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS account_migration_transitions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL,
+		from_state TEXT NOT NULL,
+		to_state TEXT NOT NULL,
+		transitioned_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS account_migration_transitions_account ON account_migration_transitions (account_id, id);`
+
 	_, err := db.Exec(query)
 	return err
 }
 
+// addColumnIfMissing adds a column to an existing table unless it is already present, reporting whether it was added
+func addColumnIfMissing(db *sql.DB, table, column, definition string) (bool, error) {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return false, err
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// hasColumn reports whether a table has a column
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// nullMillisFromTime converts an optional timestamp to nullable epoch milliseconds
+func nullMillisFromTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+}
+
+// timeFromNullMillis converts nullable epoch milliseconds to an optional timestamp
+func timeFromNullMillis(millis sql.NullInt64) *time.Time {
+	if !millis.Valid {
+		return nil
+	}
+	t := time.UnixMilli(millis.Int64)
+	return &t
+}
+
 func createSQLiteDatabaseWithPath(name, basePath string, config DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
 	var dir string
 	if basePath != "" {
@@ -420,6 +891,23 @@ func createSQLiteDatabaseWithPath(name, basePath string, config DatabaseConfig,
 		dir = filepath.Join(wd, ".data")
 	}
 
+	file := filepath.Join(dir, fmt.Sprintf("%s.db", name))
+
+	if config.ReadOnly {
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, file)
+		}
+
+		db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", file))
+		if err != nil {
+			return nil, fmt.Errorf("could not open sqlite db: %w", err)
+		}
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+		return db, nil
+	}
+
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		err := os.MkdirAll(dir, 0750)
 		if err != nil {
@@ -427,8 +915,6 @@ func createSQLiteDatabaseWithPath(name, basePath string, config DatabaseConfig,
 		}
 	}
 
-	file := filepath.Join(dir, fmt.Sprintf("%s.db", name))
-
 	// Configure SQLite for multi-process access with WAL mode and timeouts
 	dsn := fmt.Sprintf("file:%s", file)
 
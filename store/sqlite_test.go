@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -445,6 +446,50 @@ func TestRetryLogic(t *testing.T) {
 	t.Logf("Retry logic test passed: %d concurrent operations completed successfully", numOperations)
 }
 
+// busyFaults fails the given number of query attempts with a SQLITE_BUSY error
+type busyFaults struct {
+	mu        sync.Mutex
+	remaining int
+	stores    []string
+}
+
+func (f *busyFaults) StoreFault(store string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stores = append(f.stores, store)
+	if f.remaining == 0 {
+		return nil
+	}
+	f.remaining--
+	return errors.New("database is locked (SQLITE_BUSY)")
+}
+
+func TestInjectedStoreFaults(t *testing.T) {
+	faults := &busyFaults{remaining: 2}
+	noteStore, err := NewNoteStore(StoreOptions{
+		Name:     "test_faults",
+		BasePath: getGlobalTempDir(),
+		Config:   DefaultDatabaseConfig(),
+		Faults:   faults,
+	})
+	require.NoError(t, err)
+	defer noteStore.Close()
+
+	ctx := context.Background()
+	accountID := uuid.New()
+	note := Note{ID: uuid.New(), Creator: accountID, CreatedAt: time.Now(), UpdatedAt: time.Now(), Content: "busy"}
+
+	// Injected faults are retried like real busy errors
+	require.NoError(t, noteStore.CreateNote(ctx, accountID, note))
+	require.Equal(t, []string{"test_faults", "test_faults", "test_faults"}, faults.stores)
+
+	// Once the retries are exhausted, the query fails with the injected error
+	faults.remaining = defaultRetryConfig.MaxRetries + 1
+	_, err = noteStore.GetNote(ctx, accountID, note.ID)
+	require.ErrorContains(t, err, "SQLITE_BUSY")
+}
+
 func TestReadAfterWriteConsistency(t *testing.T) {
 	// Create two separate store instances accessing the same database
 	accountStore1, noteStore1, _ := setupTestStores(t, "test_consistency")
@@ -1045,3 +1090,117 @@ func TestSQLiteContentionReads(t *testing.T) {
 	// Verify some reads succeeded despite contention
 	require.Greater(t, successfulReads, 0, "Expected at least some reads to succeed")
 }
+
+func TestTransitionMigrationState(t *testing.T) {
+	accountStore, _, _ := setupTestStores(t, "test_account_migration_state")
+	defer accountStore.Close()
+
+	ctx := context.Background()
+
+	account := Account{ID: uuid.New(), Name: "migrating"}
+	require.NoError(t, accountStore.CreateAccount(ctx, account))
+
+	stored, err := accountStore.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateNotStarted, stored.MigrationState)
+	require.Nil(t, stored.MigrationUpdatedAt)
+
+	// Phases cannot be skipped
+	_, err = accountStore.TransitionMigrationState(ctx, account.ID, MigrationStateBackfilling)
+	require.ErrorIs(t, err, ErrInvalidMigrationTransition)
+
+	updated, err := accountStore.TransitionMigrationState(ctx, account.ID, MigrationStateDualWrite)
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateDualWrite, updated.MigrationState)
+	require.NotNil(t, updated.MigrationUpdatedAt)
+
+	updated, err = accountStore.TransitionMigrationState(ctx, account.ID, MigrationStateRollingBack)
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateRollingBack, updated.MigrationState)
+
+	// Updating the account must not touch the migration state
+	require.NoError(t, accountStore.UpdateAccount(ctx, Account{ID: account.ID, Name: "renamed"}))
+	stored, err = accountStore.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateRollingBack, stored.MigrationState)
+
+	transitions, err := accountStore.ListMigrationTransitions(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	require.Equal(t, MigrationStateNotStarted, transitions[0].From)
+	require.Equal(t, MigrationStateDualWrite, transitions[0].To)
+	require.Equal(t, MigrationStateDualWrite, transitions[1].From)
+	require.Equal(t, MigrationStateRollingBack, transitions[1].To)
+
+	_, err = accountStore.TransitionMigrationState(ctx, uuid.New(), MigrationStateDualWrite)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestRevertMigrationState(t *testing.T) {
+	accountStore, _, _ := setupTestStores(t, "test_account_migration_revert")
+	defer accountStore.Close()
+
+	ctx := context.Background()
+
+	account := Account{ID: uuid.New(), Name: "reverted"}
+	require.NoError(t, accountStore.CreateAccount(ctx, account))
+	_, err := accountStore.TransitionMigrationState(ctx, account.ID, MigrationStateDualWrite)
+	require.NoError(t, err)
+	_, err = accountStore.TransitionMigrationState(ctx, account.ID, MigrationStateRollingBack)
+	require.NoError(t, err)
+
+	// Rolling back cannot be undone by a transition, but a failed rollback can be reverted
+	reverted, err := accountStore.RevertMigrationState(ctx, account.ID, MigrationStateRollingBack, MigrationStateDualWrite)
+	require.NoError(t, err)
+	require.Equal(t, MigrationStateDualWrite, reverted.MigrationState)
+
+	// Only the state that failed can be reverted
+	_, err = accountStore.RevertMigrationState(ctx, account.ID, MigrationStateRollingBack, MigrationStateDualWrite)
+	require.ErrorIs(t, err, ErrInvalidMigrationTransition)
+
+	transitions, err := accountStore.ListMigrationTransitions(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, transitions, 3)
+	require.Equal(t, MigrationStateRollingBack, transitions[2].From)
+	require.Equal(t, MigrationStateDualWrite, transitions[2].To)
+
+	_, err = accountStore.RevertMigrationState(ctx, uuid.New(), MigrationStateDualWrite, MigrationStateNotStarted)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestCreateAccountsTableAddsMissingColumns(t *testing.T) {
+	db, err := createSQLiteDatabaseWithPath("test-accounts-upgrade", t.TempDir(), DatabaseConfig{
+		MaxOpenConns:    1,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Second,
+		EnableWAL:       true,
+	}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Accounts table as created before migration states
+	_, err = db.Exec(`CREATE TABLE accounts (id TEXT PRIMARY KEY, name TEXT NOT NULL, is_migrating BOOLEAN NOT NULL DEFAULT 0, shard TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO accounts (id, name) VALUES (?, ?)`, uuid.New().String(), "existing")
+	require.NoError(t, err)
+	migratingID := uuid.New()
+	_, err = db.Exec(`INSERT INTO accounts (id, name, is_migrating, shard) VALUES (?, ?, 1, ?)`, migratingID.String(), "migrating", "new")
+	require.NoError(t, err)
+
+	require.NoError(t, createAccountsTable(db))
+	// Running the upgrade again must be a no-op
+	require.NoError(t, createAccountsTable(db))
+
+	accounts, err := (&sqliteAccountStore{db}).ListAccounts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	for _, account := range accounts {
+		// Accounts that were migrating keep mirroring writes to their shard
+		if account.ID == migratingID {
+			require.Equal(t, MigrationStateDualWrite, account.MigrationState)
+		} else {
+			require.Equal(t, MigrationStateNotStarted, account.MigrationState)
+		}
+		require.Nil(t, account.MigrationUpdatedAt)
+	}
+}
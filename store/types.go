@@ -10,10 +10,14 @@ import (
 )
 
 type Account struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	IsMigrating bool      `json:"isMigrating"`
-	Shard       *string   `json:"shard,omitempty"`
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Shard *string   `json:"shard,omitempty"`
+
+	// MigrationState is the migration phase of the account's notes
+	MigrationState MigrationState `json:"migrationState"`
+	// MigrationUpdatedAt is the time the account entered its current migration state
+	MigrationUpdatedAt *time.Time `json:"migrationUpdatedAt,omitempty"`
 }
 
 type Note struct {
@@ -35,6 +39,14 @@ type AccountStore interface {
 	GetAccount(ctx context.Context, accountID uuid.UUID) (*Account, error)
 	CreateAccount(ctx context.Context, a Account) error
 	UpdateAccount(ctx context.Context, a Account) error
+
+	// TransitionMigrationState advances or rolls back the migration state of an account by one phase.
+	// Invalid transitions return ErrInvalidMigrationTransition.
+	TransitionMigrationState(ctx context.Context, accountID uuid.UUID, target MigrationState) (*Account, error)
+	// RevertMigrationState restores the previous state of an account whose transition to the failed state could not be applied.
+	// Accounts no longer in the failed state return ErrInvalidMigrationTransition.
+	RevertMigrationState(ctx context.Context, accountID uuid.UUID, failed, restored MigrationState) (*Account, error)
+	ListMigrationTransitions(ctx context.Context, accountID uuid.UUID) ([]MigrationTransition, error)
 	HealthCheck(ctx context.Context) error
 	io.Closer
 }
@@ -56,10 +68,27 @@ type NoteStore interface {
 	io.Closer
 }
 
+// NoteScanner iterates over all notes of a store, regardless of account
+type NoteScanner interface {
+	ScanNotes(ctx context.Context, fn func(note Note) error) error
+}
+
+// Snapshotter writes a consistent copy of a store's database to a new file
+type Snapshotter interface {
+	SnapshotTo(ctx context.Context, path string) error
+}
+
+// FaultInjector fails note store queries on purpose, e.g. during chaos experiments.
+// StoreFault is called before every attempt of a query and fails the attempt with the returned error, if any.
+type FaultInjector interface {
+	StoreFault(store string) error
+}
+
 // Custom error types for better error handling
 var (
-	ErrAccountNotFound = errors.New("account not found")
-	ErrNoteNotFound    = errors.New("note not found")
+	ErrAccountNotFound  = errors.New("account not found")
+	ErrNoteNotFound     = errors.New("note not found")
+	ErrDatabaseNotFound = errors.New("database not found")
 )
 
 // DatabaseConfig holds database connection configuration
@@ -68,6 +97,9 @@ type DatabaseConfig struct {
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	EnableWAL       bool
+
+	// ReadOnly opens an existing database without creating files, tables or changing pragmas
+	ReadOnly bool
 }
 
 // DefaultDatabaseConfig returns sensible defaults for database configuration
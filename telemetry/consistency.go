@@ -0,0 +1,31 @@
+package telemetry
+
+import "time"
+
+// ConsistencyMissBufferSize is the number of recent consistency misses kept for inspection
+const ConsistencyMissBufferSize = 500
+
+// ConsistencyCheck identifies which check of the simulator detected a consistency miss
+type ConsistencyCheck string
+
+const (
+	ConsistencyCheckRead           ConsistencyCheck = "read"             // A note read back differs from what was written
+	ConsistencyCheckListMissing    ConsistencyCheck = "list_missing"     // A written note is missing from the list of notes
+	ConsistencyCheckListMismatch   ConsistencyCheck = "list_mismatch"    // A listed note differs from what was written
+	ConsistencyCheckReadYourWrites ConsistencyCheck = "read_your_writes" // A note read right after writing it differs or is missing
+	ConsistencyCheckMonotonicRead  ConsistencyCheck = "monotonic_read"   // A note read returned an older version than a previous read
+	ConsistencyCheckCrossAccount   ConsistencyCheck = "cross_account"    // An account read a note created by another account
+)
+
+// ConsistencyMiss describes a single consistency miss detected by the simulator
+type ConsistencyMiss struct {
+	Time         time.Time        `json:"time"`
+	AccountID    string           `json:"accountId"`
+	NoteID       string           `json:"noteId"`
+	Check        ConsistencyCheck `json:"check"`
+	ExpectedHash string           `json:"expectedHash"`
+	ActualHash   string           `json:"actualHash,omitempty"` // Empty if the note was missing
+	ProxyID      int              `json:"proxyId,omitempty"`    // Proxy version that served the request, zero if unknown
+	RequestID    string           `json:"requestId,omitempty"`
+	Detail       string           `json:"detail,omitempty"` // What the check observed beyond the hashes, e.g. the timestamps of a monotonic read miss
+}
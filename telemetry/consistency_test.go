@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackConsistencyMiss(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	require.Empty(t, collector.RecentConsistencyMisses())
+
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{
+		AccountID:    "account-1",
+		NoteID:       "note-1",
+		Check:        ConsistencyCheckRead,
+		ExpectedHash: "abc",
+		ActualHash:   "def",
+		ProxyID:      2,
+		RequestID:    "req-1",
+	}))
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{AccountID: "account-1", NoteID: "note-2", Check: ConsistencyCheckListMissing}))
+
+	require.Equal(t, 2, collector.Export().ConsistencyMisses)
+	require.Equal(t, map[ConsistencyCheck]int{ConsistencyCheckRead: 1, ConsistencyCheckListMissing: 1}, collector.Export().MissesByCheck)
+
+	misses := collector.RecentConsistencyMisses()
+	require.Len(t, misses, 2)
+	require.Equal(t, "note-2", misses[0].NoteID, "Expected newest miss first")
+	require.Equal(t, ConsistencyCheckRead, misses[1].Check)
+	require.Equal(t, 2, misses[1].ProxyID)
+	require.False(t, misses[1].Time.IsZero(), "Expected time to default to now")
+}
+
+func TestTrackConsistencyMiss_Bounded(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	for i := 0; i < ConsistencyMissBufferSize+10; i++ {
+		require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{NoteID: fmt.Sprintf("note-%d", i)}))
+	}
+
+	// All misses are counted, but only the most recent ones are kept
+	require.Equal(t, ConsistencyMissBufferSize+10, collector.Export().ConsistencyMisses)
+
+	misses := collector.RecentConsistencyMisses()
+	require.Len(t, misses, ConsistencyMissBufferSize)
+	require.Equal(t, fmt.Sprintf("note-%d", ConsistencyMissBufferSize+9), misses[0].NoteID)
+	require.Equal(t, "note-10", misses[len(misses)-1].NoteID)
+}
+
+func TestImportMissesByCheck(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{Check: ConsistencyCheckRead}))
+
+	snapshot := func(read, monotonic int) Stats {
+		return Stats{
+			ConsistencyMisses: read + monotonic,
+			MissesByCheck:     map[ConsistencyCheck]int{ConsistencyCheckRead: read, ConsistencyCheckMonotonicRead: monotonic},
+		}
+	}
+	collector.Import("loadgen-1", snapshot(1, 2))
+	collector.Import("loadgen-1", snapshot(1, 3))
+
+	stats := collector.Export()
+	require.Equal(t, 5, stats.ConsistencyMisses)
+	require.Equal(t, 2, stats.MissesByCheck[ConsistencyCheckRead], "Expected local and imported misses to add up")
+	require.Equal(t, 3, stats.MissesByCheck[ConsistencyCheckMonotonicRead], "Expected only the delta of re-imported misses")
+}
@@ -0,0 +1,221 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// histogramSubBucketBits splits every power-of-two range into 2^histogramSubBucketBits linear buckets
+	histogramSubBucketBits = 4
+	histogramSubBuckets    = 1 << histogramSubBucketBits
+	// histogramMaxExponent caps recorded values at 2^histogramMaxExponent microseconds, roughly 12 days
+	histogramMaxExponent = 40
+	histogramBucketCount = histogramSubBuckets + (histogramMaxExponent-histogramSubBucketBits+1)*histogramSubBuckets
+)
+
+// Histogram records durations in log-linear buckets, similar to an HDR histogram.
+// Values are kept in microseconds and each power-of-two range is split into 16 linear buckets,
+// so percentiles are accurate to within 6.25% while using a fixed amount of memory.
+type Histogram struct {
+	Count     int
+	SumMicros int64
+	MaxMicros int64
+	counts    []int
+}
+
+// histogramBucket returns the bucket index for a value in microseconds
+func histogramBucket(micros int64) int {
+	if micros < histogramSubBuckets {
+		return int(max(micros, 0))
+	}
+
+	exponent := bits.Len64(uint64(micros)) - 1
+	if exponent > histogramMaxExponent {
+		return histogramBucketCount - 1
+	}
+
+	shift := exponent - histogramSubBucketBits
+	subBucket := int(micros>>shift) - histogramSubBuckets
+	return histogramSubBuckets + shift*histogramSubBuckets + subBucket
+}
+
+// histogramBucketUpperBound returns the largest value in microseconds recorded into a bucket
+func histogramBucketUpperBound(index int) int64 {
+	if index < histogramSubBuckets {
+		return int64(index)
+	}
+
+	shift := (index - histogramSubBuckets) / histogramSubBuckets
+	subBucket := (index - histogramSubBuckets) % histogramSubBuckets
+	return (int64(histogramSubBuckets+subBucket+1) << shift) - 1
+}
+
+// Observe adds a duration to the histogram
+func (h *Histogram) Observe(duration time.Duration) {
+	micros := max(duration.Microseconds(), 0)
+	if h.counts == nil {
+		h.counts = make([]int, histogramBucketCount)
+	}
+
+	h.counts[histogramBucket(micros)]++
+	h.Count++
+	h.SumMicros += micros
+	h.MaxMicros = max(h.MaxMicros, micros)
+}
+
+// Merge adds all values recorded by other to h
+func (h *Histogram) Merge(other Histogram) {
+	if other.Count == 0 {
+		return
+	}
+	if h.counts == nil {
+		h.counts = make([]int, histogramBucketCount)
+	}
+
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.Count += other.Count
+	h.SumMicros += other.SumMicros
+	h.MaxMicros = max(h.MaxMicros, other.MaxMicros)
+}
+
+// Sub returns the values recorded since previous, an earlier snapshot of the same histogram.
+// It reports false if previous is not contained in h, e.g. because the histogram was reset in between.
+func (h Histogram) Sub(previous Histogram) (Histogram, bool) {
+	if previous.Count > h.Count || previous.SumMicros > h.SumMicros {
+		return Histogram{}, false
+	}
+
+	delta := Histogram{
+		Count:     h.Count - previous.Count,
+		SumMicros: h.SumMicros - previous.SumMicros,
+		MaxMicros: h.MaxMicros, // The maximum of the delta is unknown, the overall maximum is an upper bound
+	}
+	if delta.Count == 0 {
+		return delta, true
+	}
+
+	delta.counts = make([]int, histogramBucketCount)
+	for i := range delta.counts {
+		var before, after int
+		if i < len(previous.counts) {
+			before = previous.counts[i]
+		}
+		if i < len(h.counts) {
+			after = h.counts[i]
+		}
+		if after < before {
+			return Histogram{}, false
+		}
+		delta.counts[i] = after - before
+	}
+
+	return delta, true
+}
+
+// Percentile returns the value below which the fraction q of all recorded durations fall, for q between 0 and 1
+func (h Histogram) Percentile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(q * float64(h.Count)))
+	rank = min(max(rank, 1), h.Count)
+
+	seen := 0
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return time.Duration(min(histogramBucketUpperBound(i), h.MaxMicros)) * time.Microsecond
+		}
+	}
+	return h.Max()
+}
+
+// P50 returns the median duration
+func (h Histogram) P50() time.Duration { return h.Percentile(0.50) }
+
+// P90 returns the 90th percentile duration
+func (h Histogram) P90() time.Duration { return h.Percentile(0.90) }
+
+// P99 returns the 99th percentile duration
+func (h Histogram) P99() time.Duration { return h.Percentile(0.99) }
+
+// Max returns the largest recorded duration
+func (h Histogram) Max() time.Duration {
+	return time.Duration(h.MaxMicros) * time.Microsecond
+}
+
+// Sum returns the sum of all recorded durations
+func (h Histogram) Sum() time.Duration {
+	return time.Duration(h.SumMicros) * time.Microsecond
+}
+
+// CountAtOrBelow returns how many durations fell into buckets entirely at or below d.
+// Durations in the bucket containing d are not counted, so the result is exact only on bucket boundaries.
+func (h Histogram) CountAtOrBelow(d time.Duration) int {
+	bound := d.Microseconds()
+	total := 0
+	for i, count := range h.counts {
+		if histogramBucketUpperBound(i) > bound {
+			break
+		}
+		total += count
+	}
+	return total
+}
+
+// clone returns a deep copy of the histogram
+func (h Histogram) clone() Histogram {
+	if h.counts != nil {
+		h.counts = append([]int(nil), h.counts...)
+	}
+	return h
+}
+
+// histogramJSON is the compact wire format of a Histogram, listing only non-empty buckets
+type histogramJSON struct {
+	Count     int      `json:"count"`
+	SumMicros int64    `json:"sumUs"`
+	MaxMicros int64    `json:"maxUs"`
+	Buckets   [][2]int `json:"buckets,omitempty"` // Pairs of bucket index and count
+}
+
+// MarshalJSON encodes the histogram sparsely so stats stay small when exported by data proxies
+func (h Histogram) MarshalJSON() ([]byte, error) {
+	encoded := histogramJSON{Count: h.Count, SumMicros: h.SumMicros, MaxMicros: h.MaxMicros}
+	for i, count := range h.counts {
+		if count > 0 {
+			encoded.Buckets = append(encoded.Buckets, [2]int{i, count})
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a histogram encoded by MarshalJSON
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	var encoded histogramJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	*h = Histogram{Count: encoded.Count, SumMicros: encoded.SumMicros, MaxMicros: encoded.MaxMicros}
+	if len(encoded.Buckets) == 0 {
+		return nil
+	}
+
+	h.counts = make([]int, histogramBucketCount)
+	for _, bucket := range encoded.Buckets {
+		index, count := bucket[0], bucket[1]
+		if index < 0 || index >= histogramBucketCount {
+			return fmt.Errorf("invalid histogram bucket %d", index)
+		}
+		h.counts[index] = count
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newHistogram returns a histogram containing the given durations
+func newHistogram(durations ...time.Duration) Histogram {
+	var h Histogram
+	for _, d := range durations {
+		h.Observe(d)
+	}
+	return h
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	// Small values are recorded exactly
+	for micros := int64(0); micros < histogramSubBuckets; micros++ {
+		require.Equal(t, micros, histogramBucketUpperBound(histogramBucket(micros)))
+	}
+
+	// Every value falls into a bucket whose upper bound is within 6.25% of it
+	for _, micros := range []int64{16, 17, 100, 999, 1000, 1024, 123_456, 10_000_000} {
+		upper := histogramBucketUpperBound(histogramBucket(micros))
+		require.GreaterOrEqual(t, upper, micros)
+		require.LessOrEqual(t, float64(upper-micros), float64(micros)/histogramSubBuckets, "value %d", micros)
+	}
+
+	// Buckets are ordered and contiguous
+	for i := 1; i < histogramBucketCount; i++ {
+		require.Equal(t, i, histogramBucket(histogramBucketUpperBound(i-1)+1))
+	}
+
+	// Values beyond the tracked range are clamped into the last bucket
+	require.Equal(t, histogramBucketCount-1, histogramBucket(1<<62))
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	var h Histogram
+	require.Zero(t, h.P50())
+	require.Zero(t, h.Max())
+
+	// Sub-millisecond durations are kept
+	for i := 1; i <= 100; i++ {
+		h.Observe(time.Duration(i) * 10 * time.Microsecond)
+	}
+
+	require.Equal(t, 100, h.Count)
+	require.InEpsilon(t, 500*time.Microsecond, h.P50(), 0.0625)
+	require.InEpsilon(t, 900*time.Microsecond, h.P90(), 0.0625)
+	require.InEpsilon(t, 990*time.Microsecond, h.P99(), 0.0625)
+	require.Equal(t, time.Millisecond, h.Max())
+	require.Equal(t, 50500*time.Microsecond, h.Sum())
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a := newHistogram(time.Millisecond, 2*time.Millisecond)
+	b := newHistogram(3*time.Millisecond, 100*time.Millisecond)
+
+	merged := a.clone()
+	merged.Merge(b)
+	require.Equal(t, 4, merged.Count)
+	require.Equal(t, 100*time.Millisecond, merged.Max())
+	require.Equal(t, 106*time.Millisecond, merged.Sum())
+	require.Equal(t, 2, a.Count, "merging must not modify the source")
+
+	// Subtracting an earlier snapshot yields only the values recorded since
+	later := a.clone()
+	later.Observe(5 * time.Millisecond)
+	delta, ok := later.Sub(a)
+	require.True(t, ok)
+	require.Equal(t, 1, delta.Count)
+	require.Equal(t, 5*time.Millisecond, delta.Sum())
+	require.Equal(t, 5*time.Millisecond, delta.P50())
+
+	// A histogram that was reset does not contain the earlier snapshot
+	_, ok = newHistogram(time.Second).Sub(later)
+	require.False(t, ok)
+}
+
+func TestHistogramJSON(t *testing.T) {
+	h := newHistogram(time.Millisecond, time.Millisecond, 40*time.Millisecond)
+
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"count":3,"sumUs":42000,"maxUs":40000,"buckets":[[111,2],[195,1]]}`, string(data))
+
+	var decoded Histogram
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, h, decoded)
+
+	empty, err := json.Marshal(Histogram{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"count":0,"sumUs":0,"maxUs":0}`, string(empty))
+
+	require.Error(t, json.Unmarshal([]byte(`{"buckets":[[100000,1]]}`), &decoded))
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rotatingFile appends log entries as JSON lines and moves the file to <path>.1 once it exceeds its size limit
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// logFileLine is the JSON encoding of a persisted log entry
+type logFileLine struct {
+	Time       time.Time         `json:"time"`
+	Level      string            `json:"level"`
+	Source     string            `json:"source"`
+	Message    string            `json:"msg"`
+	Attributes map[string]string `json:"attrs,omitempty"`
+}
+
+func openRotatingFile(path string, maxBytes int64) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("could not create log directory: %w", err)
+	}
+
+	f := &rotatingFile{path: path, maxBytes: maxBytes}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("could not stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate replaces the previous rotated file with the current one and starts a new file
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("could not close log file: %w", err)
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+	return f.open()
+}
+
+// writeEntry persists an entry, dropping it if the file cannot be written to
+func (f *rotatingFile) writeEntry(entry LogEntry) {
+	line := logFileLine{
+		Time:    entry.Timestamp,
+		Level:   entry.Level.String(),
+		Source:  entry.Source,
+		Message: entry.Message,
+	}
+	if len(entry.Attributes) > 0 {
+		line.Attributes = make(map[string]string, len(entry.Attributes))
+		for _, attr := range entry.Attributes {
+			line.Attributes[attr.Key] = attr.Value
+		}
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return
+	}
+	if f.size > 0 && f.size+int64(len(data)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			f.file = nil
+			return
+		}
+	}
+
+	n, _ := f.file.Write(data)
+	f.size += int64(n)
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.jsonl")
+
+	capture := NewLogCapture(10)
+	require.NoError(t, capture.OpenFile(path, 300))
+	logger := slog.New(capture.Handler(LogSourceAPI, slog.LevelDebug))
+
+	for i := 0; i < 5; i++ {
+		logger.Info("request handled", "index", i)
+	}
+	require.NoError(t, capture.Close())
+
+	// Older entries were moved to the rotated file, no entry is split across files
+	var lines []logFileLine
+	for _, name := range []string{path + ".1", path} {
+		file, err := os.Open(name)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line logFileLine
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		file.Close()
+
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(300))
+	}
+
+	require.NotEmpty(t, lines)
+	last := lines[len(lines)-1]
+	require.Equal(t, "request handled", last.Message)
+	require.Equal(t, "INFO", last.Level)
+	require.Equal(t, LogSourceAPI, last.Source)
+	require.Equal(t, "4", last.Attributes["index"])
+}
@@ -1,22 +1,95 @@
 package telemetry
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Sources of captured logs, data proxies are identified by ProxyLogSource
+const (
+	LogSourceAPI       = "api"
+	LogSourceSimulator = "simulator"
+
+	// LogSourceKey is the attribute key that overrides the source of a log record
+	LogSourceKey = "source"
+)
+
+// ProxyLogSource returns the log source of a data proxy
+func ProxyLogSource(id int) string {
+	return fmt.Sprintf("proxy v%d", id)
+}
+
+// LogEntry is a captured log record
 type LogEntry struct {
-	Timestamp time.Time
-	Message   string
+	Timestamp  time.Time
+	Level      slog.Level
+	Source     string
+	Message    string
+	Attributes []Attribute
 }
 
+// Attribute returns the value of a log attribute, or an empty string if it is not set
+func (e LogEntry) Attribute(key string) string {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// String formats the entry as a single line of text
+func (e LogEntry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Timestamp.Format(time.StampMilli))
+	b.WriteString(" ")
+	b.WriteString(LevelLabel(e.Level))
+	b.WriteString(" [")
+	b.WriteString(e.Source)
+	b.WriteString("] ")
+	b.WriteString(e.Message)
+	for _, attr := range e.Attributes {
+		b.WriteString(" ")
+		b.WriteString(attr.Key)
+		b.WriteString("=")
+		if strings.ContainsAny(attr.Value, " \t\"=") {
+			b.WriteString(fmt.Sprintf("%q", attr.Value))
+		} else {
+			b.WriteString(attr.Value)
+		}
+	}
+	return b.String()
+}
+
+// LevelLabel returns the three letter label of a log level
+func LevelLabel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERR"
+	case level >= slog.LevelWarn:
+		return "WRN"
+	case level >= slog.LevelInfo:
+		return "INF"
+	default:
+		return "DBG"
+	}
+}
+
+// LogCapture keeps the most recent log records of the API and all data proxies
 type LogCapture struct {
 	mu      sync.RWMutex
 	entries []LogEntry
 	maxSize int
 	writers []io.Writer
 	onLog   func(LogEntry)
+	file    *rotatingFile
 }
 
 func NewLogCapture(maxSize int) *LogCapture {
@@ -27,28 +100,43 @@ func NewLogCapture(maxSize int) *LogCapture {
 	}
 }
 
-func (lc *LogCapture) Write(p []byte) (int, error) {
-	entry := LogEntry{
-		Timestamp: time.Now(),
-		Message:   string(p),
-	}
-
+// add stores an entry, passes it to the log callback and persists it.
+// Entries not logged through Handler are also echoed to the added writers, which receive logged records directly.
+func (lc *LogCapture) add(entry LogEntry, echo bool) {
 	lc.mu.Lock()
 	if len(lc.entries) >= lc.maxSize {
 		lc.entries = lc.entries[1:]
 	}
 	lc.entries = append(lc.entries, entry)
 	onLog := lc.onLog
+	writers := lc.writers
+	file := lc.file
 	lc.mu.Unlock()
 
 	if onLog != nil {
 		onLog(entry)
 	}
 
-	for _, w := range lc.writers {
-		w.Write(p)
+	if file != nil {
+		file.writeEntry(entry)
+	}
+
+	if echo {
+		line := []byte(entry.String() + "\n")
+		for _, w := range writers {
+			w.Write(line)
+		}
 	}
+}
 
+// Write captures plain text written by the API, one entry per line
+func (lc *LogCapture) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lc.add(LogEntry{Timestamp: time.Now(), Level: slog.LevelInfo, Source: LogSourceAPI, Message: line}, true)
+	}
 	return len(p), nil
 }
 
@@ -64,6 +152,37 @@ func (lc *LogCapture) SetLogCallback(callback func(LogEntry)) {
 	lc.mu.Unlock()
 }
 
+// OpenFile persists all captured entries as JSON lines, rotating the file once it exceeds maxBytes
+func (lc *LogCapture) OpenFile(path string, maxBytes int64) error {
+	file, err := openRotatingFile(path, maxBytes)
+	if err != nil {
+		return err
+	}
+
+	lc.mu.Lock()
+	previous := lc.file
+	lc.file = file
+	lc.mu.Unlock()
+
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
+
+// Close closes the log file, if any
+func (lc *LogCapture) Close() error {
+	lc.mu.Lock()
+	file := lc.file
+	lc.file = nil
+	lc.mu.Unlock()
+
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
 func (lc *LogCapture) GetRecentLogs(limit int) []LogEntry {
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
@@ -87,3 +206,217 @@ func (lc *LogCapture) GetAllLogs() []LogEntry {
 	return result
 }
 
+// Sources returns all sources of captured logs in order of their first entry
+func (lc *LogCapture) Sources() []string {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	var sources []string
+	seen := make(map[string]bool)
+	for _, entry := range lc.entries {
+		if !seen[entry.Source] {
+			seen[entry.Source] = true
+			sources = append(sources, entry.Source)
+		}
+	}
+	return sources
+}
+
+// Handler returns a slog handler capturing records of the given source.
+// A source attribute added with Logger.With overrides the source, e.g. for the load generator.
+func (lc *LogCapture) Handler(source string, level slog.Leveler) slog.Handler {
+	return &captureHandler{capture: lc, source: source, level: level}
+}
+
+// SourceWriter returns a writer capturing the output of a data proxy process.
+// Lines written by the JSON log handler of data proxies are decoded into structured entries,
+// all other output like panics is captured as is.
+func (lc *LogCapture) SourceWriter(source string) io.Writer {
+	return &sourceWriter{capture: lc, source: source}
+}
+
+type captureHandler struct {
+	capture *LogCapture
+	source  string
+	level   slog.Leveler
+	attrs   []Attribute
+	group   string
+}
+
+func (h *captureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *captureHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := LogEntry{
+		Timestamp:  record.Time,
+		Level:      record.Level,
+		Source:     h.source,
+		Message:    record.Message,
+		Attributes: append([]Attribute(nil), h.attrs...),
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		entry.Source, entry.Attributes = appendLogAttr(entry.Source, entry.Attributes, h.group, attr)
+		return true
+	})
+
+	h.capture.add(entry, false)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]Attribute(nil), h.attrs...)
+	for _, attr := range attrs {
+		next.source, next.attrs = appendLogAttr(next.source, next.attrs, h.group, attr)
+	}
+	return &next
+}
+
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = h.group + name + "."
+	return &next
+}
+
+// appendLogAttr flattens an attribute into a list of attributes, picking up source overrides
+func appendLogAttr(source string, attrs []Attribute, group string, attr slog.Attr) (string, []Attribute) {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return source, attrs
+	}
+
+	if value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix = group + attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			source, attrs = appendLogAttr(source, attrs, prefix, member)
+		}
+		return source, attrs
+	}
+
+	if group == "" && attr.Key == LogSourceKey {
+		return value.String(), attrs
+	}
+	return source, append(attrs, Attribute{Key: group + attr.Key, Value: value.String()})
+}
+
+// MultiHandler passes records to all handlers enabled for their level
+type MultiHandler []slog.Handler
+
+func (h MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h MultiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (h MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := make(MultiHandler, len(h))
+	for i, handler := range h {
+		next[i] = handler.WithAttrs(attrs)
+	}
+	return next
+}
+
+func (h MultiHandler) WithGroup(name string) slog.Handler {
+	next := make(MultiHandler, len(h))
+	for i, handler := range h {
+		next[i] = handler.WithGroup(name)
+	}
+	return next
+}
+
+type sourceWriter struct {
+	mu      sync.Mutex
+	capture *LogCapture
+	source  string
+	pending []byte
+}
+
+func (w *sourceWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Output may arrive in chunks that do not end on a line break
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimSpace(w.pending[:i]))
+		w.pending = w.pending[i+1:]
+		if line != "" {
+			w.capture.add(decodeLogLine(w.source, line), true)
+		}
+	}
+	return len(p), nil
+}
+
+// decodeLogLine decodes a line written by slog's JSON handler, keeping the order of attributes.
+// Lines that are not JSON are captured as info messages.
+func decodeLogLine(source, line string) LogEntry {
+	entry := LogEntry{Timestamp: time.Now(), Level: slog.LevelInfo, Source: source, Message: line}
+	if !strings.HasPrefix(line, "{") {
+		return entry
+	}
+
+	decoded := LogEntry{Timestamp: entry.Timestamp, Level: slog.LevelInfo, Source: source}
+	decoder := json.NewDecoder(strings.NewReader(line))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return entry
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return entry
+		}
+		key, _ := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return entry
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+
+		switch key {
+		case slog.TimeKey:
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				decoded.Timestamp = t
+			}
+		case slog.LevelKey:
+			_ = decoded.Level.UnmarshalText([]byte(value))
+		case slog.MessageKey:
+			decoded.Message = value
+		default:
+			decoded.Attributes = append(decoded.Attributes, Attribute{Key: key, Value: value})
+		}
+	}
+	return decoded
+}
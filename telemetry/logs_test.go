@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureHandlerKeepsStructuredRecords(t *testing.T) {
+	capture := NewLogCapture(10)
+	logger := slog.New(NewContextHandler(capture.Handler(LogSourceAPI, slog.LevelInfo)))
+
+	logger.Debug("dropped below the level")
+	logger.WithGroup("req").Info("handled", "status", 200, slog.Group("account", "id", "a1"))
+	logger.With(LogSourceKey, LogSourceSimulator).ErrorContext(WithRequestID(context.Background(), "req-1"), "failed", "error", errors.New("boom"))
+
+	entries := capture.GetAllLogs()
+	require.Len(t, entries, 2)
+
+	require.Equal(t, slog.LevelInfo, entries[0].Level)
+	require.Equal(t, LogSourceAPI, entries[0].Source)
+	require.Equal(t, "handled", entries[0].Message)
+	require.Equal(t, []Attribute{{Key: "req.status", Value: "200"}, {Key: "req.account.id", Value: "a1"}}, entries[0].Attributes)
+
+	// The source attribute overrides the source instead of being kept as an attribute
+	require.Equal(t, LogSourceSimulator, entries[1].Source)
+	require.Equal(t, slog.LevelError, entries[1].Level)
+	require.Equal(t, "boom", entries[1].Attribute("error"))
+	require.Equal(t, "req-1", entries[1].Attribute(RequestIDLogKey))
+	require.Empty(t, entries[1].Attribute(LogSourceKey))
+
+	require.Equal(t, []string{LogSourceAPI, LogSourceSimulator}, capture.Sources())
+}
+
+func TestSourceWriterDecodesProxyLogs(t *testing.T) {
+	capture := NewLogCapture(10)
+	w := capture.SourceWriter(ProxyLogSource(2))
+
+	line := `{"time":"2025-08-14T10:00:00.123456789Z","level":"WARN","msg":"Shadow read mismatch","operation":"GetNote","notes":3,"requestID":"req-7"}`
+
+	// Output of child processes may be split anywhere
+	_, err := fmt.Fprint(w, line[:40])
+	require.NoError(t, err)
+	require.Empty(t, capture.GetAllLogs())
+	_, err = fmt.Fprint(w, line[40:]+"\npanic: something went wrong\n")
+	require.NoError(t, err)
+
+	entries := capture.GetAllLogs()
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "proxy v2", entries[0].Source)
+	require.Equal(t, slog.LevelWarn, entries[0].Level)
+	require.Equal(t, "Shadow read mismatch", entries[0].Message)
+	require.Equal(t, 123456789, entries[0].Timestamp.Nanosecond())
+	require.Equal(t, []Attribute{{Key: "operation", Value: "GetNote"}, {Key: "notes", Value: "3"}, {Key: "requestID", Value: "req-7"}}, entries[0].Attributes)
+
+	// Anything else is kept as plain text
+	require.Equal(t, slog.LevelInfo, entries[1].Level)
+	require.Equal(t, "panic: something went wrong", entries[1].Message)
+	require.True(t, strings.HasSuffix(entries[1].String(), "INF [proxy v2] panic: something went wrong"))
+}
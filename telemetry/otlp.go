@@ -0,0 +1,311 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// otlpScopeName identifies this application as the instrumentation scope of exported spans
+const otlpScopeName = "github.com/brunoscheufler/gopherconuk25"
+
+// otlpStatusError is the OTLP status code of failed spans
+const otlpStatusError = 2
+
+// The types below mirror the JSON encoding of an OTLP ExportTraceServiceRequest,
+// so trace files can be loaded by any tool that reads OTLP JSON lines.
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// encodeOTLPLine encodes spans of a service as a single line of OTLP JSON
+func encodeOTLPLine(service string, spans []SpanData) ([]byte, error) {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		}
+		if span.ParentSpanID.IsValid() {
+			s.ParentSpanID = span.ParentSpanID.String()
+		}
+		for _, attr := range span.Attributes {
+			s.Attributes = append(s.Attributes, otlpKeyValue{Key: attr.Key, Value: otlpAnyValue{StringValue: attr.Value}})
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		encoded = append(encoded, s)
+	}
+
+	line, err := json.Marshal(otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: service}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: otlpScopeName},
+				Spans: encoded,
+			}},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode spans: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// decodeOTLPLine decodes all spans contained in a line of OTLP JSON
+func decodeOTLPLine(line []byte) ([]SpanData, error) {
+	var traces otlpTraces
+	if err := json.Unmarshal(line, &traces); err != nil {
+		return nil, fmt.Errorf("could not decode spans: %w", err)
+	}
+
+	var spans []SpanData
+	for _, resourceSpans := range traces.ResourceSpans {
+		var service string
+		for _, attr := range resourceSpans.Resource.Attributes {
+			if attr.Key == "service.name" {
+				service = attr.Value.StringValue
+			}
+		}
+
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, s := range scopeSpans.Spans {
+				span := SpanData{Service: service, Name: s.Name, Kind: s.Kind}
+				if err := decodeHexID(span.TraceID[:], s.TraceID); err != nil {
+					return nil, fmt.Errorf("invalid trace ID: %w", err)
+				}
+				if err := decodeHexID(span.SpanID[:], s.SpanID); err != nil {
+					return nil, fmt.Errorf("invalid span ID: %w", err)
+				}
+				if s.ParentSpanID != "" {
+					if err := decodeHexID(span.ParentSpanID[:], s.ParentSpanID); err != nil {
+						return nil, fmt.Errorf("invalid parent span ID: %w", err)
+					}
+				}
+
+				start, err := strconv.ParseInt(s.StartTimeUnixNano, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid start time: %w", err)
+				}
+				end, err := strconv.ParseInt(s.EndTimeUnixNano, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid end time: %w", err)
+				}
+				span.Start = time.Unix(0, start)
+				span.End = time.Unix(0, end)
+
+				for _, attr := range s.Attributes {
+					span.Attributes = append(span.Attributes, Attribute{Key: attr.Key, Value: attr.Value.StringValue})
+				}
+				if s.Status.Code == otlpStatusError {
+					span.Error = s.Status.Message
+				}
+
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans, nil
+}
+
+func decodeHexID(dst []byte, value string) error {
+	if hex.DecodedLen(len(value)) != len(dst) {
+		return fmt.Errorf("expected %d hex characters, got %q", 2*len(dst), value)
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
+
+// ReadTrace loads all spans of a trace from a file of OTLP JSON lines.
+// Lines that cannot be decoded, e.g. a partially written last line, are skipped.
+func ReadTrace(path string, traceID TraceID) ([]SpanData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not open trace file: %w", err)
+	}
+	defer file.Close()
+
+	needle := []byte(traceID.String())
+
+	var spans []SpanData
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.Contains(line, needle) {
+			continue
+		}
+
+		lineSpans, err := decodeOTLPLine(line)
+		if err != nil {
+			continue
+		}
+		for _, span := range lineSpans {
+			if span.TraceID == traceID {
+				spans = append(spans, span)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read trace file: %w", err)
+	}
+
+	return spans, nil
+}
+
+// traceReader follows a file of OTLP JSON lines that other processes append to, across rotations
+type traceReader struct {
+	path    string
+	file    *os.File
+	partial []byte // Start of a line that is still being written
+}
+
+// newTraceReader starts following the file at its current end, so only spans of the current run are read
+func newTraceReader(path string) (*traceReader, error) {
+	r := &traceReader{path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open trace file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not seek trace file: %w", err)
+	}
+	r.file = file
+
+	return r, nil
+}
+
+// read returns the spans of all lines completed since the last read.
+// Once the file was rotated, the rest of the rotated file is read before continuing with the new one.
+func (r *traceReader) read() ([]SpanData, error) {
+	if r.file == nil {
+		file, err := os.Open(r.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("could not open trace file: %w", err)
+		}
+		r.file = file
+	}
+
+	spans, err := r.readLines()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return spans, nil
+	}
+	current, currentErr := r.file.Stat()
+	if currentErr != nil || (err == nil && os.SameFile(info, current)) {
+		return spans, nil
+	}
+
+	// The file was rotated, continue with the new one from its start
+	r.close()
+	if err != nil {
+		return spans, nil
+	}
+	rotated, err := r.read()
+	return append(spans, rotated...), err
+}
+
+// readLines decodes the lines appended since the last read, keeping an incomplete last line for the next read
+func (r *traceReader) readLines() ([]SpanData, error) {
+	data, err := io.ReadAll(r.file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read trace file: %w", err)
+	}
+
+	data = append(r.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		r.partial = data
+		return nil, nil
+	}
+	r.partial = bytes.Clone(data[end+1:])
+
+	var spans []SpanData
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		lineSpans, err := decodeOTLPLine(line)
+		if err != nil {
+			continue
+		}
+		spans = append(spans, lineSpans...)
+	}
+	return spans, nil
+}
+
+func (r *traceReader) close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	r.partial = nil
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPLineRoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 123456789)
+	span := SpanData{
+		Service:      "data-proxy-2",
+		Name:         "sqlite GetNote",
+		Kind:         SpanKindClient,
+		TraceID:      newTraceID(),
+		SpanID:       newSpanID(),
+		ParentSpanID: newSpanID(),
+		Start:        start,
+		End:          start.Add(3 * time.Millisecond),
+		Attributes:   []Attribute{{Key: "db.store", Value: "legacy"}},
+		Error:        "database is locked",
+	}
+
+	line, err := encodeOTLPLine(span.Service, []SpanData{span})
+	require.NoError(t, err)
+	require.Equal(t, byte('\n'), line[len(line)-1], "every export is a single line")
+
+	// The line follows the OTLP JSON encoding
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(line, &raw))
+	encoded := raw["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	require.Equal(t, span.TraceID.String(), encoded["traceId"])
+	require.Equal(t, "1700000000123456789", encoded["startTimeUnixNano"])
+	require.Equal(t, float64(SpanKindClient), encoded["kind"])
+	require.Equal(t, float64(otlpStatusError), encoded["status"].(map[string]any)["code"])
+
+	decoded, err := decodeOTLPLine(line)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.Equal(t, span.TraceID, decoded[0].TraceID)
+	require.Equal(t, span.ParentSpanID, decoded[0].ParentSpanID)
+	require.True(t, span.Start.Equal(decoded[0].Start))
+	require.True(t, span.End.Equal(decoded[0].End))
+	require.Equal(t, span.Service, decoded[0].Service)
+	require.Equal(t, span.Attributes, decoded[0].Attributes)
+	require.Equal(t, span.Error, decoded[0].Error)
+}
+
+func TestReadTraceSkipsOtherTracesAndBrokenLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	wanted := SpanData{Name: "wanted", TraceID: newTraceID(), SpanID: newSpanID(), Start: time.Now(), End: time.Now()}
+	other := SpanData{Name: "other", TraceID: newTraceID(), SpanID: newSpanID(), Start: time.Now(), End: time.Now()}
+
+	var content []byte
+	for _, span := range []SpanData{wanted, other} {
+		line, err := encodeOTLPLine("notes-api", []SpanData{span})
+		require.NoError(t, err)
+		content = append(content, line...)
+	}
+	// A line cut off while being written
+	content = append(content, []byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"`+wanted.TraceID.String())...)
+	require.NoError(t, os.WriteFile(path, content, 0640))
+
+	spans, err := ReadTrace(path, wanted.TraceID)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	require.Equal(t, "wanted", spans[0].Name)
+
+	// A missing file has no spans yet
+	spans, err = ReadTrace(filepath.Join(t.TempDir(), "missing.jsonl"), wanted.TraceID)
+	require.NoError(t, err)
+	require.Empty(t, spans)
+}
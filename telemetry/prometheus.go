@@ -0,0 +1,255 @@
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusHandler serves the collector's current stats in the Prometheus text exposition format
+func PrometheusHandler(collector StatsCollector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		if err := WritePrometheus(w, collector.Export()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// label is a single Prometheus label pair
+type label struct {
+	name  string
+	value string
+}
+
+// promWriter writes metric families, remembering the first write error
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, args...)
+}
+
+func (pw *promWriter) header(name, help, metricType string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func (pw *promWriter) sample(name string, labels []label, value float64) {
+	pw.printf("%s%s %s\n", name, formatLabels(labels), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// PrometheusBuckets are the upper bounds of the exposed histogram buckets.
+// Counts are derived from the recorded histogram and exact to its bucket resolution.
+var PrometheusBuckets = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// histogram writes the cumulative buckets, sum and count of a latency histogram in seconds
+func (pw *promWriter) histogram(name string, labels []label, h Histogram) {
+	for _, bound := range PrometheusBuckets {
+		le := label{name: "le", value: strconv.FormatFloat(bound.Seconds(), 'g', -1, 64)}
+		pw.sample(name+"_bucket", append(labels[:len(labels):len(labels)], le), float64(h.CountAtOrBelow(bound)))
+	}
+	pw.sample(name+"_bucket", append(labels[:len(labels):len(labels)], label{name: "le", value: "+Inf"}), float64(h.Count))
+	pw.sample(name+"_sum", labels, h.Sum().Seconds())
+	pw.sample(name+"_count", labels, float64(h.Count))
+}
+
+// WritePrometheus renders stats in the Prometheus text exposition format.
+// Totals are exposed as counters and latencies as histograms in seconds.
+func WritePrometheus(w io.Writer, stats Stats) error {
+	pw := &promWriter{w: bufio.NewWriter(w)}
+
+	// API requests
+	apiKeys := sortedKeys(stats.APIRequests)
+	pw.header("notes_api_requests_total", "Total number of handled API requests.", "counter")
+	for _, key := range apiKeys {
+		s := stats.APIRequests[key]
+		pw.sample("notes_api_requests_total", apiLabels(s), float64(s.Metrics.TotalCount))
+	}
+	pw.header("notes_api_request_duration_seconds", "Duration of handled API requests.", "histogram")
+	for _, key := range apiKeys {
+		s := stats.APIRequests[key]
+		pw.histogram("notes_api_request_duration_seconds", apiLabels(s), s.Metrics.Latency)
+	}
+
+	// Proxy access
+	proxyKeys := sortedKeys(stats.ProxyAccess)
+	pw.header("notes_proxy_access_total", "Total number of data proxy calls.", "counter")
+	for _, key := range proxyKeys {
+		s := stats.ProxyAccess[key]
+		pw.sample("notes_proxy_access_total", proxyLabels(s), float64(s.Metrics.TotalCount))
+	}
+	pw.header("notes_proxy_access_duration_seconds", "Duration of data proxy calls.", "histogram")
+	for _, key := range proxyKeys {
+		s := stats.ProxyAccess[key]
+		pw.histogram("notes_proxy_access_duration_seconds", proxyLabels(s), s.Metrics.Latency)
+	}
+
+	// Data store access
+	dataStoreKeys := sortedKeys(stats.DataStoreAccess)
+	pw.header("notes_datastore_access_total", "Total number of data store operations.", "counter")
+	for _, key := range dataStoreKeys {
+		s := stats.DataStoreAccess[key]
+		pw.sample("notes_datastore_access_total", dataStoreLabels(s), float64(s.Metrics.TotalCount))
+	}
+	pw.header("notes_datastore_access_duration_seconds", "Duration of data store operations.", "histogram")
+	for _, key := range dataStoreKeys {
+		s := stats.DataStoreAccess[key]
+		pw.histogram("notes_datastore_access_duration_seconds", dataStoreLabels(s), s.Metrics.Latency)
+	}
+
+	// Note count
+	pw.header("notes_note_count", "Number of notes per store.", "gauge")
+	for _, key := range sortedKeys(stats.NoteCount) {
+		pw.sample("notes_note_count", []label{{name: "store", value: key}}, float64(stats.NoteCount[key]))
+	}
+
+	// Consistency misses
+	pw.header("notes_consistency_misses_total", "Total number of unexpected note contents observed by the load generator.", "counter")
+	pw.sample("notes_consistency_misses_total", nil, float64(stats.ConsistencyMisses))
+	pw.header("notes_consistency_misses_by_check_total", "Total number of consistency misses per check of the load generator that detected them.", "counter")
+	for _, check := range sortedKeys(stats.MissesByCheck) {
+		pw.sample("notes_consistency_misses_by_check_total", []label{{name: "check", value: string(check)}}, float64(stats.MissesByCheck[check]))
+	}
+
+	// Shadow reads, summed per operation. A series per account would grow without bound, the stats keep the breakdown.
+	shadowReads := shadowReadsByOperation(stats.ShadowReads)
+	shadowOperations := sortedKeys(shadowReads)
+	pw.header("notes_shadow_reads_total", "Total number of shadow reads compared against legacy.", "counter")
+	for _, operation := range shadowOperations {
+		s := shadowReads[operation]
+		pw.sample("notes_shadow_reads_total", shadowReadLabels(s), float64(s.TotalCount))
+	}
+	pw.header("notes_shadow_read_mismatches_total", "Total number of shadow reads that did not match legacy.", "counter")
+	for _, operation := range shadowOperations {
+		s := shadowReads[operation]
+		pw.sample("notes_shadow_read_mismatches_total", shadowReadLabels(s), float64(s.Mismatches))
+	}
+
+	// Injected faults
+	pw.header("notes_injected_faults_total", "Total number of faults injected by chaos experiments.", "counter")
+	for _, key := range sortedKeys(stats.InjectedFaults) {
+		s := stats.InjectedFaults[key]
+		pw.sample("notes_injected_faults_total", injectedFaultLabels(s), float64(s.Count))
+	}
+
+	if pw.err != nil {
+		return fmt.Errorf("could not write metrics: %w", pw.err)
+	}
+	if err := pw.w.Flush(); err != nil {
+		return fmt.Errorf("could not write metrics: %w", err)
+	}
+	return nil
+}
+
+func apiLabels(s *APIStats) []label {
+	return []label{
+		{name: "method", value: s.Method},
+		{name: "route", value: s.Route},
+		{name: "status", value: strconv.Itoa(s.Status)},
+	}
+}
+
+func proxyLabels(s *ProxyStats) []label {
+	return []label{
+		{name: "proxy_id", value: strconv.Itoa(s.ProxyID)},
+		{name: "operation", value: s.Operation},
+		{name: "status", value: s.Status.String()},
+	}
+}
+
+func dataStoreLabels(s *DataStoreStats) []label {
+	return []label{
+		{name: "store", value: s.StoreID},
+		{name: "operation", value: s.Operation},
+		{name: "status", value: s.Status.String()},
+	}
+}
+
+func shadowReadLabels(s *ShadowReadStats) []label {
+	return []label{
+		{name: "operation", value: s.Operation},
+	}
+}
+
+// shadowReadsByOperation sums the shadow reads of all accounts per operation
+func shadowReadsByOperation(shadowReads map[string]*ShadowReadStats) map[string]*ShadowReadStats {
+	totals := make(map[string]*ShadowReadStats)
+	for _, s := range shadowReads {
+		total, ok := totals[s.Operation]
+		if !ok {
+			total = &ShadowReadStats{Operation: s.Operation}
+			totals[s.Operation] = total
+		}
+		total.TotalCount += s.TotalCount
+		total.Mismatches += s.Mismatches
+	}
+	return totals
+}
+
+func injectedFaultLabels(s *InjectedFaultStats) []label {
+	return []label{
+		{name: "fault", value: s.Fault},
+		{name: "target", value: s.Target},
+		{name: "proxy_id", value: strconv.Itoa(s.ProxyID)},
+	}
+}
+
+// formatLabels renders a label set, escaping values as required by the exposition format
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueReplacer.Replace(l.value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// sortedKeys returns the keys of a map in a stable order so scrapes are deterministic
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
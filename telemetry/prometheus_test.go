@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	require.NoError(t, collector.TrackAPIRequest("GET", "/accounts/{id}", 3*time.Millisecond, 200))
+	require.NoError(t, collector.TrackAPIRequest("GET", "/accounts/{id}", 40*time.Millisecond, 200))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", 2*time.Millisecond, 1, ProxyAccessStatusContention))
+	require.NoError(t, collector.TrackDataStoreAccess("CreateNote", time.Millisecond, "legacy", DataStoreAccessStatusSuccess))
+	require.NoError(t, collector.TrackNoteCount("legacy", 12))
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{Check: ConsistencyCheckMonotonicRead}))
+	require.NoError(t, collector.TrackShadowRead("account-1", "GetNote", false))
+	require.NoError(t, collector.TrackShadowRead("account-2", "GetNote", true))
+	require.NoError(t, collector.TrackInjectedFault("store_busy", "legacy", 2))
+
+	rec := httptest.NewRecorder()
+	PrometheusHandler(collector).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, PrometheusContentType, rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"# TYPE notes_api_requests_total counter",
+		`notes_api_requests_total{method="GET",route="/accounts/{id}",status="200"} 2`,
+		"# TYPE notes_api_request_duration_seconds histogram",
+		`notes_api_request_duration_seconds_bucket{method="GET",route="/accounts/{id}",status="200",le="0.005"} 1`,
+		`notes_api_request_duration_seconds_bucket{method="GET",route="/accounts/{id}",status="200",le="0.05"} 2`,
+		`notes_api_request_duration_seconds_bucket{method="GET",route="/accounts/{id}",status="200",le="+Inf"} 2`,
+		`notes_api_request_duration_seconds_sum{method="GET",route="/accounts/{id}",status="200"} 0.043`,
+		`notes_api_request_duration_seconds_count{method="GET",route="/accounts/{id}",status="200"} 2`,
+		`notes_proxy_access_total{proxy_id="1",operation="GetNote",status="contention"} 1`,
+		`notes_datastore_access_total{store="legacy",operation="CreateNote",status="success"} 1`,
+		`notes_note_count{store="legacy"} 12`,
+		"notes_consistency_misses_total 1",
+		`notes_consistency_misses_by_check_total{check="monotonic_read"} 1`,
+		`notes_shadow_reads_total{operation="GetNote"} 2`,
+		`notes_shadow_read_mismatches_total{operation="GetNote"} 1`,
+		`notes_injected_faults_total{fault="store_busy",target="legacy",proxy_id="2"} 1`,
+	} {
+		require.Contains(t, body, expected)
+	}
+
+	// Shadow reads are not broken down by account
+	require.NotContains(t, body, "account_id")
+
+	// Every family is declared exactly once
+	require.Equal(t, 1, strings.Count(body, "# TYPE notes_datastore_access_duration_seconds histogram"))
+}
+
+func TestFormatLabelsEscapesValues(t *testing.T) {
+	require.Equal(t, "", formatLabels(nil))
+	require.Equal(t, `{route="a\"b\\c\nd"}`, formatLabels([]label{{name: "route", value: "a\"b\\c\nd"}}))
+}
@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// RateWindowShort and RateWindowLong are the windows request rates are averaged over
+	RateWindowShort = time.Minute
+	RateWindowLong  = 5 * time.Minute
+	// rateBuckets is the number of completed tick buckets needed for the long window
+	rateBuckets = int(RateWindowLong / TickInterval)
+)
+
+// ewmaAlpha weighs each completed bucket so the moving average decays with a time constant of one minute
+var ewmaAlpha = 1 - math.Exp(-TickInterval.Seconds()/RateWindowShort.Seconds())
+
+// RequestRates holds requests per minute averaged in different ways
+type RequestRates struct {
+	OneMinute   float64 `json:"oneMinute"`   // Average over the last minute
+	FiveMinutes float64 `json:"fiveMinutes"` // Average over the last five minutes
+	EWMA        float64 `json:"ewma"`        // Exponentially weighted moving average with a one minute time constant
+}
+
+// rateWindow counts requests in a ring of tick-sized buckets, so rates can be averaged over sliding windows
+type rateWindow struct {
+	buckets [rateBuckets]int // Counts of completed buckets
+	next    int              // Index of the bucket overwritten by the next rotation
+	filled  int              // Number of completed buckets, up to rateBuckets
+	current int              // Count of the bucket in progress
+	ewma    float64          // Moving average in requests per minute
+}
+
+// add counts requests in the bucket in progress
+func (w *rateWindow) add(count int) {
+	w.current += count
+}
+
+// rotate completes the bucket in progress and returns the updated rates
+func (w *rateWindow) rotate() RequestRates {
+	w.buckets[w.next] = w.current
+	w.next = (w.next + 1) % rateBuckets
+	w.filled = min(w.filled+1, rateBuckets)
+
+	w.ewma += ewmaAlpha * (perMinute(w.current, 1) - w.ewma)
+	w.current = 0
+
+	return RequestRates{
+		OneMinute:   w.rate(RateWindowShort),
+		FiveMinutes: w.rate(RateWindowLong),
+		EWMA:        w.ewma,
+	}
+}
+
+// rate returns the requests per minute over the most recent completed buckets of a window.
+// Until the window is filled, the rate is averaged over the buckets completed so far.
+func (w *rateWindow) rate(window time.Duration) float64 {
+	n := min(int(window/TickInterval), w.filled)
+	if n == 0 {
+		return 0
+	}
+
+	sum := 0
+	for i := 1; i <= n; i++ {
+		sum += w.buckets[(w.next-i+rateBuckets)%rateBuckets]
+	}
+	return perMinute(sum, n)
+}
+
+// perMinute converts a count over a number of tick buckets to a rate per minute
+func perMinute(count, buckets int) float64 {
+	return float64(count) * time.Minute.Seconds() / (float64(buckets) * TickInterval.Seconds())
+}
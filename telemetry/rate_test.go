@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateWindow_Empty(t *testing.T) {
+	var window rateWindow
+	require.Equal(t, 0.0, window.rate(RateWindowShort))
+
+	rates := window.rotate()
+	require.Equal(t, RequestRates{}, rates)
+}
+
+func TestRateWindow_SteadyTraffic(t *testing.T) {
+	var window rateWindow
+
+	// One request per tick of 5 seconds is 12 RPM in every window
+	var rates RequestRates
+	for i := 0; i < rateBuckets; i++ {
+		window.add(1)
+		rates = window.rotate()
+	}
+	require.InDelta(t, 12, rates.OneMinute, 0.001)
+	require.InDelta(t, 12, rates.FiveMinutes, 0.001)
+	require.InDelta(t, 12, rates.EWMA, 0.1, "Expected the moving average to converge after five time constants")
+}
+
+func TestRateWindow_LowRates(t *testing.T) {
+	var window rateWindow
+
+	// A single request per minute is not rounded away
+	var rates RequestRates
+	for i := 0; i < rateBuckets; i++ {
+		if i%12 == 0 {
+			window.add(1)
+		}
+		rates = window.rotate()
+	}
+	require.InDelta(t, 1, rates.OneMinute, 0.001)
+	require.InDelta(t, 1, rates.FiveMinutes, 0.001)
+}
+
+func TestRateWindow_Wraparound(t *testing.T) {
+	var window rateWindow
+
+	// Fill the ring with a burst followed by silence, then overwrite the burst
+	window.add(rateBuckets)
+	window.rotate()
+	for i := 1; i < rateBuckets; i++ {
+		window.rotate()
+	}
+	require.InDelta(t, 12, window.rate(RateWindowLong), 0.001)
+
+	rates := window.rotate()
+	require.Equal(t, 0.0, rates.FiveMinutes, "Expected the burst to leave the window after five minutes")
+	require.Equal(t, rateBuckets, window.filled)
+}
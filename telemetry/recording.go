@@ -0,0 +1,289 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StatsFrame is a snapshot of all stats at a point in time, as recorded to a stats file
+type StatsFrame struct {
+	Time   time.Time `json:"time"`
+	Stats  Stats     `json:"stats"`
+	Events []string  `json:"events,omitempty"` // Events like deployments that happened since the previous frame
+}
+
+// StatsRecorder appends a frame of the collector's stats to a JSON lines file every interval
+type StatsRecorder struct {
+	collector StatsCollector
+	path      string
+
+	mu     sync.Mutex
+	file   *os.File
+	events []string
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	done    chan struct{}
+}
+
+// NewStatsRecorder creates a new recording file in dir, named after the current time
+func NewStatsRecorder(collector StatsCollector, dir string) (*StatsRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create stats directory: %w", err)
+	}
+
+	path := filepath.Join(dir, time.Now().Format("20060102-150405")+".jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("could not open stats file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StatsRecorder{
+		collector: collector,
+		path:      path,
+		file:      file,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Path returns the file stats are recorded to, or an empty string if stats are not recorded
+func (r *StatsRecorder) Path() string {
+	if r == nil {
+		return ""
+	}
+	return r.path
+}
+
+// Start records a frame every interval until the recorder is closed
+func (r *StatsRecorder) Start(interval time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.started = true
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case now := <-ticker.C:
+				_ = r.record(now)
+			}
+		}
+	}()
+}
+
+// RecordEvent adds an event to the next recorded frame
+func (r *StatsRecorder) RecordEvent(event string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+// record appends a frame of the current stats and all events since the previous frame
+func (r *StatsRecorder) record(now time.Time) error {
+	stats := r.collector.Export()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(StatsFrame{Time: now, Stats: stats, Events: r.events})
+	if err != nil {
+		return fmt.Errorf("could not encode stats frame: %w", err)
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write stats frame: %w", err)
+	}
+
+	r.events = nil
+	return nil
+}
+
+// Close records a final frame and closes the file
+func (r *StatsRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.cancel()
+	if r.started {
+		<-r.done
+	}
+
+	recordErr := r.record(time.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return recordErr
+	}
+	err := r.file.Close()
+	r.file = nil
+	return errors.Join(recordErr, err)
+}
+
+// LoadRecording reads all frames of a stats file.
+// Lines that cannot be decoded, e.g. a partially written last line, are skipped.
+func LoadRecording(path string) ([]StatsFrame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open stats file: %w", err)
+	}
+	defer file.Close()
+
+	var frames []StatsFrame
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var frame StatsFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read stats file: %w", err)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("stats file %s contains no frames", path)
+	}
+
+	return frames, nil
+}
+
+// ReplayStatsCollector serves recorded stats frame by frame instead of collecting new stats
+type ReplayStatsCollector struct {
+	mu     sync.RWMutex
+	frames []StatsFrame
+	index  int
+}
+
+// NewReplayStatsCollector creates a collector positioned at the first of the recorded frames
+func NewReplayStatsCollector(frames []StatsFrame) *ReplayStatsCollector {
+	return &ReplayStatsCollector{frames: frames}
+}
+
+// Len returns the number of recorded frames
+func (c *ReplayStatsCollector) Len() int {
+	return len(c.frames)
+}
+
+// Position returns the index of the current frame
+func (c *ReplayStatsCollector) Position() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.index
+}
+
+// Frame returns a recorded frame
+func (c *ReplayStatsCollector) Frame(index int) StatsFrame {
+	return c.frames[index]
+}
+
+// Seek moves to a frame, clamped to the recorded frames
+func (c *ReplayStatsCollector) Seek(index int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index = min(max(index, 0), len(c.frames)-1)
+}
+
+// SeekEvent moves to the next frame with events in the given direction, staying in place if there is none
+func (c *ReplayStatsCollector) SeekEvent(direction int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := c.index + direction; i >= 0 && i < len(c.frames); i += direction {
+		if len(c.frames[i].Events) > 0 {
+			c.index = i
+			return
+		}
+	}
+}
+
+// EventsUntil returns the events of all frames up to and including the current frame, oldest first
+func (c *ReplayStatsCollector) EventsUntil() []TimedEvent {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var events []TimedEvent
+	for _, frame := range c.frames[:c.index+1] {
+		for _, event := range frame.Events {
+			events = append(events, TimedEvent{Time: frame.Time, Event: event})
+		}
+	}
+	return events
+}
+
+// TimedEvent is a recorded event and the time of the frame it was recorded in
+type TimedEvent struct {
+	Time  time.Time
+	Event string
+}
+
+func (c *ReplayStatsCollector) TrackAPIRequest(method string, path string, duration time.Duration, responseStatusCode int) error {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackProxyAccess(operation string, duration time.Duration, proxyID int, status ProxyAccessStatus) error {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackDataStoreAccess(operation string, duration time.Duration, storeID string, status DataStoreAccessStatus) error {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackNoteCount(shardID string, count int) error {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackConsistencyMiss(miss ConsistencyMiss) error {
+	return nil
+}
+
+// RecentConsistencyMisses returns nothing, as recordings only contain the number of misses
+func (c *ReplayStatsCollector) RecentConsistencyMisses() []ConsistencyMiss {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackShadowRead(accountID string, operation string, match bool) error {
+	return nil
+}
+
+func (c *ReplayStatsCollector) TrackInjectedFault(fault string, target string, proxyID int) error {
+	return nil
+}
+
+// Export returns the stats of the current frame
+func (c *ReplayStatsCollector) Export() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.frames[c.index].Stats
+}
+
+func (c *ReplayStatsCollector) Import(sourceID string, stats Stats) {}
+
+func (c *ReplayStatsCollector) Stop() {}
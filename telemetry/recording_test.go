@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRecordingRoundTrip(t *testing.T) {
+	collector := NewStatsCollector(WithAutoStart(false))
+	defer collector.Stop()
+
+	recorder, err := NewStatsRecorder(collector, t.TempDir())
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, collector.TrackAPIRequest("GET", "/accounts", 5*time.Millisecond, 200))
+	require.NoError(t, recorder.record(start))
+
+	recorder.RecordEvent("Rolling out proxy v2")
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{}))
+	require.NoError(t, collector.TrackAPIRequest("GET", "/accounts", 7*time.Millisecond, 200))
+	require.NoError(t, recorder.record(start.Add(TickInterval)))
+
+	// Closing records a final frame
+	require.NoError(t, recorder.Close())
+
+	// A partially written line is skipped
+	file, err := os.OpenFile(recorder.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"time":"2025-`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	frames, err := LoadRecording(recorder.Path())
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+
+	require.Empty(t, frames[0].Events)
+	require.Equal(t, 1, frames[0].Stats.APIRequests["GET-/accounts-200"].Metrics.TotalCount)
+	require.Equal(t, []string{"Rolling out proxy v2"}, frames[1].Events)
+	require.Equal(t, 1, frames[1].Stats.ConsistencyMisses)
+	require.Equal(t, 2, frames[1].Stats.APIRequests["GET-/accounts-200"].Metrics.Latency.Count)
+	require.Empty(t, frames[2].Events, "events are recorded once")
+}
+
+func TestReplayStatsCollector(t *testing.T) {
+	start := time.Now()
+	frames := []StatsFrame{
+		{Time: start, Stats: Stats{ConsistencyMisses: 0}},
+		{Time: start.Add(5 * time.Second), Stats: Stats{ConsistencyMisses: 1}, Events: []string{"Rolling out proxy v2"}},
+		{Time: start.Add(10 * time.Second), Stats: Stats{ConsistencyMisses: 4}},
+		{Time: start.Add(15 * time.Second), Stats: Stats{ConsistencyMisses: 4}, Events: []string{"Shut down proxy v1"}},
+	}
+
+	replay := NewReplayStatsCollector(frames)
+	require.Equal(t, 4, replay.Len())
+	require.Equal(t, 0, replay.Position())
+	require.Empty(t, replay.EventsUntil())
+
+	replay.SeekEvent(1)
+	require.Equal(t, 1, replay.Position())
+	require.Equal(t, 1, replay.Export().ConsistencyMisses)
+
+	replay.SeekEvent(1)
+	require.Equal(t, 3, replay.Position())
+	events := replay.EventsUntil()
+	require.Len(t, events, 2)
+	require.Equal(t, "Shut down proxy v1", events[1].Event)
+	require.True(t, events[1].Time.Equal(frames[3].Time))
+
+	// There is no later event, and seeking is clamped to the recording
+	replay.SeekEvent(1)
+	require.Equal(t, 3, replay.Position())
+	replay.Seek(-5)
+	require.Equal(t, 0, replay.Position())
+	replay.Seek(100)
+	require.Equal(t, 3, replay.Position())
+
+	// Tracking does not change recorded stats
+	require.NoError(t, replay.TrackConsistencyMiss(ConsistencyMiss{}))
+	require.Equal(t, 4, replay.Export().ConsistencyMisses)
+}
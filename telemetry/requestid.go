@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request in responses and calls to data proxies
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDLogKey is the attribute key request IDs are logged under
+const RequestIDLogKey = "requestID"
+
+// maxRequestIDLength limits the length of request IDs accepted from clients
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// NewRequestID generates a new request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID of the context, or an empty string if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// ValidRequestID reports whether a request ID sent by a client can be used as is.
+// IDs are limited to printable ASCII without spaces so they are safe to log and filter on.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// InjectRequestID adds the context's request ID to outgoing request headers
+func InjectRequestID(ctx context.Context, header http.Header) {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		header.Set(RequestIDHeader, requestID)
+	}
+}
+
+// ContextHandler adds the request ID of the context to every log record.
+// Records are only correlated when logged using the Context variants of the slog methods.
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps a handler to add request IDs to its records
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+// Handle adds the request ID to the record and passes it on
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(RequestIDLogKey, requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler that keeps adding request IDs
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that keeps adding request IDs
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidRequestID(t *testing.T) {
+	require.True(t, ValidRequestID(NewRequestID()))
+	require.True(t, ValidRequestID("checkout-42"))
+
+	require.False(t, ValidRequestID(""))
+	require.False(t, ValidRequestID("two words"))
+	require.False(t, ValidRequestID("line\nbreak"))
+	require.False(t, ValidRequestID("emoji-🙂"))
+	require.False(t, ValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
+
+func TestContextHandlerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.InfoContext(ctx, "handled")
+	require.Contains(t, buf.String(), "component=test")
+	require.Contains(t, buf.String(), RequestIDLogKey+"=req-1")
+
+	// Records logged without a request ID are left unchanged
+	buf.Reset()
+	logger.Info("background")
+	require.NotContains(t, buf.String(), RequestIDLogKey)
+
+	// The request ID is forwarded to data proxies
+	header := http.Header{}
+	InjectRequestID(ctx, header)
+	require.Equal(t, "req-1", header.Get(RequestIDHeader))
+
+	header = http.Header{}
+	InjectRequestID(context.Background(), header)
+	require.Empty(t, header.Get(RequestIDHeader))
+}
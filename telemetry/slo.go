@@ -0,0 +1,448 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSLOWindow is the window the error budget of an SLO is spent over
+	DefaultSLOWindow = RateWindowLong
+	// DefaultSLOBurnWindow is the recent window burn rates are calculated over
+	DefaultSLOBurnWindow = RateWindowShort
+	// DefaultSLOMaxBurnRate is the burn rate at which an SLO is violated, spending the budget twice as fast as allowed
+	DefaultSLOMaxBurnRate = 2
+)
+
+// SLOKind defines what makes a request good or bad
+type SLOKind string
+
+const (
+	SLOKindLatency      SLOKind = "latency"      // Requests must complete within the threshold
+	SLOKindAvailability SLOKind = "availability" // Requests must not fail, i.e. 5xx for the API and errors for proxies
+	SLOKindConsistency  SLOKind = "consistency"  // At most MaxMisses consistency misses per window
+)
+
+// SLOScope defines which stats an SLO is evaluated from
+type SLOScope string
+
+const (
+	SLOScopeAPI   SLOScope = "api"
+	SLOScopeProxy SLOScope = "proxy"
+)
+
+// Duration is a time.Duration encoded as a string like "50ms" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"50ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// SLO is a service level objective, e.g. 99% of requests to a route complete within 50ms
+type SLO struct {
+	Name        string   `json:"name"`
+	Kind        SLOKind  `json:"kind"`
+	Scope       SLOScope `json:"scope,omitempty"`       // Defaults to api, ignored for consistency
+	Method      string   `json:"method,omitempty"`      // API method, empty for all
+	Route       string   `json:"route,omitempty"`       // API route pattern like /accounts/{accountId}/notes, empty for all
+	Operation   string   `json:"operation,omitempty"`   // Proxy operation like GetNote, empty for all
+	Objective   float64  `json:"objective,omitempty"`   // Fraction of good requests, e.g. 0.99
+	Threshold   Duration `json:"threshold,omitempty"`   // Latency a good request completes within
+	MaxMisses   int      `json:"maxMisses,omitempty"`   // Consistency misses allowed per window
+	Window      Duration `json:"window,omitempty"`      // Window the error budget is spent over
+	BurnWindow  Duration `json:"burnWindow,omitempty"`  // Recent window the burn rate is calculated over
+	MaxBurnRate float64  `json:"maxBurnRate,omitempty"` // Burn rate at which the SLO is violated
+}
+
+// sloFile is the format of the file passed to --slo-file
+type sloFile struct {
+	SLOs []SLO `json:"slos"`
+}
+
+// LoadSLOs reads SLO definitions from a JSON file, applying defaults
+func LoadSLOs(path string) ([]SLO, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read SLO file: %w", err)
+	}
+
+	var file sloFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not decode SLO file: %w", err)
+	}
+
+	names := make(map[string]bool, len(file.SLOs))
+	for i := range file.SLOs {
+		slo := &file.SLOs[i]
+		slo.applyDefaults()
+		if err := slo.validate(); err != nil {
+			return nil, fmt.Errorf("invalid SLO %q: %w", slo.Name, err)
+		}
+		if names[slo.Name] {
+			return nil, fmt.Errorf("duplicate SLO %q", slo.Name)
+		}
+		names[slo.Name] = true
+	}
+
+	return file.SLOs, nil
+}
+
+func (s *SLO) applyDefaults() {
+	if s.Scope == "" {
+		s.Scope = SLOScopeAPI
+	}
+	if s.Window == 0 {
+		s.Window = Duration(DefaultSLOWindow)
+	}
+	if s.BurnWindow == 0 {
+		s.BurnWindow = Duration(DefaultSLOBurnWindow)
+	}
+	if s.MaxBurnRate == 0 {
+		s.MaxBurnRate = DefaultSLOMaxBurnRate
+	}
+}
+
+func (s *SLO) validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch s.Kind {
+	case SLOKindLatency:
+		if s.Threshold <= 0 {
+			return errors.New("latency SLOs require a threshold")
+		}
+		fallthrough
+	case SLOKindAvailability:
+		if s.Objective <= 0 || s.Objective > 1 {
+			return fmt.Errorf("objective must be between 0 and 1, got %v", s.Objective)
+		}
+	case SLOKindConsistency:
+		if s.MaxMisses < 0 {
+			return errors.New("maxMisses must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", s.Kind)
+	}
+
+	if s.Scope != SLOScopeAPI && s.Scope != SLOScopeProxy {
+		return fmt.Errorf("unknown scope %q", s.Scope)
+	}
+	if s.BurnWindow > s.Window {
+		return errors.New("burnWindow must not be longer than window")
+	}
+	return nil
+}
+
+// PerProxy returns whether the SLO is evaluated from the requests of each proxy
+func (s SLO) PerProxy() bool {
+	return s.Scope == SLOScopeProxy && s.Kind != SLOKindConsistency
+}
+
+// counts returns the cumulative number of bad and total requests the SLO covers. For proxy-scope SLOs,
+// a proxy ID other than zero only counts the requests of that proxy.
+func (s SLO) counts(stats Stats, proxyID int) (bad, total int) {
+	if s.Kind == SLOKindConsistency {
+		return stats.ConsistencyMisses, 0
+	}
+
+	count := func(metrics RequestMetrics, failed bool) {
+		switch s.Kind {
+		case SLOKindLatency:
+			// Durations in the bucket containing the threshold count as too slow
+			total += metrics.Latency.Count
+			bad += metrics.Latency.Count - metrics.Latency.CountAtOrBelow(time.Duration(s.Threshold))
+		case SLOKindAvailability:
+			total += metrics.TotalCount
+			if failed {
+				bad += metrics.TotalCount
+			}
+		}
+	}
+
+	switch s.Scope {
+	case SLOScopeProxy:
+		for _, proxyStats := range stats.ProxyAccess {
+			// Calls rejected by contention were not handled by the proxy and are retried
+			if proxyStats.Status == ProxyAccessStatusContention {
+				continue
+			}
+			if proxyID != 0 && proxyStats.ProxyID != proxyID {
+				continue
+			}
+			if s.Operation == "" || proxyStats.Operation == s.Operation {
+				count(proxyStats.Metrics, proxyStats.Status == ProxyAccessStatusError)
+			}
+		}
+	default:
+		for _, apiStats := range stats.APIRequests {
+			if (s.Method == "" || apiStats.Method == s.Method) && (s.Route == "" || apiStats.Route == s.Route) {
+				count(apiStats.Metrics, apiStats.Status >= 500)
+			}
+		}
+	}
+	return bad, total
+}
+
+// SLOStatus is the result of evaluating an SLO over its windows
+type SLOStatus struct {
+	SLO             SLO
+	Bad             int     // Bad requests or consistency misses within the window
+	Total           int     // Requests within the window, zero for consistency SLOs
+	BudgetRemaining float64 // Fraction of the error budget left in the window, negative once overspent
+	BurnRate        float64 // How many times faster than allowed the budget was spent within the burn window
+	Violated        bool    // Whether the budget is spent or burning faster than allowed
+}
+
+// sloSample holds the cumulative counts of all SLOs at a point in time
+type sloSample struct {
+	time  time.Time
+	bad   []int
+	total []int
+}
+
+// SLOEvaluator continuously evaluates SLOs from the stats of a collector
+type SLOEvaluator struct {
+	collector StatsCollector
+	slos      []SLO
+	history   time.Duration
+
+	mu       sync.RWMutex
+	samples  []sloSample
+	statuses []SLOStatus
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	done    chan struct{}
+}
+
+// NewSLOEvaluator creates an evaluator for SLOs loaded with LoadSLOs
+func NewSLOEvaluator(collector StatsCollector, slos []SLO) *SLOEvaluator {
+	var history time.Duration
+	for _, slo := range slos {
+		history = max(history, time.Duration(slo.Window))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SLOEvaluator{
+		collector: collector,
+		slos:      slos,
+		history:   history,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start evaluates all SLOs every interval until the evaluator is stopped
+func (e *SLOEvaluator) Start(interval time.Duration) {
+	if e == nil {
+		return
+	}
+
+	e.started = true
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		e.Evaluate(time.Now())
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case now := <-ticker.C:
+				e.Evaluate(now)
+			}
+		}
+	}()
+}
+
+// Stop ends periodic evaluation
+func (e *SLOEvaluator) Stop() {
+	if e == nil {
+		return
+	}
+
+	e.cancel()
+	if e.started {
+		<-e.done
+	}
+}
+
+// Evaluate samples the current stats and updates the status of all SLOs
+func (e *SLOEvaluator) Evaluate(now time.Time) []SLOStatus {
+	stats := e.collector.Export()
+
+	sample := sloSample{time: now, bad: make([]int, len(e.slos)), total: make([]int, len(e.slos))}
+	for i, slo := range e.slos {
+		sample.bad[i], sample.total[i] = slo.counts(stats, 0)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Keep one sample older than the longest window to compare against
+	e.samples = append(e.samples, sample)
+	for len(e.samples) > 1 && now.Sub(e.samples[1].time) >= e.history {
+		e.samples = e.samples[1:]
+	}
+
+	statuses := make([]SLOStatus, len(e.slos))
+	for i, slo := range e.slos {
+		bad, total := e.delta(i, now, time.Duration(slo.Window))
+		burnBad, burnTotal := e.delta(i, now, time.Duration(slo.BurnWindow))
+		statuses[i] = slo.status(bad, total, burnBad, burnTotal)
+	}
+	e.statuses = statuses
+
+	return statuses
+}
+
+// status evaluates an SLO from its counts within its window and burn window
+func (s SLO) status(bad, total, burnBad, burnTotal int) SLOStatus {
+	status := SLOStatus{SLO: s, Bad: bad, Total: total}
+	if s.Kind == SLOKindConsistency {
+		// The allowed misses are spread evenly across the window
+		allowedInBurnWindow := float64(s.MaxMisses) * float64(s.BurnWindow) / float64(s.Window)
+		status.BudgetRemaining = budgetRemaining(float64(bad), float64(s.MaxMisses))
+		status.BurnRate = burnRate(float64(burnBad), allowedInBurnWindow)
+	} else {
+		allowed := 1 - s.Objective
+		status.BudgetRemaining = budgetRemaining(float64(bad), allowed*float64(total))
+		status.BurnRate = burnRate(float64(burnBad), allowed*float64(burnTotal))
+	}
+	status.Violated = status.BudgetRemaining <= 0 || status.BurnRate >= s.MaxBurnRate
+	return status
+}
+
+// delta returns the growth of an SLO's counts within a window, starting at the oldest sample within the window.
+// Counters shrinking after a restart of the collector are counted from zero.
+func (e *SLOEvaluator) delta(index int, now time.Time, window time.Duration) (bad, total int) {
+	latest := e.samples[len(e.samples)-1]
+	start := e.samples[0]
+	for _, sample := range e.samples {
+		if now.Sub(sample.time) <= window {
+			break
+		}
+		start = sample
+	}
+	return counterDelta(latest.bad[index], start.bad[index]), counterDelta(latest.total[index], start.total[index])
+}
+
+// budgetRemaining returns the fraction of an error budget left, which is all or nothing for a budget of zero
+func budgetRemaining(bad, allowed float64) float64 {
+	if allowed == 0 {
+		if bad > 0 {
+			return 0
+		}
+		return 1
+	}
+	return 1 - bad/allowed
+}
+
+// burnRate returns how many times the allowed number of bad requests occurred, which is infinite for a budget of zero
+func burnRate(bad, allowed float64) float64 {
+	if bad == 0 {
+		return 0
+	}
+	if allowed == 0 {
+		return math.Inf(1)
+	}
+	return bad / allowed
+}
+
+// Statuses returns the result of the latest evaluation, in the order SLOs were defined
+func (e *SLOEvaluator) Statuses() []SLOStatus {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]SLOStatus(nil), e.statuses...)
+}
+
+// Violations returns the statuses of all SLOs violated in the latest evaluation
+func (e *SLOEvaluator) Violations() []SLOStatus {
+	var violations []SLOStatus
+	for _, status := range e.Statuses() {
+		if status.Violated {
+			violations = append(violations, status)
+		}
+	}
+	return violations
+}
+
+// ProxySLOWatch evaluates the proxy-scope SLOs against the requests a single proxy handled since the watch started
+type ProxySLOWatch struct {
+	evaluator *SLOEvaluator
+	proxyID   int
+	bad       []int
+	total     []int
+}
+
+// WatchProxy starts watching the proxy-scope SLOs for a proxy, e.g. while rolling it out
+func (e *SLOEvaluator) WatchProxy(proxyID int) *ProxySLOWatch {
+	if e == nil {
+		return nil
+	}
+
+	w := &ProxySLOWatch{evaluator: e, proxyID: proxyID}
+	w.bad, w.total = w.counts()
+	return w
+}
+
+func (w *ProxySLOWatch) counts() (bad, total []int) {
+	stats := w.evaluator.collector.Export()
+	bad = make([]int, len(w.evaluator.slos))
+	total = make([]int, len(w.evaluator.slos))
+	for i, slo := range w.evaluator.slos {
+		if slo.PerProxy() {
+			bad[i], total[i] = slo.counts(stats, w.proxyID)
+		}
+	}
+	return bad, total
+}
+
+// Violations returns the proxy-scope SLOs the proxy violated since the watch started.
+// The watch is expected to be shorter than the burn window, so both are evaluated over the whole watch.
+func (w *ProxySLOWatch) Violations() []SLOStatus {
+	if w == nil {
+		return nil
+	}
+
+	bad, total := w.counts()
+	var violations []SLOStatus
+	for i, slo := range w.evaluator.slos {
+		if !slo.PerProxy() {
+			continue
+		}
+		badDelta, totalDelta := counterDelta(bad[i], w.bad[i]), counterDelta(total[i], w.total[i])
+		if status := slo.status(badDelta, totalDelta, badDelta, totalDelta); status.Violated {
+			violations = append(violations, status)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,212 @@
+package telemetry
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeSLOFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "slos.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadSLOs(t *testing.T) {
+	path := writeSLOFile(t, `{"slos": [
+		{"name": "get note latency", "kind": "latency", "method": "GET", "route": "/accounts/{accountId}/notes/{noteId}", "objective": 0.99, "threshold": "50ms"},
+		{"name": "no consistency misses", "kind": "consistency", "window": "10m"}
+	]}`)
+
+	slos, err := LoadSLOs(path)
+	require.NoError(t, err)
+	require.Len(t, slos, 2)
+
+	require.Equal(t, Duration(50*time.Millisecond), slos[0].Threshold)
+	require.Equal(t, SLOScopeAPI, slos[0].Scope, "Expected scope to default to api")
+	require.Equal(t, Duration(DefaultSLOWindow), slos[0].Window)
+	require.Equal(t, Duration(DefaultSLOBurnWindow), slos[0].BurnWindow)
+	require.Equal(t, float64(DefaultSLOMaxBurnRate), slos[0].MaxBurnRate)
+
+	require.Equal(t, Duration(10*time.Minute), slos[1].Window)
+}
+
+func TestLoadSLOs_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing name", `{"slos": [{"kind": "availability", "objective": 0.9}]}`},
+		{"unknown kind", `{"slos": [{"name": "a", "kind": "throughput"}]}`},
+		{"objective out of range", `{"slos": [{"name": "a", "kind": "availability", "objective": 99}]}`},
+		{"latency without threshold", `{"slos": [{"name": "a", "kind": "latency", "objective": 0.9}]}`},
+		{"invalid duration", `{"slos": [{"name": "a", "kind": "latency", "objective": 0.9, "threshold": "fast"}]}`},
+		{"unknown scope", `{"slos": [{"name": "a", "kind": "availability", "objective": 0.9, "scope": "db"}]}`},
+		{"duplicate name", `{"slos": [{"name": "a", "kind": "consistency"}, {"name": "a", "kind": "consistency"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSLOs(writeSLOFile(t, tt.content))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSLOEvaluator_Latency(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	slo := SLO{Name: "latency", Kind: SLOKindLatency, Method: "GET", Route: "/notes", Objective: 0.9, Threshold: Duration(50 * time.Millisecond)}
+	slo.applyDefaults()
+	evaluator := NewSLOEvaluator(collector, []SLO{slo})
+
+	start := time.Now()
+	evaluator.Evaluate(start)
+
+	// 1 of 20 requests is too slow, spending half of the 10% budget
+	for i := 0; i < 19; i++ {
+		require.NoError(t, collector.TrackAPIRequest("GET", "/notes", 10*time.Millisecond, 200))
+	}
+	require.NoError(t, collector.TrackAPIRequest("GET", "/notes", 200*time.Millisecond, 200))
+	// Requests to other routes are not covered
+	require.NoError(t, collector.TrackAPIRequest("GET", "/other", time.Second, 200))
+
+	statuses := evaluator.Evaluate(start.Add(TickInterval))
+	require.Len(t, statuses, 1)
+	require.Equal(t, 1, statuses[0].Bad)
+	require.Equal(t, 20, statuses[0].Total)
+	require.InDelta(t, 0.5, statuses[0].BudgetRemaining, 0.001)
+	require.InDelta(t, 0.5, statuses[0].BurnRate, 0.001)
+	require.False(t, statuses[0].Violated)
+	require.Empty(t, evaluator.Violations())
+
+	// A burst of slow requests burns the budget faster than allowed
+	for i := 0; i < 5; i++ {
+		require.NoError(t, collector.TrackAPIRequest("GET", "/notes", 200*time.Millisecond, 200))
+	}
+	statuses = evaluator.Evaluate(start.Add(2 * TickInterval))
+	require.Equal(t, 6, statuses[0].Bad)
+	require.Less(t, statuses[0].BudgetRemaining, 0.0)
+	require.True(t, statuses[0].Violated)
+	require.Len(t, evaluator.Violations(), 1)
+}
+
+func TestSLOEvaluator_BurnWindow(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	slo := SLO{Name: "availability", Kind: SLOKindAvailability, Scope: SLOScopeProxy, Operation: "GetNote", Objective: 0.5}
+	slo.applyDefaults()
+	evaluator := NewSLOEvaluator(collector, []SLO{slo})
+
+	start := time.Now()
+	evaluator.Evaluate(start)
+
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusError))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusSuccess))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusSuccess))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusSuccess))
+	evaluator.Evaluate(start.Add(TickInterval))
+
+	// Two minutes later, the failure is outside the burn window but still spends the budget of the five minute window
+	status := evaluator.Evaluate(start.Add(2 * time.Minute))[0]
+	require.Equal(t, 1, status.Bad)
+	require.Equal(t, 4, status.Total)
+	require.InDelta(t, 0.5, status.BudgetRemaining, 0.001)
+	require.Equal(t, 0.0, status.BurnRate)
+
+	// After the window has passed, the budget is restored
+	status = evaluator.Evaluate(start.Add(DefaultSLOWindow + 2*time.Minute))[0]
+	require.Equal(t, 0, status.Bad)
+	require.Equal(t, 1.0, status.BudgetRemaining)
+}
+
+func TestSLOEvaluator_ProxyContention(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	slo := SLO{Name: "availability", Kind: SLOKindAvailability, Scope: SLOScopeProxy, Objective: 0.9}
+	slo.applyDefaults()
+	evaluator := NewSLOEvaluator(collector, []SLO{slo})
+
+	start := time.Now()
+	evaluator.Evaluate(start)
+
+	// Calls rejected by contention are neither good nor bad
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusSuccess))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusContention))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusContention))
+
+	status := evaluator.Evaluate(start.Add(TickInterval))[0]
+	require.Equal(t, 0, status.Bad)
+	require.Equal(t, 1, status.Total)
+}
+
+func TestProxySLOWatch(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	slo := SLO{Name: "availability", Kind: SLOKindAvailability, Scope: SLOScopeProxy, Objective: 0.9}
+	slo.applyDefaults()
+	evaluator := NewSLOEvaluator(collector, []SLO{slo})
+
+	// Proxy 2 already failed before the watch started
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 2, ProxyAccessStatusError))
+	watch := evaluator.WatchProxy(2)
+
+	// Failures of the previous proxy do not count against the watched proxy
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 1, ProxyAccessStatusError))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 2, ProxyAccessStatusSuccess))
+	}
+	require.Empty(t, watch.Violations())
+
+	// Two failures of twelve requests spend more than the 10% budget
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 2, ProxyAccessStatusError))
+	require.NoError(t, collector.TrackProxyAccess("GetNote", time.Millisecond, 2, ProxyAccessStatusError))
+	violations := watch.Violations()
+	require.Len(t, violations, 1)
+	require.Equal(t, 2, violations[0].Bad)
+	require.Equal(t, 12, violations[0].Total)
+
+	var nilEvaluator *SLOEvaluator
+	require.Empty(t, nilEvaluator.WatchProxy(1).Violations())
+}
+
+func TestSLOEvaluator_ZeroConsistencyMisses(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	// Misses before the evaluator started do not count
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{}))
+
+	slo := SLO{Name: "consistency", Kind: SLOKindConsistency}
+	slo.applyDefaults()
+	evaluator := NewSLOEvaluator(collector, []SLO{slo})
+
+	start := time.Now()
+	status := evaluator.Evaluate(start)[0]
+	require.Equal(t, 1.0, status.BudgetRemaining)
+	require.False(t, status.Violated)
+
+	require.NoError(t, collector.TrackConsistencyMiss(ConsistencyMiss{}))
+	status = evaluator.Evaluate(start.Add(TickInterval))[0]
+	require.Equal(t, 1, status.Bad)
+	require.Equal(t, 0.0, status.BudgetRemaining)
+	require.True(t, math.IsInf(status.BurnRate, 1))
+	require.True(t, status.Violated)
+}
+
+func TestSLOEvaluator_Nil(t *testing.T) {
+	var evaluator *SLOEvaluator
+	evaluator.Start(TickInterval)
+	evaluator.Stop()
+	require.Empty(t, evaluator.Statuses())
+	require.Empty(t, evaluator.Violations())
+}
@@ -2,7 +2,8 @@ package telemetry
 
 import (
 	"context"
-	"sort"
+	"maps"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -10,8 +11,8 @@ import (
 
 const (
 	TickInterval = 5 * time.Second
-	// SecondsPerMinute converts tick interval to per-minute calculations
-	SecondsPerMinute = 60
+	// ImportSourceRetention is how long the last snapshot of a silent import source is kept for computing deltas
+	ImportSourceRetention = 15 * time.Minute
 )
 
 type DataStoreAccessStatus int
@@ -22,6 +23,19 @@ const (
 	DataStoreAccessStatusError
 )
 
+func (s DataStoreAccessStatus) String() string {
+	switch s {
+	case DataStoreAccessStatusSuccess:
+		return "success"
+	case DataStoreAccessStatusContention:
+		return "contention"
+	case DataStoreAccessStatusError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 type ProxyAccessStatus int
 
 const (
@@ -30,25 +44,41 @@ const (
 	ProxyAccessStatusError
 )
 
+func (s ProxyAccessStatus) String() string {
+	switch s {
+	case ProxyAccessStatusSuccess:
+		return "success"
+	case ProxyAccessStatusContention:
+		return "contention"
+	case ProxyAccessStatusError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 // StatsCollector defines the interface for collecting metrics
 type StatsCollector interface {
 	TrackAPIRequest(method string, path string, duration time.Duration, responseStatusCode int) error
 	TrackProxyAccess(operation string, duration time.Duration, proxyID int, status ProxyAccessStatus) error
 	TrackDataStoreAccess(operation string, duration time.Duration, storeID string, status DataStoreAccessStatus) error
 	TrackNoteCount(shardID string, count int) error
-	TrackConsistencyMiss() error
+	TrackConsistencyMiss(miss ConsistencyMiss) error
+	RecentConsistencyMisses() []ConsistencyMiss // Newest first, up to ConsistencyMissBufferSize
+	TrackShadowRead(accountID string, operation string, match bool) error
+	TrackInjectedFault(fault string, target string, proxyID int) error
 	Export() Stats
-	Import(stats Stats)
+	Import(sourceID string, stats Stats)
 	Stop() // Gracefully shut down the stats collector
 }
 
 // RequestMetrics holds metrics for a specific request type
 type RequestMetrics struct {
-	TotalCount       int   `json:"totalCount"`     // Total count of requests. Only goes up.
-	RequestsPerMin   int   `json:"requestsPerMin"` // Recent requests per minute
-	DurationP95      int   `json:"durationP95"`    // Recent p95 duration in milliseconds
-	currentCount     int   // Request count in current time window
-	currentDurations []int // Recent durations in milliseconds
+	TotalCount     int          `json:"totalCount"`     // Total count of requests. Only goes up.
+	RequestsPerMin int          `json:"requestsPerMin"` // Requests per minute over the last minute, rounded
+	Rates          RequestRates `json:"rates"`          // Requests per minute over sliding windows
+	Latency        Histogram    `json:"latency"`        // All durations since the collector started
+	window         rateWindow   // Request counts of recent ticks
 }
 
 // APIStats holds API request metrics
@@ -75,21 +105,83 @@ type DataStoreStats struct {
 	Metrics   RequestMetrics        `json:"metrics"`
 }
 
+// ShadowReadHistory is how long shadow reads are counted per minute, so recent reads can be told from older ones
+const ShadowReadHistory = 10 * time.Minute
+
+// ShadowReadStats holds shadow read comparison results for an account and operation
+type ShadowReadStats struct {
+	AccountID      string        `json:"accountId"`
+	Operation      string        `json:"operation"`
+	TotalCount     int           `json:"totalCount"`
+	Mismatches     int           `json:"mismatches"`
+	LastMismatchAt *time.Time    `json:"lastMismatchAt,omitempty"`
+	RecentReads    map[int64]int `json:"recentReads,omitempty"` // Reads per minute of the last ShadowReadHistory, keyed by the Unix time the minute started
+}
+
+// ReadsSince counts the recent reads in minutes that started at or after the given time.
+// Reads in the minute the time falls into are left out, so reads before it are never counted.
+func (s *ShadowReadStats) ReadsSince(since time.Time) int {
+	reads := 0
+	for minute, count := range s.RecentReads {
+		if !time.Unix(minute, 0).Before(since) {
+			reads += count
+		}
+	}
+	return reads
+}
+
+// pruneRecentReads drops the reads of minutes older than ShadowReadHistory
+func (s *ShadowReadStats) pruneRecentReads(now time.Time) {
+	oldest := now.Add(-ShadowReadHistory).Unix()
+	maps.DeleteFunc(s.RecentReads, func(minute int64, _ int) bool {
+		return minute < oldest
+	})
+}
+
+// InjectedFaultStats counts the faults a chaos experiment injected into a target of a data proxy,
+// e.g. an RPC method, a note store or the proxy process itself
+type InjectedFaultStats struct {
+	Fault   string `json:"fault"`
+	Target  string `json:"target"`
+	ProxyID int    `json:"proxyId"`
+	Count   int    `json:"count"`
+}
+
 // Stats holds all collected metrics
 type Stats struct {
-	APIRequests       map[string]*APIStats       `json:"apiRequests"`
-	ProxyAccess       map[string]*ProxyStats     `json:"proxyAccess"`
-	DataStoreAccess   map[string]*DataStoreStats `json:"dataStoreAccess"`
-	NoteCount         map[string]int             `json:"noteCount"`
-	ConsistencyMisses int                        `json:"consistencyMisses"`
+	Epoch             int64                          `json:"epoch"` // Identifies the collector instance, changes when a process restarts
+	APIRequests       map[string]*APIStats           `json:"apiRequests"`
+	ProxyAccess       map[string]*ProxyStats         `json:"proxyAccess"`
+	DataStoreAccess   map[string]*DataStoreStats     `json:"dataStoreAccess"`
+	NoteCount         map[string]int                 `json:"noteCount"`
+	ConsistencyMisses int                            `json:"consistencyMisses"`
+	MissesByCheck     map[ConsistencyCheck]int       `json:"missesByCheck"` // Consistency misses counted per check that detected them
+	ShadowReads       map[string]*ShadowReadStats    `json:"shadowReads"`
+	InjectedFaults    map[string]*InjectedFaultStats `json:"injectedFaults"`
+}
+
+// importSource is the last snapshot imported from another collector
+type importSource struct {
+	stats      Stats
+	lastSeen   time.Time
+	noteCounts map[string]noteCount // Note count of every store the source reported
+}
+
+// noteCount is the note count of a store with the time it last changed
+type noteCount struct {
+	count     int
+	changedAt time.Time
 }
 
 // inMemoryStatsCollector implements StatsCollector interface
 type inMemoryStatsCollector struct {
-	stats  Stats
-	mutex  sync.RWMutex
-	ctx    context.Context
-	cancel context.CancelFunc
+	stats      Stats
+	sources    map[string]*importSource
+	noteCounts map[string]noteCount // Note counts tracked by this collector, merged with those of sources
+	misses     []ConsistencyMiss    // Most recent consistency misses, oldest first
+	mutex   sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
 }
 
 // StatsCollectorOption defines a functional option for configuring a StatsCollector
@@ -122,14 +214,20 @@ func NewStatsCollector(options ...StatsCollectorOption) StatsCollector {
 	ctx, cancel := context.WithCancel(context.Background())
 	collector := &inMemoryStatsCollector{
 		stats: Stats{
+			Epoch:             time.Now().UnixNano(),
 			APIRequests:       make(map[string]*APIStats),
 			ProxyAccess:       make(map[string]*ProxyStats),
 			DataStoreAccess:   make(map[string]*DataStoreStats),
 			NoteCount:         make(map[string]int),
 			ConsistencyMisses: 0,
+			MissesByCheck:     make(map[ConsistencyCheck]int),
+			ShadowReads:       make(map[string]*ShadowReadStats),
+			InjectedFaults:    make(map[string]*InjectedFaultStats),
 		},
-		ctx:    ctx,
-		cancel: cancel,
+		sources:    make(map[string]*importSource),
+		noteCounts: make(map[string]noteCount),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 
 	// Start the ticker goroutine only if requested
@@ -141,7 +239,7 @@ func NewStatsCollector(options ...StatsCollectorOption) StatsCollector {
 }
 
 // trackMetric provides common tracking logic for all metric types
-func (sc *inMemoryStatsCollector) trackMetric(keyGen func() string, durationMs int, updateFn func(key string)) error {
+func (sc *inMemoryStatsCollector) trackMetric(keyGen func() string, updateFn func(key string)) error {
 	// Check if context is cancelled first
 	select {
 	case <-sc.ctx.Done():
@@ -160,29 +258,27 @@ func (sc *inMemoryStatsCollector) trackMetric(keyGen func() string, durationMs i
 
 // TrackAPIRequest tracks API request metrics
 func (sc *inMemoryStatsCollector) TrackAPIRequest(method string, path string, duration time.Duration, responseStatusCode int) error {
-	durationMs := int(duration.Milliseconds())
-
 	return sc.trackMetric(
 		func() string {
 			return method + "-" + path + "-" + strconv.Itoa(responseStatusCode)
 		},
-		durationMs,
 		func(key string) {
 			if existing, exists := sc.stats.APIRequests[key]; exists {
 				existing.Metrics.TotalCount++
-				existing.Metrics.currentCount++
-				existing.Metrics.currentDurations = append(existing.Metrics.currentDurations, durationMs)
+				existing.Metrics.window.add(1)
+				existing.Metrics.Latency.Observe(duration)
 			} else {
-				sc.stats.APIRequests[key] = &APIStats{
+				created := &APIStats{
 					Method: method,
 					Route:  path,
 					Status: responseStatusCode,
 					Metrics: RequestMetrics{
-						TotalCount:       1,
-						currentCount:     1,
-						currentDurations: []int{durationMs},
+						TotalCount: 1,
 					},
 				}
+				created.Metrics.window.add(1)
+				created.Metrics.Latency.Observe(duration)
+				sc.stats.APIRequests[key] = created
 			}
 		},
 	)
@@ -190,29 +286,27 @@ func (sc *inMemoryStatsCollector) TrackAPIRequest(method string, path string, du
 
 // TrackProxyAccess tracks proxy access metrics
 func (sc *inMemoryStatsCollector) TrackProxyAccess(operation string, duration time.Duration, proxyID int, status ProxyAccessStatus) error {
-	durationMs := int(duration.Milliseconds())
-
 	return sc.trackMetric(
 		func() string {
 			return operation + "-" + strconv.Itoa(int(status)) + "-" + strconv.Itoa(proxyID)
 		},
-		durationMs,
 		func(key string) {
 			if existing, exists := sc.stats.ProxyAccess[key]; exists {
 				existing.Metrics.TotalCount++
-				existing.Metrics.currentCount++
-				existing.Metrics.currentDurations = append(existing.Metrics.currentDurations, durationMs)
+				existing.Metrics.window.add(1)
+				existing.Metrics.Latency.Observe(duration)
 			} else {
-				sc.stats.ProxyAccess[key] = &ProxyStats{
+				created := &ProxyStats{
 					ProxyID:   proxyID,
 					Operation: operation,
 					Status:    status,
 					Metrics: RequestMetrics{
-						TotalCount:       1,
-						currentCount:     1,
-						currentDurations: []int{durationMs},
+						TotalCount: 1,
 					},
 				}
+				created.Metrics.window.add(1)
+				created.Metrics.Latency.Observe(duration)
+				sc.stats.ProxyAccess[key] = created
 			}
 		},
 	)
@@ -220,47 +314,121 @@ func (sc *inMemoryStatsCollector) TrackProxyAccess(operation string, duration ti
 
 // TrackDataStoreAccess tracks data store access metrics
 func (sc *inMemoryStatsCollector) TrackDataStoreAccess(operation string, duration time.Duration, storeID string, status DataStoreAccessStatus) error {
-	durationMs := int(duration.Milliseconds())
-
 	return sc.trackMetric(
 		func() string {
 			return operation + "-" + strconv.Itoa(int(status)) + "-" + storeID
 		},
-		durationMs,
 		func(key string) {
 			if existing, exists := sc.stats.DataStoreAccess[key]; exists {
 				existing.Metrics.TotalCount++
-				existing.Metrics.currentCount++
-				existing.Metrics.currentDurations = append(existing.Metrics.currentDurations, durationMs)
+				existing.Metrics.window.add(1)
+				existing.Metrics.Latency.Observe(duration)
 			} else {
-				sc.stats.DataStoreAccess[key] = &DataStoreStats{
+				created := &DataStoreStats{
 					StoreID:   storeID,
 					Operation: operation,
 					Status:    status,
 					Metrics: RequestMetrics{
-						TotalCount:       1,
-						currentCount:     1,
-						currentDurations: []int{durationMs},
+						TotalCount: 1,
 					},
 				}
+				created.Metrics.window.add(1)
+				created.Metrics.Latency.Observe(duration)
+				sc.stats.DataStoreAccess[key] = created
 			}
 		},
 	)
 }
 
-func (sc *inMemoryStatsCollector) TrackConsistencyMiss() error {
+// TrackConsistencyMiss counts a consistency miss and keeps its details for inspection
+func (sc *inMemoryStatsCollector) TrackConsistencyMiss(miss ConsistencyMiss) error {
 	sc.mutex.Lock()
 	defer sc.mutex.Unlock()
 
+	if miss.Time.IsZero() {
+		miss.Time = time.Now()
+	}
+
 	sc.stats.ConsistencyMisses++
+	sc.stats.MissesByCheck[miss.Check]++
+	if len(sc.misses) >= ConsistencyMissBufferSize {
+		sc.misses = sc.misses[1:]
+	}
+	sc.misses = append(sc.misses, miss)
 	return nil
 }
 
+// RecentConsistencyMisses returns the details of the most recent consistency misses, newest first
+func (sc *inMemoryStatsCollector) RecentConsistencyMisses() []ConsistencyMiss {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	misses := make([]ConsistencyMiss, len(sc.misses))
+	for i, miss := range sc.misses {
+		misses[len(misses)-1-i] = miss
+	}
+	return misses
+}
+
+// TrackShadowRead tracks the result of comparing a legacy read with the shadow read from the target shard
+func (sc *inMemoryStatsCollector) TrackShadowRead(accountID string, operation string, match bool) error {
+	return sc.trackMetric(
+		func() string {
+			return accountID + "-" + operation
+		},
+		func(key string) {
+			existing, exists := sc.stats.ShadowReads[key]
+			if !exists {
+				existing = &ShadowReadStats{
+					AccountID: accountID,
+					Operation: operation,
+				}
+				sc.stats.ShadowReads[key] = existing
+			}
+
+			now := time.Now()
+			if existing.RecentReads == nil {
+				existing.RecentReads = make(map[int64]int)
+			}
+			existing.RecentReads[now.Truncate(time.Minute).Unix()]++
+			existing.pruneRecentReads(now)
+
+			existing.TotalCount++
+			if !match {
+				existing.Mismatches++
+				existing.LastMismatchAt = &now
+			}
+		},
+	)
+}
+
+// TrackInjectedFault counts a fault injected into a target of a data proxy
+func (sc *inMemoryStatsCollector) TrackInjectedFault(fault string, target string, proxyID int) error {
+	return sc.trackMetric(
+		func() string {
+			return fault + "-" + target + "-" + strconv.Itoa(proxyID)
+		},
+		func(key string) {
+			existing, exists := sc.stats.InjectedFaults[key]
+			if !exists {
+				existing = &InjectedFaultStats{
+					Fault:   fault,
+					Target:  target,
+					ProxyID: proxyID,
+				}
+				sc.stats.InjectedFaults[key] = existing
+			}
+			existing.Count++
+		},
+	)
+}
+
 func (sc *inMemoryStatsCollector) TrackNoteCount(shardID string, count int) error {
 	sc.mutex.Lock()
 	defer sc.mutex.Unlock()
 
-	sc.stats.NoteCount[shardID] = count
+	sc.noteCounts[shardID] = noteCount{count: count, changedAt: time.Now()}
+	sc.mergeNoteCounts()
 	return nil
 }
 
@@ -271,19 +439,24 @@ func (sc *inMemoryStatsCollector) Export() Stats {
 
 	// Copy stats excluding internal fields
 	exported := Stats{
+		Epoch:             sc.stats.Epoch,
 		APIRequests:       make(map[string]*APIStats),
 		ProxyAccess:       make(map[string]*ProxyStats),
 		DataStoreAccess:   make(map[string]*DataStoreStats),
 		NoteCount:         make(map[string]int),
 		ConsistencyMisses: sc.stats.ConsistencyMisses,
+		MissesByCheck:     maps.Clone(sc.stats.MissesByCheck),
+		ShadowReads:       make(map[string]*ShadowReadStats),
+		InjectedFaults:    make(map[string]*InjectedFaultStats),
 	}
 
 	for k, v := range sc.stats.APIRequests {
-		// Exclude internal fields (currentCount, currentDurations)
+		// Exclude internal fields (window)
 		exportedMetrics := RequestMetrics{
 			TotalCount:     v.Metrics.TotalCount,
 			RequestsPerMin: v.Metrics.RequestsPerMin,
-			DurationP95:    v.Metrics.DurationP95,
+			Rates:          v.Metrics.Rates,
+			Latency:        v.Metrics.Latency.clone(),
 		}
 
 		exported.APIRequests[k] = &APIStats{
@@ -295,11 +468,12 @@ func (sc *inMemoryStatsCollector) Export() Stats {
 	}
 
 	for k, v := range sc.stats.ProxyAccess {
-		// Exclude internal fields (currentCount, currentDurations)
+		// Exclude internal fields (window)
 		exportedMetrics := RequestMetrics{
 			TotalCount:     v.Metrics.TotalCount,
 			RequestsPerMin: v.Metrics.RequestsPerMin,
-			DurationP95:    v.Metrics.DurationP95,
+			Rates:          v.Metrics.Rates,
+			Latency:        v.Metrics.Latency.clone(),
 		}
 
 		exported.ProxyAccess[k] = &ProxyStats{
@@ -311,11 +485,12 @@ func (sc *inMemoryStatsCollector) Export() Stats {
 	}
 
 	for k, v := range sc.stats.DataStoreAccess {
-		// Exclude internal fields (currentCount, currentDurations)
+		// Exclude internal fields (window)
 		exportedMetrics := RequestMetrics{
 			TotalCount:     v.Metrics.TotalCount,
 			RequestsPerMin: v.Metrics.RequestsPerMin,
-			DurationP95:    v.Metrics.DurationP95,
+			Rates:          v.Metrics.Rates,
+			Latency:        v.Metrics.Latency.clone(),
 		}
 
 		exported.DataStoreAccess[k] = &DataStoreStats{
@@ -330,70 +505,225 @@ func (sc *inMemoryStatsCollector) Export() Stats {
 		exported.NoteCount[k] = v
 	}
 
+	for k, v := range sc.stats.ShadowReads {
+		shadowRead := *v
+		shadowRead.RecentReads = maps.Clone(v.RecentReads)
+		exported.ShadowReads[k] = &shadowRead
+	}
+
+	for k, v := range sc.stats.InjectedFaults {
+		injectedFault := *v
+		exported.InjectedFaults[k] = &injectedFault
+	}
+
 	return exported
 }
 
-// Import merges incoming stats with existing ones
-func (sc *inMemoryStatsCollector) Import(stats Stats) {
+// Import merges a snapshot exported by another collector, identified by sourceID.
+// Snapshots are cumulative, so only the difference to the previous snapshot of the same source is added.
+// A changed epoch or shrinking counters mean the source restarted, in which case the whole snapshot is new.
+func (sc *inMemoryStatsCollector) Import(sourceID string, stats Stats) {
 	sc.mutex.Lock()
 	defer sc.mutex.Unlock()
 
+	var last Stats
+	if previous, exists := sc.sources[sourceID]; exists && previous.stats.Epoch == stats.Epoch {
+		last = previous.stats
+	}
+
 	// Merge API requests
 	for key, incoming := range stats.APIRequests {
-		if existing, exists := sc.stats.APIRequests[key]; exists {
-			// Only import the delta (new counts since last import)
-			if incoming.Metrics.TotalCount > existing.Metrics.TotalCount {
-				existing.Metrics.TotalCount = incoming.Metrics.TotalCount
-			}
-		} else {
-			// Don't include current count/durations as they're from external source
-			incoming.Metrics.currentCount = 0
-			incoming.Metrics.currentDurations = nil
-			sc.stats.APIRequests[key] = incoming
+		existing, exists := sc.stats.APIRequests[key]
+		if !exists {
+			existing = &APIStats{Method: incoming.Method, Route: incoming.Route, Status: incoming.Status}
+			sc.stats.APIRequests[key] = existing
+		}
+
+		var before *RequestMetrics
+		if previous, ok := last.APIRequests[key]; ok {
+			before = &previous.Metrics
 		}
+		existing.Metrics.addDelta(incoming.Metrics, before)
 	}
 
 	// Merge proxy access
 	for key, incoming := range stats.ProxyAccess {
-		if existing, exists := sc.stats.ProxyAccess[key]; exists {
-			// Only import the delta (new counts since last import)
-			if incoming.Metrics.TotalCount > existing.Metrics.TotalCount {
-				existing.Metrics.TotalCount = incoming.Metrics.TotalCount
-			}
-		} else {
-			incoming.Metrics.currentCount = 0
-			incoming.Metrics.currentDurations = nil
-			sc.stats.ProxyAccess[key] = incoming
+		existing, exists := sc.stats.ProxyAccess[key]
+		if !exists {
+			existing = &ProxyStats{ProxyID: incoming.ProxyID, Operation: incoming.Operation, Status: incoming.Status}
+			sc.stats.ProxyAccess[key] = existing
 		}
+
+		var before *RequestMetrics
+		if previous, ok := last.ProxyAccess[key]; ok {
+			before = &previous.Metrics
+		}
+		existing.Metrics.addDelta(incoming.Metrics, before)
 	}
 
 	// Merge data store access
 	for key, incoming := range stats.DataStoreAccess {
-		if existing, exists := sc.stats.DataStoreAccess[key]; exists {
-			// Only import the delta (new counts since last import)
-			if incoming.Metrics.TotalCount > existing.Metrics.TotalCount {
-				existing.Metrics.TotalCount = incoming.Metrics.TotalCount
+		existing, exists := sc.stats.DataStoreAccess[key]
+		if !exists {
+			existing = &DataStoreStats{StoreID: incoming.StoreID, Operation: incoming.Operation, Status: incoming.Status}
+			sc.stats.DataStoreAccess[key] = existing
+		}
+
+		var before *RequestMetrics
+		if previous, ok := last.DataStoreAccess[key]; ok {
+			before = &previous.Metrics
+		}
+		existing.Metrics.addDelta(incoming.Metrics, before)
+	}
+
+
+	// Merge consistency misses
+	sc.stats.ConsistencyMisses += counterDelta(stats.ConsistencyMisses, last.ConsistencyMisses)
+	for check, incoming := range stats.MissesByCheck {
+		sc.stats.MissesByCheck[check] += counterDelta(incoming, last.MissesByCheck[check])
+	}
+
+	// Merge shadow reads
+	for key, incoming := range stats.ShadowReads {
+		existing, exists := sc.stats.ShadowReads[key]
+		if !exists {
+			existing = &ShadowReadStats{AccountID: incoming.AccountID, Operation: incoming.Operation}
+			sc.stats.ShadowReads[key] = existing
+		}
+
+		var before ShadowReadStats
+		if previous, ok := last.ShadowReads[key]; ok {
+			before = *previous
+		}
+		existing.TotalCount += counterDelta(incoming.TotalCount, before.TotalCount)
+		existing.Mismatches += counterDelta(incoming.Mismatches, before.Mismatches)
+		for minute, count := range incoming.RecentReads {
+			if existing.RecentReads == nil {
+				existing.RecentReads = make(map[int64]int)
 			}
+			existing.RecentReads[minute] += counterDelta(count, before.RecentReads[minute])
+		}
+		existing.pruneRecentReads(time.Now())
+		if incoming.LastMismatchAt != nil && (existing.LastMismatchAt == nil || incoming.LastMismatchAt.After(*existing.LastMismatchAt)) {
+			existing.LastMismatchAt = incoming.LastMismatchAt
+		}
+	}
+
+	// Merge injected faults
+	for key, incoming := range stats.InjectedFaults {
+		existing, exists := sc.stats.InjectedFaults[key]
+		if !exists {
+			existing = &InjectedFaultStats{Fault: incoming.Fault, Target: incoming.Target, ProxyID: incoming.ProxyID}
+			sc.stats.InjectedFaults[key] = existing
+		}
+
+		var before int
+		if previous, ok := last.InjectedFaults[key]; ok {
+			before = previous.Count
+		}
+		existing.Count += counterDelta(incoming.Count, before)
+	}
+
+	// Note counts are absolute, keep them per source and only note when they change
+	now := time.Now()
+	var previousCounts map[string]noteCount
+	if previous, exists := sc.sources[sourceID]; exists {
+		previousCounts = previous.noteCounts
+	}
+	noteCounts := make(map[string]noteCount, len(stats.NoteCount))
+	for store, count := range stats.NoteCount {
+		if value, ok := previousCounts[store]; ok && value.count == count {
+			noteCounts[store] = value
 		} else {
-			incoming.Metrics.currentCount = 0
-			incoming.Metrics.currentDurations = nil
-			sc.stats.DataStoreAccess[key] = incoming
+			noteCounts[store] = noteCount{count: count, changedAt: now}
+		}
+	}
+
+	sc.sources[sourceID] = &importSource{stats: stats, lastSeen: now, noteCounts: noteCounts}
+	sc.mergeNoteCounts()
+}
+
+// mergeNoteCounts sets the note count of every store to the value that changed most recently, tracked or imported.
+// Proxies only report the stores they touched and keep reporting their last count after another proxy took over,
+// so the count of the proxy that last wrote to a store is the current one.
+func (sc *inMemoryStatsCollector) mergeNoteCounts() {
+	latest := maps.Clone(sc.noteCounts)
+	for _, source := range sc.sources {
+		for store, value := range source.noteCounts {
+			if current, ok := latest[store]; !ok || value.changedAt.After(current.changedAt) {
+				latest[store] = value
+			}
 		}
 	}
 
-	// Merge note count
 	clear(sc.stats.NoteCount)
-	for key, incoming := range stats.NoteCount {
-		sc.stats.NoteCount[key] = incoming
+	for store, value := range latest {
+		sc.stats.NoteCount[store] = value.count
 	}
+}
 
-	// Merge consistency misses
-	if stats.ConsistencyMisses > sc.stats.ConsistencyMisses {
-		sc.stats.ConsistencyMisses = stats.ConsistencyMisses
+// counterDelta returns how much a cumulative counter grew since the previous snapshot.
+// A counter below its previous value was reset, so its whole value is new.
+func counterDelta(current, previous int) int {
+	if current < previous {
+		return current
 	}
+	return current - previous
 }
 
-// tick runs periodically to calculate requests per minute and p95 duration
+// addDelta adds the growth of an imported source's metrics since its previous snapshot, which may be nil.
+// Imported requests are counted in the current tick, so rates cover all sources and decay once a source goes silent.
+func (m *RequestMetrics) addDelta(incoming RequestMetrics, before *RequestMetrics) {
+	if before == nil {
+		before = &RequestMetrics{}
+	}
+
+	latency, ok := incoming.Latency.Sub(before.Latency)
+	if !ok || incoming.TotalCount < before.TotalCount {
+		// The source was reset without changing its epoch
+		m.TotalCount += incoming.TotalCount
+		m.window.add(incoming.TotalCount)
+		m.Latency.Merge(incoming.Latency)
+		return
+	}
+
+	m.TotalCount += incoming.TotalCount - before.TotalCount
+	m.window.add(incoming.TotalCount - before.TotalCount)
+	m.Latency.Merge(latency)
+}
+
+// pruneSources forgets sources that have been silent for longer than the retention period
+func (sc *inMemoryStatsCollector) pruneSources(now time.Time) {
+	pruned := false
+	for sourceID, source := range sc.sources {
+		if now.Sub(source.lastSeen) > ImportSourceRetention {
+			delete(sc.sources, sourceID)
+			pruned = true
+		}
+	}
+
+	// Note counts of retired sources are dropped along with them
+	if pruned {
+		sc.mergeNoteCounts()
+	}
+}
+
+// allRequestMetrics returns pointers to the request metrics of all API, proxy and data store stats
+func allRequestMetrics(stats Stats) map[string]*RequestMetrics {
+	all := make(map[string]*RequestMetrics, len(stats.APIRequests)+len(stats.ProxyAccess)+len(stats.DataStoreAccess))
+	for key, s := range stats.APIRequests {
+		all["api-"+key] = &s.Metrics
+	}
+	for key, s := range stats.ProxyAccess {
+		all["proxy-"+key] = &s.Metrics
+	}
+	for key, s := range stats.DataStoreAccess {
+		all["datastore-"+key] = &s.Metrics
+	}
+	return all
+}
+
+// tick runs periodically to calculate requests per minute
 func (sc *inMemoryStatsCollector) tick() {
 	ticker := time.NewTicker(TickInterval)
 	defer ticker.Stop()
@@ -408,59 +738,18 @@ func (sc *inMemoryStatsCollector) tick() {
 	}
 }
 
-// calculateMetrics updates RPM and P95 duration for all metrics
+// calculateMetrics completes the current tick and updates the rates of all metrics
 func (sc *inMemoryStatsCollector) calculateMetrics() {
 	sc.mutex.Lock()
 	defer sc.mutex.Unlock()
 
-	// Calculate metrics for API requests
-	for _, stats := range sc.stats.APIRequests {
-		stats.Metrics.RequestsPerMin = calculateRPM(stats.Metrics.currentCount)
-		stats.Metrics.DurationP95 = calculateP95(stats.Metrics.currentDurations)
-		stats.Metrics.currentCount = 0
-		stats.Metrics.currentDurations = nil
-	}
-
-	// Calculate metrics for proxy access
-	for _, stats := range sc.stats.ProxyAccess {
-		stats.Metrics.RequestsPerMin = calculateRPM(stats.Metrics.currentCount)
-		stats.Metrics.DurationP95 = calculateP95(stats.Metrics.currentDurations)
-		stats.Metrics.currentCount = 0
-		stats.Metrics.currentDurations = nil
-	}
-
-	// Calculate metrics for data store access
-	for _, stats := range sc.stats.DataStoreAccess {
-		stats.Metrics.RequestsPerMin = calculateRPM(stats.Metrics.currentCount)
-		stats.Metrics.DurationP95 = calculateP95(stats.Metrics.currentDurations)
-		stats.Metrics.currentCount = 0
-		stats.Metrics.currentDurations = nil
-	}
-}
-
-// calculateRPM converts count per tick interval to requests per minute
-func calculateRPM(count int) int {
-	// Convert tick interval count to per-minute rate
-	ticksPerMinute := SecondsPerMinute / int(TickInterval.Seconds())
-	return count * ticksPerMinute
-}
-
-// calculateP95 calculates the 95th percentile of durations
-func calculateP95(durations []int) int {
-	if len(durations) == 0 {
-		return 0
-	}
-
-	sorted := make([]int, len(durations))
-	copy(sorted, durations)
-	sort.Ints(sorted)
-
-	index := int(float64(len(sorted)) * 0.95)
-	if index >= len(sorted) {
-		index = len(sorted) - 1
+	// Calculate rates for API requests, proxy access and data store access, including imported requests
+	for _, metrics := range allRequestMetrics(sc.stats) {
+		metrics.Rates = metrics.window.rotate()
+		metrics.RequestsPerMin = int(math.Round(metrics.Rates.OneMinute))
 	}
 
-	return sorted[index]
+	sc.pruneSources(time.Now())
 }
 
 // Stop gracefully shuts down the stats collector
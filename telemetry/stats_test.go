@@ -24,25 +24,25 @@ func (tc *testableStatsCollector) triggerCalculation() {
 }
 
 // getInternalMetrics returns internal state for testing
-func (tc *testableStatsCollector) getInternalMetrics(key string, metricType string) (currentCount int, currentDurations []int) {
+func (tc *testableStatsCollector) getInternalMetrics(key string, metricType string) (currentCount int, latency Histogram) {
 	tc.mutex.RLock()
 	defer tc.mutex.RUnlock()
 	
 	switch metricType {
 	case "api":
 		if stats, exists := tc.stats.APIRequests[key]; exists {
-			return stats.Metrics.currentCount, stats.Metrics.currentDurations
+			return stats.Metrics.window.current, stats.Metrics.Latency.clone()
 		}
 	case "proxy":
 		if stats, exists := tc.stats.ProxyAccess[key]; exists {
-			return stats.Metrics.currentCount, stats.Metrics.currentDurations
+			return stats.Metrics.window.current, stats.Metrics.Latency.clone()
 		}
 	case "datastore":
 		if stats, exists := tc.stats.DataStoreAccess[key]; exists {
-			return stats.Metrics.currentCount, stats.Metrics.currentDurations
+			return stats.Metrics.window.current, stats.Metrics.Latency.clone()
 		}
 	}
-	return 0, nil
+	return 0, Histogram{}
 }
 
 func TestRequestMetrics_EmptyState(t *testing.T) {
@@ -55,11 +55,11 @@ func TestRequestMetrics_EmptyState(t *testing.T) {
 	
 	// Verify internal state
 	key := "GET-/api/test-200"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "api")
+	currentCount, latency := collector.getInternalMetrics(key, "api")
 	
 	require.Equal(t, 1, currentCount, "Expected currentCount=1")
-	require.Len(t, currentDurations, 1, "Expected 1 duration")
-	require.Equal(t, 100, currentDurations[0], "Expected duration=100ms")
+	require.Equal(t, 1, latency.Count, "Expected 1 duration")
+	require.Equal(t, 100*time.Millisecond, latency.Max(), "Expected duration=100ms")
 	
 	// Export stats before calculation
 	stats := collector.Export()
@@ -68,7 +68,7 @@ func TestRequestMetrics_EmptyState(t *testing.T) {
 	
 	require.Equal(t, 1, apiStats.Metrics.TotalCount, "Expected TotalCount=1")
 	require.Equal(t, 0, apiStats.Metrics.RequestsPerMin, "Expected RequestsPerMin=0 before calculation")
-	require.Equal(t, 0, apiStats.Metrics.DurationP95, "Expected DurationP95=0 before calculation")
+	require.Equal(t, 100*time.Millisecond, apiStats.Metrics.Latency.P50(), "Expected latency to be available before calculation")
 }
 
 func TestRequestMetrics_WithPreviousState(t *testing.T) {
@@ -85,11 +85,11 @@ func TestRequestMetrics_WithPreviousState(t *testing.T) {
 	
 	// Verify internal state
 	key := "GET-/api/test-200"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "api")
+	currentCount, latency := collector.getInternalMetrics(key, "api")
 	
 	require.Equal(t, 2, currentCount, "Expected currentCount=2")
-	expectedDurations := []int{100, 150}
-	require.Equal(t, expectedDurations, currentDurations, "Expected durations to match")
+	require.Equal(t, 2, latency.Count, "Expected 2 durations")
+	require.Equal(t, (100+150)*time.Millisecond, latency.Sum(), "Expected durations to match")
 	
 	// Export stats
 	stats := collector.Export()
@@ -108,11 +108,11 @@ func TestProxyAccessMetrics_EmptyState(t *testing.T) {
 	
 	// Verify internal state
 	key := "CreateNote-0-1"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "proxy")
+	currentCount, latency := collector.getInternalMetrics(key, "proxy")
 	
 	require.Equal(t, 1, currentCount, "Expected currentCount=1")
-	require.Len(t, currentDurations, 1, "Expected 1 duration")
-	require.Equal(t, 50, currentDurations[0], "Expected duration=50ms")
+	require.Equal(t, 1, latency.Count, "Expected 1 duration")
+	require.Equal(t, 50*time.Millisecond, latency.Max(), "Expected duration=50ms")
 	
 	// Export stats
 	stats := collector.Export()
@@ -138,11 +138,11 @@ func TestProxyAccessMetrics_WithPreviousState(t *testing.T) {
 	
 	// Verify internal state
 	key := "GetNote-0-2"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "proxy")
+	currentCount, latency := collector.getInternalMetrics(key, "proxy")
 	
 	require.Equal(t, 2, currentCount, "Expected currentCount=2")
-	expectedDurations := []int{30, 45}
-	require.Equal(t, expectedDurations, currentDurations, "Expected durations to match")
+	require.Equal(t, 2, latency.Count, "Expected 2 durations")
+	require.Equal(t, (30+45)*time.Millisecond, latency.Sum(), "Expected durations to match")
 	
 	// Export stats
 	stats := collector.Export()
@@ -161,11 +161,11 @@ func TestDataStoreAccessMetrics_EmptyState(t *testing.T) {
 	
 	// Verify internal state
 	key := "INSERT-0-primary"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "datastore")
+	currentCount, latency := collector.getInternalMetrics(key, "datastore")
 	
 	require.Equal(t, 1, currentCount, "Expected currentCount=1")
-	require.Len(t, currentDurations, 1, "Expected 1 duration")
-	require.Equal(t, 200, currentDurations[0], "Expected duration=200ms")
+	require.Equal(t, 1, latency.Count, "Expected 1 duration")
+	require.Equal(t, 200*time.Millisecond, latency.Max(), "Expected duration=200ms")
 	
 	// Export stats
 	stats := collector.Export()
@@ -191,11 +191,11 @@ func TestDataStoreAccessMetrics_WithPreviousState(t *testing.T) {
 	
 	// Verify internal state
 	key := "SELECT-2-secondary"
-	currentCount, currentDurations := collector.getInternalMetrics(key, "datastore")
+	currentCount, latency := collector.getInternalMetrics(key, "datastore")
 	
 	require.Equal(t, 2, currentCount, "Expected currentCount=2")
-	expectedDurations := []int{80, 120}
-	require.Equal(t, expectedDurations, currentDurations, "Expected durations to match")
+	require.Equal(t, 2, latency.Count, "Expected 2 durations")
+	require.Equal(t, (80+120)*time.Millisecond, latency.Sum(), "Expected durations to match")
 	
 	// Export stats
 	stats := collector.Export()
@@ -223,19 +223,36 @@ func TestRequestsPerMinuteCalculation(t *testing.T) {
 	apiStats := stats.APIRequests[key]
 	require.NotNil(t, apiStats, "Expected API stats to be present")
 	
-	// With TickInterval = 5 seconds, 5 requests should equal 60 RPM
-	// ticksPerMinute = 60 / 5 = 12
-	// RPM = 5 * 12 = 60
-	expectedRPM := 60
-	require.Equal(t, expectedRPM, apiStats.Metrics.RequestsPerMin, "Expected RequestsPerMin=%d", expectedRPM)
+	// Until a full minute has passed, the rate is averaged over the ticks so far
+	// 5 requests in one tick of 5 seconds = 60 RPM
+	require.Equal(t, 60, apiStats.Metrics.RequestsPerMin, "Expected RequestsPerMin=60 after one tick")
+	require.InDelta(t, 60, apiStats.Metrics.Rates.FiveMinutes, 0.001)
 	
-	// Verify internal state was reset
-	currentCount, currentDurations := collector.getInternalMetrics(key, "api")
+	// Verify the current tick was completed
+	currentCount, latency := collector.getInternalMetrics(key, "api")
 	require.Equal(t, 0, currentCount, "Expected currentCount to be reset to 0")
-	require.Empty(t, currentDurations, "Expected currentDurations to be reset to empty")
+	require.Equal(t, 5, latency.Count, "Expected latency histogram to keep durations across ticks")
+	
+	// Once the minute is complete, the burst is spread over the whole window
+	for i := 0; i < 11; i++ {
+		collector.triggerCalculation()
+	}
+	apiStats = collector.Export().APIRequests[key]
+	require.Equal(t, 5, apiStats.Metrics.RequestsPerMin, "Expected a burst of 5 requests to average to 5 RPM over a minute")
+	require.InDelta(t, 5, apiStats.Metrics.Rates.FiveMinutes, 0.001)
+	require.Greater(t, apiStats.Metrics.Rates.EWMA, 0.0)
+	require.Less(t, apiStats.Metrics.Rates.EWMA, 5.0)
+	
+	// A minute later, the burst has left the one minute window but not the five minute window
+	for i := 0; i < 12; i++ {
+		collector.triggerCalculation()
+	}
+	apiStats = collector.Export().APIRequests[key]
+	require.Equal(t, 0, apiStats.Metrics.RequestsPerMin)
+	require.InDelta(t, 2.5, apiStats.Metrics.Rates.FiveMinutes, 0.001)
 }
 
-func TestP95DurationCalculation(t *testing.T) {
+func TestLatencyPercentiles(t *testing.T) {
 	collector := newTestableStatsCollector()
 	defer collector.Stop()
 	
@@ -249,20 +266,21 @@ func TestP95DurationCalculation(t *testing.T) {
 	// Trigger metric calculation
 	collector.triggerCalculation()
 	
-	// Export stats to check calculated P95
+	// Export stats to check percentiles
 	stats := collector.Export()
 	key := "UpdateNote-0-1"
 	proxyStats := stats.ProxyAccess[key]
 	require.NotNil(t, proxyStats, "Expected proxy stats to be present")
 	
-	// For 10 values, P95 index = int(10 * 0.95) = 9 (0-indexed)
-	// Sorted: [10, 20, 30, 40, 50, 60, 70, 80, 90, 100]
-	// P95 should be 100ms
-	expectedP95 := 100
-	require.Equal(t, expectedP95, proxyStats.Metrics.DurationP95, "Expected DurationP95=%d", expectedP95)
+	// Percentiles are accurate to the histogram's bucket resolution of 6.25%
+	latency := proxyStats.Metrics.Latency
+	require.InEpsilon(t, 50*time.Millisecond, latency.P50(), 0.0625, "Expected P50≈50ms")
+	require.InEpsilon(t, 90*time.Millisecond, latency.P90(), 0.0625, "Expected P90≈90ms")
+	require.Equal(t, 100*time.Millisecond, latency.P99(), "Expected P99=100ms")
+	require.Equal(t, 100*time.Millisecond, latency.Max(), "Expected Max=100ms")
 }
 
-func TestP95DurationCalculation_SmallDataset(t *testing.T) {
+func TestLatencyPercentiles_SmallDataset(t *testing.T) {
 	collector := newTestableStatsCollector()
 	defer collector.Stop()
 	
@@ -276,20 +294,19 @@ func TestP95DurationCalculation_SmallDataset(t *testing.T) {
 	// Trigger metric calculation
 	collector.triggerCalculation()
 	
-	// Export stats to check calculated P95
+	// Export stats to check percentiles
 	stats := collector.Export()
 	key := "DELETE-0-cache"
 	dsStats := stats.DataStoreAccess[key]
 	require.NotNil(t, dsStats, "Expected data store stats to be present")
 	
-	// For 2 values, P95 index = int(2 * 0.95) = 1 (0-indexed)
-	// Sorted: [10, 50]
-	// P95 should be 50ms
-	expectedP95 := 50
-	require.Equal(t, expectedP95, dsStats.Metrics.DurationP95, "Expected DurationP95=%d", expectedP95)
+	// For 2 values, P50 is the lower and P99 the higher value
+	latency := dsStats.Metrics.Latency
+	require.InEpsilon(t, 10*time.Millisecond, latency.P50(), 0.0625, "Expected P50≈10ms")
+	require.Equal(t, 50*time.Millisecond, latency.P99(), "Expected P99=50ms")
 }
 
-func TestP95DurationCalculation_EmptyDataset(t *testing.T) {
+func TestLatencyPercentiles_EmptyDataset(t *testing.T) {
 	collector := newTestableStatsCollector()
 	defer collector.Stop()
 	
@@ -301,46 +318,6 @@ func TestP95DurationCalculation_EmptyDataset(t *testing.T) {
 	require.Empty(t, stats.APIRequests, "Expected no API stats")
 }
 
-func TestCalculateRPM_EdgeCases(t *testing.T) {
-	tests := []struct {
-		name     string
-		count    int
-		expected int
-	}{
-		{"zero count", 0, 0},
-		{"single request", 1, 12}, // 1 * (60/5) = 12
-		{"high count", 100, 1200}, // 100 * (60/5) = 1200
-	}
-	
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := calculateRPM(tt.count)
-			require.Equal(t, tt.expected, result, "calculateRPM(%d)", tt.count)
-		})
-	}
-}
-
-func TestCalculateP95_EdgeCases(t *testing.T) {
-	tests := []struct {
-		name      string
-		durations []int
-		expected  int
-	}{
-		{"empty slice", []int{}, 0},
-		{"single value", []int{100}, 100},
-		{"two values", []int{10, 90}, 90},
-		{"three values", []int{10, 50, 90}, 90},
-		{"unsorted input", []int{90, 10, 50}, 90},
-	}
-	
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := calculateP95(tt.durations)
-			require.Equal(t, tt.expected, result, "calculateP95(%v)", tt.durations)
-		})
-	}
-}
-
 func TestExportExcludesInternalFields(t *testing.T) {
 	collector := newTestableStatsCollector()
 	defer collector.Stop()
@@ -359,7 +336,7 @@ func TestExportExcludesInternalFields(t *testing.T) {
 	// We can't directly access them in exported struct, but we can verify
 	// they don't affect the public fields before calculation
 	require.Equal(t, 0, apiStats.Metrics.RequestsPerMin, "Expected exported RequestsPerMin=0 before calculation")
-	require.Equal(t, 0, apiStats.Metrics.DurationP95, "Expected exported DurationP95=0 before calculation")
+	require.Equal(t, 1, apiStats.Metrics.Latency.Count, "Expected exported latency to be a copy")
 }
 
 func TestImportMergesCorrectly(t *testing.T) {
@@ -376,7 +353,7 @@ func TestImportMergesCorrectly(t *testing.T) {
 				Metrics: RequestMetrics{
 					TotalCount:     5,
 					RequestsPerMin: 60,
-					DurationP95:    150,
+					Latency:        newHistogram(150 * time.Millisecond),
 				},
 			},
 		},
@@ -388,7 +365,7 @@ func TestImportMergesCorrectly(t *testing.T) {
 				Metrics: RequestMetrics{
 					TotalCount:     3,
 					RequestsPerMin: 36,
-					DurationP95:    80,
+					Latency:        newHistogram(80 * time.Millisecond),
 				},
 			},
 		},
@@ -400,14 +377,14 @@ func TestImportMergesCorrectly(t *testing.T) {
 				Metrics: RequestMetrics{
 					TotalCount:     10,
 					RequestsPerMin: 120,
-					DurationP95:    200,
+					Latency:        newHistogram(200 * time.Millisecond),
 				},
 			},
 		},
 	}
 	
 	// Import the external stats
-	collector.Import(externalStats)
+	collector.Import("proxy-1", externalStats)
 	
 	// Verify imported stats
 	stats := collector.Export()
@@ -416,6 +393,7 @@ func TestImportMergesCorrectly(t *testing.T) {
 	apiStats := stats.APIRequests["GET-/api/test-200"]
 	require.NotNil(t, apiStats, "Expected imported API stats to be present")
 	require.Equal(t, 5, apiStats.Metrics.TotalCount, "Expected imported TotalCount=5")
+	require.Equal(t, 0, apiStats.Metrics.RequestsPerMin, "Expected imported RequestsPerMin to be ignored")
 	
 	// Check proxy access was imported
 	proxyStats := stats.ProxyAccess["GetNote-0-1"]
@@ -426,6 +404,11 @@ func TestImportMergesCorrectly(t *testing.T) {
 	dsStats := stats.DataStoreAccess["SELECT-0-primary"]
 	require.NotNil(t, dsStats, "Expected imported data store stats to be present")
 	require.Equal(t, 10, dsStats.Metrics.TotalCount, "Expected imported TotalCount=10")
+	require.Equal(t, 200*time.Millisecond, dsStats.Metrics.Latency.Max(), "Expected imported latency")
+	
+	// Importing the same snapshot again must not count its durations twice
+	collector.Import("proxy-1", externalStats)
+	require.Equal(t, 1, collector.Export().DataStoreAccess["SELECT-0-primary"].Metrics.Latency.Count, "Expected re-import to be idempotent")
 	
 	// Now add local tracking to same key and verify merge
 	err := collector.TrackAPIRequest("GET", "/api/test", 100*time.Millisecond, 200)
@@ -437,6 +420,176 @@ func TestImportMergesCorrectly(t *testing.T) {
 	require.Equal(t, 6, mergedAPIStats.Metrics.TotalCount, "Expected merged TotalCount=6 (5 imported + 1 local)")
 }
 
+func TestImportAppliesDeltasPerSource(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	snapshot := func(epoch int64, count int, durations ...time.Duration) Stats {
+		return Stats{
+			Epoch: epoch,
+			DataStoreAccess: map[string]*DataStoreStats{
+				"GetNote-0-legacy": {
+					StoreID:   "legacy",
+					Operation: "GetNote",
+					Status:    DataStoreAccessStatusSuccess,
+					Metrics: RequestMetrics{
+						TotalCount: count,
+						Latency:    newHistogram(durations...),
+					},
+				},
+			},
+		}
+	}
+	imported := func() RequestMetrics {
+		return collector.Export().DataStoreAccess["GetNote-0-legacy"].Metrics
+	}
+
+	// Two proxies report the same store, their counts add up
+	collector.Import("proxy-1", snapshot(1, 2, time.Millisecond, time.Millisecond))
+	collector.Import("proxy-2", snapshot(7, 1, 5*time.Millisecond))
+	require.Equal(t, 3, imported().TotalCount)
+	require.Equal(t, 3, imported().Latency.Count)
+
+	// A newer snapshot of the same proxy only adds what changed since
+	collector.Import("proxy-1", snapshot(1, 3, time.Millisecond, time.Millisecond, 2*time.Millisecond))
+	require.Equal(t, 4, imported().TotalCount)
+	require.Equal(t, 4, imported().Latency.Count)
+
+	// A restarted proxy reports a new epoch and starts counting from zero
+	collector.Import("proxy-1", snapshot(2, 1, 3*time.Millisecond))
+	require.Equal(t, 5, imported().TotalCount, "Expected counts after a restart to be added, not subtracted")
+	require.Equal(t, 5, imported().Latency.Count)
+
+	// Counters shrinking without a new epoch are treated as a reset as well
+	collector.Import("proxy-2", snapshot(7, 0))
+	collector.Import("proxy-2", snapshot(7, 1, 4*time.Millisecond))
+	require.Equal(t, 6, imported().TotalCount)
+
+	// Imported requests count towards the rates of the current tick
+	collector.triggerCalculation()
+	require.Equal(t, 72, imported().RequestsPerMin, "Expected 6 requests in one tick to equal 72 RPM")
+
+	// Retired sources keep their totals, their rates decay as no new requests arrive
+	for i := 0; i < 12; i++ {
+		collector.triggerCalculation()
+	}
+	require.Equal(t, 0, imported().RequestsPerMin)
+	require.Equal(t, 6, imported().TotalCount, "Expected totals of retired sources to be kept")
+
+	// Sources are forgotten after the retention period
+	collector.mutex.Lock()
+	collector.sources["proxy-2"].lastSeen = time.Now().Add(-2 * ImportSourceRetention)
+	collector.mutex.Unlock()
+	collector.triggerCalculation()
+
+	collector.mutex.RLock()
+	require.Contains(t, collector.sources, "proxy-1")
+	require.NotContains(t, collector.sources, "proxy-2")
+	collector.mutex.RUnlock()
+}
+
+func TestImportKeepsNoteCountsPerSource(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	noteCounts := func(epoch int64, counts map[string]int) Stats {
+		return Stats{Epoch: epoch, NoteCount: counts}
+	}
+
+	// During a rollout, both proxies report the stores they touched on every tick
+	collector.Import("proxy-1", noteCounts(1, map[string]int{"legacy": 10, "new": 4}))
+	collector.Import("proxy-2", noteCounts(2, map[string]int{"second": 7}))
+	collector.Import("proxy-1", noteCounts(1, map[string]int{"legacy": 10, "new": 4}))
+	require.Equal(t, map[string]int{"legacy": 10, "new": 4, "second": 7}, collector.Export().NoteCount)
+
+	// The new proxy writes to a store the previous proxy reported before, its count is the current one
+	collector.Import("proxy-2", noteCounts(2, map[string]int{"second": 7, "legacy": 11}))
+	collector.Import("proxy-1", noteCounts(1, map[string]int{"legacy": 10, "new": 4}))
+	require.Equal(t, map[string]int{"legacy": 11, "new": 4, "second": 7}, collector.Export().NoteCount)
+
+	// Counts only the retired proxy reported are dropped along with it
+	collector.mutex.Lock()
+	collector.sources["proxy-1"].lastSeen = time.Now().Add(-2 * ImportSourceRetention)
+	collector.mutex.Unlock()
+	collector.triggerCalculation()
+	require.Equal(t, map[string]int{"legacy": 11, "second": 7}, collector.Export().NoteCount)
+}
+
+func TestShadowReadTracking(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	since := time.Now().Truncate(time.Minute)
+	require.NoError(t, collector.TrackShadowRead("account-1", "GetNote", true))
+	require.NoError(t, collector.TrackShadowRead("account-1", "GetNote", false))
+	require.NoError(t, collector.TrackShadowRead("account-1", "ListNotes", true))
+
+	stats := collector.Export()
+
+	getNote := stats.ShadowReads["account-1-GetNote"]
+	require.NotNil(t, getNote, "Expected shadow reads to be tracked per account and operation")
+	require.Equal(t, 2, getNote.TotalCount)
+	require.Equal(t, 1, getNote.Mismatches)
+	require.NotNil(t, getNote.LastMismatchAt)
+	require.Equal(t, 2, getNote.ReadsSince(since))
+	require.Zero(t, getNote.ReadsSince(time.Now().Add(time.Minute)), "Reads before a minute started are not counted in it")
+
+	listNotes := stats.ShadowReads["account-1-ListNotes"]
+	require.NotNil(t, listNotes)
+	require.Equal(t, 0, listNotes.Mismatches)
+	require.Nil(t, listNotes.LastMismatchAt)
+
+	// Results imported from a proxy add to local results and keep the latest mismatch
+	later := getNote.LastMismatchAt.Add(time.Minute)
+	collector.Import("proxy-1", Stats{
+		ShadowReads: map[string]*ShadowReadStats{
+			"account-1-GetNote": {
+				AccountID:      "account-1",
+				Operation:      "GetNote",
+				TotalCount:     5,
+				Mismatches:     2,
+				LastMismatchAt: &later,
+				RecentReads:    map[int64]int{since.Unix(): 4, since.Add(-time.Minute).Unix(): 1},
+			},
+		},
+	})
+
+	merged := collector.Export().ShadowReads["account-1-GetNote"]
+	require.Equal(t, 7, merged.TotalCount)
+	require.Equal(t, 3, merged.Mismatches)
+	require.True(t, merged.LastMismatchAt.Equal(later))
+	require.Equal(t, 6, merged.ReadsSince(since))
+}
+
+func TestInjectedFaultTracking(t *testing.T) {
+	collector := newTestableStatsCollector()
+	defer collector.Stop()
+
+	require.NoError(t, collector.TrackInjectedFault("kill", "process", 1))
+	require.NoError(t, collector.TrackInjectedFault("kill", "process", 1))
+	require.NoError(t, collector.TrackInjectedFault("error", "GetNote", 1))
+
+	stats := collector.Export()
+	require.Len(t, stats.InjectedFaults, 2, "Expected faults to be tracked per fault, target and proxy")
+	require.Equal(t, 2, stats.InjectedFaults["kill-process-1"].Count)
+
+	// Faults injected by a proxy are imported as deltas of its snapshots
+	proxyStats := func(count int) Stats {
+		return Stats{
+			Epoch: 1,
+			InjectedFaults: map[string]*InjectedFaultStats{
+				"error-GetNote-1": {Fault: "error", Target: "GetNote", ProxyID: 1, Count: count},
+			},
+		}
+	}
+	collector.Import("proxy-1", proxyStats(3))
+	collector.Import("proxy-1", proxyStats(5))
+
+	merged := collector.Export().InjectedFaults["error-GetNote-1"]
+	require.Equal(t, 6, merged.Count)
+	require.Equal(t, "GetNote", merged.Target)
+}
+
 func TestNewStatsCollector_Options(t *testing.T) {
 	// Test default behavior (auto-start enabled)
 	defaultCollector := NewStatsCollector()
@@ -1,6 +1,8 @@
 package telemetry
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
@@ -13,6 +15,9 @@ import (
 type Telemetry struct {
 	LogCapture     *LogCapture
 	StatsCollector StatsCollector
+	Tracer         *Tracer
+	Recorder       *StatsRecorder
+	SLOs           *SLOEvaluator
 	Logger         *slog.Logger
 	logLevel       slog.Level
 }
@@ -38,8 +43,15 @@ type TelemetryOption func(*telemetryConfig)
 
 // telemetryConfig holds configuration options for Telemetry
 type telemetryConfig struct {
-	cliMode  bool
-	logLevel string
+	cliMode     bool
+	logLevel    string
+	serviceName string
+	traceFile   string
+	jsonLogs    bool
+	logFile     string
+	statsDir    string
+	collector   StatsCollector
+	slos        []SLO
 }
 
 // WithCLIMode configures whether telemetry should run in CLI mode
@@ -56,12 +68,62 @@ func WithLogLevel(logLevel string) TelemetryOption {
 	}
 }
 
+// WithServiceName configures the service name recorded on spans
+func WithServiceName(serviceName string) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.serviceName = serviceName
+	}
+}
+
+// WithTraceFile configures the file spans are exported to as OTLP JSON lines
+func WithTraceFile(traceFile string) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.traceFile = traceFile
+	}
+}
+
+// WithJSONLogs configures logging JSON lines to stderr, which is how data proxies pass structured logs to the API
+func WithJSONLogs(jsonLogs bool) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.jsonLogs = jsonLogs
+	}
+}
+
+// WithLogFile configures the file captured logs are persisted to as JSON lines
+func WithLogFile(logFile string) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.logFile = logFile
+	}
+}
+
+// WithStatsDir configures the directory a recording of all stats is written to
+func WithStatsDir(statsDir string) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.statsDir = statsDir
+	}
+}
+
+// WithStatsCollector replaces the in-memory stats collector, e.g. to replay recorded stats
+func WithStatsCollector(collector StatsCollector) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.collector = collector
+	}
+}
+
+// WithSLOs configures SLOs to evaluate continuously from the collected stats
+func WithSLOs(slos []SLO) TelemetryOption {
+	return func(config *telemetryConfig) {
+		config.slos = slos
+	}
+}
+
 // New creates a new telemetry instance with optional configuration
 func New(options ...TelemetryOption) *Telemetry {
 	// Default configuration
 	config := &telemetryConfig{
-		cliMode:  false, // Default to non-CLI mode
-		logLevel: "debug", // Default to debug level
+		cliMode:     false,       // Default to non-CLI mode
+		logLevel:    "debug",     // Default to debug level
+		serviceName: "notes-api", // Default to the REST API, data proxies override this
 	}
 	
 	// Apply options
@@ -70,7 +132,10 @@ func New(options ...TelemetryOption) *Telemetry {
 	}
 	
 	logCapture := NewLogCapture(constants.DefaultLogBufferSize)
-	statsCollector := NewStatsCollector()
+	statsCollector := config.collector
+	if statsCollector == nil {
+		statsCollector = NewStatsCollector()
+	}
 
 	// Determine log level
 	var level slog.Level
@@ -81,25 +146,57 @@ func New(options ...TelemetryOption) *Telemetry {
 	}
 
 	var logger *slog.Logger
-	if config.cliMode {
-		// In CLI mode, send logs to the capture system with colors (tview supports ANSI)
-		handler := tint.NewHandler(logCapture, &tint.Options{
+	if config.jsonLogs {
+		// Data proxies log JSON lines, which the API decodes into structured entries
+		handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 			Level: level,
 		})
-		logger = slog.New(handler)
+		logger = slog.New(NewContextHandler(handler))
+	} else if config.cliMode {
+		// In CLI mode, send logs to the capture system only, the TUI renders them
+		logger = slog.New(NewContextHandler(logCapture.Handler(LogSourceAPI, level)))
 	} else {
 		// In non-CLI mode, send logs to stderr with color
 		handler := tint.NewHandler(os.Stderr, &tint.Options{
 			Level: level,
 		})
-		logger = slog.New(handler)
-		// Also capture logs for telemetry display
+		logger = slog.New(NewContextHandler(MultiHandler{handler, logCapture.Handler(LogSourceAPI, level)}))
+		// Also print captured data proxy logs
 		logCapture.AddWriter(os.Stderr)
 	}
 
+	if config.logFile != "" {
+		if err := logCapture.OpenFile(config.logFile, constants.DefaultLogFileMaxBytes); err != nil {
+			logger.Warn("Failed to open log file, logs will not be persisted", "path", config.logFile, "error", err)
+		}
+	}
+
+	tracer, err := NewTracer(config.serviceName, config.traceFile)
+	if err != nil {
+		// Keep tracing in memory so the TUI still shows traces
+		logger.Warn("Failed to open trace file, traces will not be exported", "path", config.traceFile, "error", err)
+		tracer, _ = NewTracer(config.serviceName, "")
+	}
+
+	var recorder *StatsRecorder
+	if config.statsDir != "" {
+		recorder, err = NewStatsRecorder(statsCollector, config.statsDir)
+		if err != nil {
+			logger.Warn("Failed to create stats recording, stats will not be persisted", "dir", config.statsDir, "error", err)
+		}
+	}
+
+	var slos *SLOEvaluator
+	if len(config.slos) > 0 {
+		slos = NewSLOEvaluator(statsCollector, config.slos)
+	}
+
 	return &Telemetry{
 		LogCapture:     logCapture,
 		StatsCollector: statsCollector,
+		Tracer:         tracer,
+		Recorder:       recorder,
+		SLOs:           slos,
 		Logger:         logger,
 		logLevel:       level,
 	}
@@ -113,6 +210,23 @@ func (t *Telemetry) SetupGlobalLogger() {
 // Start begins background telemetry collection
 func (t *Telemetry) Start() {
 	// Stats collector now starts its ticker automatically in NewStatsCollector
+	t.Recorder.Start(TickInterval)
+	t.SLOs.Start(TickInterval)
+}
+
+// RecordEvent adds an event like a deployment to the stats recording, so it can be found when replaying
+func (t *Telemetry) RecordEvent(format string, args ...any) {
+	t.Recorder.RecordEvent(fmt.Sprintf(format, args...))
+}
+
+// Close stops evaluating SLOs, then flushes and closes the stats recording, log file and trace file
+func (t *Telemetry) Close() error {
+	t.SLOs.Stop()
+	return errors.Join(
+		t.Recorder.Close(),
+		t.LogCapture.Close(),
+		t.Tracer.Close(),
+	)
 }
 
 // GetStatsCollector returns the stats collector instance
@@ -120,6 +234,11 @@ func (t *Telemetry) GetStatsCollector() StatsCollector {
 	return t.StatsCollector
 }
 
+// GetTracer returns the tracer instance
+func (t *Telemetry) GetTracer() *Tracer {
+	return t.Tracer
+}
+
 // GetLogger returns the structured logger instance
 func (t *Telemetry) GetLogger() *slog.Logger {
 	return t.Logger
@@ -131,6 +250,7 @@ func (t *Telemetry) SwitchToStderr() {
 	handler := tint.NewHandler(os.Stderr, &tint.Options{
 		Level: t.logLevel,
 	})
-	t.Logger = slog.New(handler)
+	// Keep capturing logs so they are still persisted
+	t.Logger = slog.New(NewContextHandler(MultiHandler{handler, t.LogCapture.Handler(LogSourceAPI, t.logLevel)}))
 	slog.SetDefault(t.Logger)
 }
@@ -0,0 +1,496 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+)
+
+// TraceparentHeader carries span contexts between processes in the W3C Trace Context format
+const TraceparentHeader = "traceparent"
+
+// TraceID identifies all spans belonging to one request
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid reports whether the ID is set
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// SpanID identifies a single span within a trace
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid reports whether the ID is set
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// SpanContext is the part of a span that is propagated to child spans, including across processes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether both IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent encodes the span context as a traceparent header value
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// ParseTraceparent decodes a traceparent header value
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	var sc SpanContext
+	if err := decodeHexID(sc.TraceID[:], parts[1]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace ID in traceparent: %w", err)
+	}
+	if err := decodeHexID(sc.SpanID[:], parts[2]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span ID in traceparent: %w", err)
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	return sc, nil
+}
+
+// InjectTraceparent adds the context's active span to outgoing request headers
+func InjectTraceparent(ctx context.Context, header http.Header) {
+	if span := SpanFromContext(ctx); span != nil {
+		header.Set(TraceparentHeader, span.Context().Traceparent())
+	}
+}
+
+// ExtractTraceparent reads the span context of the caller from incoming request headers
+func ExtractTraceparent(header http.Header) (SpanContext, bool) {
+	value := header.Get(TraceparentHeader)
+	if value == "" {
+		return SpanContext{}, false
+	}
+	sc, err := ParseTraceparent(value)
+	return sc, err == nil
+}
+
+// SpanKind describes the role of a span, using the values of the OTLP enum
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// Attribute is a key-value pair describing a span
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// SpanData is a finished span
+type SpanData struct {
+	Service      string
+	Name         string
+	Kind         SpanKind
+	TraceID      TraceID
+	SpanID       SpanID
+	ParentSpanID SpanID
+	Start        time.Time
+	End          time.Time
+	Attributes   []Attribute
+	Error        string
+}
+
+// Duration returns how long the span took
+func (s SpanData) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Attribute returns the value of an attribute, or an empty string if it is not set
+func (s SpanData) Attribute(key string) string {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Span is an operation in progress. All methods are safe to call on a nil span,
+// so code can be instrumented without checking whether tracing is enabled.
+type Span struct {
+	tracer *Tracer
+	root   bool
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+type spanContextKey struct{}
+
+// SpanFromContext returns the active span of the context, if any
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// StartSpan starts a child of the context's active span.
+// Without an active span the request is not traced and the returned span is nil.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.start(ctx, name, kind, parent.Context(), false)
+}
+
+// Context returns the span context to propagate to child spans
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID}
+}
+
+// SetName renames the span, e.g. once the operation is known
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute records a key-value pair on the span
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, Attribute{Key: key, Value: fmt.Sprint(value)})
+}
+
+// SetError marks the span as failed. Nil errors are ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it. Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.export(data, s.root)
+}
+
+// traceRotationChecks is how often per size limit each process checks whether the shared trace file must be rotated
+const traceRotationChecks = 64
+
+// Tracer creates spans for one service and exports finished spans as OTLP JSON lines.
+// Recently finished spans of all processes writing to the same file are also kept in memory for the TUI.
+type Tracer struct {
+	service string
+	path    string
+
+	mu           sync.Mutex
+	file         *os.File
+	written      int64 // Bytes written since the size of the file was last checked
+	maxFileBytes int64
+	spans        []SpanData
+	maxSpans     int
+	roots        []SpanData
+	maxSize      int
+
+	readMu sync.Mutex
+	reader *traceReader // Follows spans other processes append to the file
+}
+
+// NewTracer creates a tracer for the service. Spans are appended to the file at path,
+// which may be shared by several processes. An empty path only keeps spans in memory.
+// Once the file exceeds DefaultTraceFileMaxBytes, it is moved to <path>.1, replacing the previous one.
+func NewTracer(service, path string) (*Tracer, error) {
+	t := &Tracer{
+		service:      service,
+		path:         path,
+		maxFileBytes: constants.DefaultTraceFileMaxBytes,
+		maxSpans:     constants.DefaultTraceSpanBuffer,
+		maxSize:      constants.DefaultTraceBufferSize,
+	}
+
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return nil, fmt.Errorf("could not create trace directory: %w", err)
+		}
+		file, err := openTraceFile(path)
+		if err != nil {
+			return nil, err
+		}
+		t.file = file
+
+		reader, err := newTraceReader(path)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		t.reader = reader
+	}
+
+	return t, nil
+}
+
+func openTraceFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("could not open trace file: %w", err)
+	}
+	return file, nil
+}
+
+// Path returns the file spans are exported to
+func (t *Tracer) Path() string {
+	if t == nil {
+		return ""
+	}
+	return t.path
+}
+
+// Start starts a span at a process entry point. If the caller's span context is valid,
+// the span continues its trace, otherwise a new trace is started.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, remote SpanContext) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	return t.start(ctx, name, kind, remote, true)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind SpanKind, parent SpanContext, root bool) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		root:   root,
+		data: SpanData{
+			Service:      t.service,
+			Name:         name,
+			Kind:         kind,
+			TraceID:      parent.TraceID,
+			SpanID:       newSpanID(),
+			ParentSpanID: parent.SpanID,
+			Start:        time.Now(),
+		},
+	}
+	if !parent.IsValid() {
+		span.data.TraceID = newTraceID()
+		span.data.ParentSpanID = SpanID{}
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// export writes a finished span to the trace file and keeps it in memory
+func (t *Tracer) export(span SpanData, root bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.keep(span)
+
+	if root {
+		if len(t.roots) >= t.maxSize {
+			t.roots = t.roots[1:]
+		}
+		t.roots = append(t.roots, span)
+	}
+
+	if t.file == nil {
+		return
+	}
+
+	line, err := encodeOTLPLine(t.service, []SpanData{span})
+	if err != nil {
+		return
+	}
+	// A single write per line keeps lines intact when several processes append to the same file
+	n, _ := t.file.Write(line)
+
+	t.written += int64(n)
+	if t.written >= t.maxFileBytes/traceRotationChecks {
+		t.written = 0
+		t.rotateIfFull()
+	}
+}
+
+// keep adds a span to the recent spans, dropping the oldest once the buffer is full
+func (t *Tracer) keep(span SpanData) {
+	if len(t.spans) >= t.maxSpans {
+		t.spans = t.spans[1:]
+	}
+	t.spans = append(t.spans, span)
+}
+
+// rotateIfFull moves the trace file aside once it exceeds its size limit and continues with a new file.
+// Other processes appending to the file notice it was rotated on their next check and reopen it.
+func (t *Tracer) rotateIfFull() {
+	info, err := os.Stat(t.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	current, currentErr := t.file.Stat()
+	if err == nil && currentErr == nil && os.SameFile(info, current) {
+		if info.Size() < t.maxFileBytes {
+			return
+		}
+		if err := os.Rename(t.path, t.path+".1"); err != nil {
+			return
+		}
+	}
+
+	// The file was rotated, by this or another process
+	file, err := openTraceFile(t.path)
+	if err != nil {
+		return
+	}
+	_ = t.file.Close()
+	t.file = file
+}
+
+// RecentRoots returns up to limit of the most recently finished entry point spans, newest first
+func (t *Tracer) RecentRoots(limit int) []SpanData {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]SpanData, 0, min(limit, len(t.roots)))
+	for i := len(t.roots) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, t.roots[i])
+	}
+	return result
+}
+
+// Trace returns the recent spans of a trace ordered by start time. Spans of other processes are
+// followed in the trace file, without one only spans of this process are available.
+func (t *Tracer) Trace(traceID TraceID) ([]SpanData, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	if err := t.follow(); err != nil {
+		return nil, err
+	}
+
+	var spans []SpanData
+	t.mu.Lock()
+	for _, span := range t.spans {
+		if span.TraceID == traceID {
+			spans = append(spans, span)
+		}
+	}
+	t.mu.Unlock()
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	return spans, nil
+}
+
+// follow adds the spans other processes appended to the trace file since it was last followed to the recent spans
+func (t *Tracer) follow() error {
+	if t.reader == nil {
+		return nil
+	}
+
+	t.readMu.Lock()
+	defer t.readMu.Unlock()
+
+	spans, err := t.reader.read()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range spans {
+		// Spans of this process are kept as they are exported
+		if span.Service != t.service {
+			t.keep(span)
+		}
+	}
+	return nil
+}
+
+// Close closes the trace file
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	t.readMu.Lock()
+	if t.reader != nil {
+		t.reader.close()
+	}
+	t.readMu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,165 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID()}
+
+	parsed, err := ParseTraceparent(sc.Traceparent())
+	require.NoError(t, err)
+	require.Equal(t, sc, parsed)
+
+	parsed, err = ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", parsed.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", parsed.SpanID.String())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(invalid)
+		require.Error(t, err, "traceparent %q", invalid)
+	}
+}
+
+func TestSpansPropagateAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	api, err := NewTracer("notes-api", path)
+	require.NoError(t, err)
+	defer api.Close()
+
+	dataProxy, err := NewTracer("data-proxy-1", path)
+	require.NoError(t, err)
+	defer dataProxy.Close()
+
+	// The API handles a request and calls the data proxy
+	ctx, root := api.Start(context.Background(), "GET /accounts/{id}", SpanKindServer, SpanContext{})
+	root.SetAttribute("http.status_code", 200)
+
+	rpcCtx, rpc := StartSpan(ctx, "rpc GetNote", SpanKindClient)
+	header := http.Header{}
+	InjectTraceparent(rpcCtx, header)
+
+	// The data proxy continues the trace from the request headers
+	remote, ok := ExtractTraceparent(header)
+	require.True(t, ok)
+	proxyCtx, server := dataProxy.Start(context.Background(), "GetNote", SpanKindServer, remote)
+	_, query := StartSpan(proxyCtx, "sqlite GetNote", SpanKindClient)
+	query.SetError(errors.New("database is locked"))
+	query.End()
+	server.End()
+
+	rpc.End()
+	root.End()
+	root.End() // Ending twice does not export the span again
+
+	require.Equal(t, root.Context().TraceID, server.Context().TraceID)
+
+	roots := api.RecentRoots(10)
+	require.Len(t, roots, 1)
+	require.Equal(t, "GET /accounts/{id}", roots[0].Name)
+	require.Equal(t, "200", roots[0].Attribute("http.status_code"))
+
+	// Spans of both processes are read back from the shared file
+	spans, err := api.Trace(root.Context().TraceID)
+	require.NoError(t, err)
+	require.Len(t, spans, 4)
+
+	byName := make(map[string]SpanData)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	require.False(t, byName["GET /accounts/{id}"].ParentSpanID.IsValid())
+	require.Equal(t, byName["GET /accounts/{id}"].SpanID, byName["rpc GetNote"].ParentSpanID)
+	require.Equal(t, byName["rpc GetNote"].SpanID, byName["GetNote"].ParentSpanID)
+	require.Equal(t, byName["GetNote"].SpanID, byName["sqlite GetNote"].ParentSpanID)
+	require.Equal(t, "data-proxy-1", byName["sqlite GetNote"].Service)
+	require.Equal(t, "database is locked", byName["sqlite GetNote"].Error)
+}
+
+func TestUntracedContextsCreateNoSpans(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "orphan", SpanKindInternal)
+	require.Nil(t, span)
+	require.Nil(t, SpanFromContext(ctx))
+
+	// Nil spans and tracers are safe to use
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("failed"))
+	span.End()
+
+	var tracer *Tracer
+	_, span = tracer.Start(context.Background(), "disabled", SpanKindServer, SpanContext{})
+	require.Nil(t, span)
+	require.NoError(t, tracer.Close())
+
+	header := http.Header{}
+	InjectTraceparent(ctx, header)
+	require.Empty(t, header.Get(TraceparentHeader))
+}
+
+func TestInMemoryTracer(t *testing.T) {
+	tracer, err := NewTracer("notes-api", "")
+	require.NoError(t, err)
+
+	ctx, root := tracer.Start(context.Background(), "POST /accounts", SpanKindServer, SpanContext{})
+	_, child := StartSpan(ctx, "validateAccount", SpanKindInternal)
+	child.End()
+	root.End()
+
+	spans, err := tracer.Trace(root.Context().TraceID)
+	require.NoError(t, err)
+	require.Len(t, spans, 2)
+	require.Equal(t, "POST /accounts", spans[0].Name, "spans are ordered by start time")
+}
+
+func TestTraceFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	api, err := NewTracer("notes-api", path)
+	require.NoError(t, err)
+	defer api.Close()
+
+	dataProxy, err := NewTracer("data-proxy-1", path)
+	require.NoError(t, err)
+	defer dataProxy.Close()
+	dataProxy.maxFileBytes = 4096
+
+	var last SpanContext
+	for range 100 {
+		_, span := dataProxy.Start(context.Background(), "GetNote", SpanKindServer, SpanContext{})
+		span.End()
+		last = span.Context()
+
+		// Following the file while it is rotated picks up spans from the new file
+		_, err := api.Trace(last.TraceID)
+		require.NoError(t, err)
+	}
+
+	// The file is rotated once it exceeds its limit, keeping one previous file
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Less(t, info.Size(), int64(2*4096))
+	_, err = os.Stat(path + ".1")
+	require.NoError(t, err)
+
+	spans, err := api.Trace(last.TraceID)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	require.Equal(t, "data-proxy-1", spans[0].Service)
+}
@@ -0,0 +1,99 @@
+package util
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+)
+
+// Rand is a seeded random number generator that is safe for concurrent use.
+// Generators with the same seed and stream draw the same sequence of numbers.
+type Rand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	// Cumulative Zipf weights of the largest n drawn for an exponent, the weights of smaller n are a prefix of them
+	zipfCDF []float64
+	zipfS   float64
+}
+
+// NewRand returns a generator for one stream of a seed. Every component of a run uses its own
+// stream, e.g. "account 3" or "proxy 2", so components do not draw the same numbers and one
+// component drawing more numbers does not change the sequence of another.
+func NewRand(seed int64, stream string) *Rand {
+	h := fnv.New64a()
+	h.Write([]byte(stream))
+	return &Rand{rng: rand.New(rand.NewPCG(uint64(seed), h.Sum64()))}
+}
+
+// IntN returns a number in [0, n). It panics if n <= 0.
+func (r *Rand) IntN(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.IntN(n)
+}
+
+// Float64 returns a number in [0.0, 1.0)
+func (r *Rand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+// ExpFloat64 returns an exponentially distributed number with mean 1, e.g. to space Poisson arrivals
+func (r *Rand) ExpFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.ExpFloat64()
+}
+
+// NormFloat64 returns a normally distributed number with mean 0 and standard deviation 1
+func (r *Rand) NormFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.NormFloat64()
+}
+
+// Zipf returns a rank in [0, n), drawing rank k with a probability proportional to 1/(k+1)^s.
+// An exponent of 0 draws all ranks equally, larger exponents favor the first ranks. It panics if n <= 0.
+func (r *Rand) Zipf(n int, s float64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.zipfCDF) < n || r.zipfS != s {
+		cdf := ZipfWeights(n, s)
+		for i := 1; i < n; i++ {
+			cdf[i] += cdf[i-1]
+		}
+		r.zipfCDF, r.zipfS = cdf, s
+	}
+
+	cdf := r.zipfCDF[:n]
+	target := r.rng.Float64() * cdf[n-1]
+	return min(sort.SearchFloat64s(cdf, target), n-1)
+}
+
+// ZipfWeights returns the weight 1/(k+1)^s of every rank k in [0, n)
+func ZipfWeights(n int, s float64) []float64 {
+	weights := make([]float64, n)
+	for k := range weights {
+		weights[k] = 1 / math.Pow(float64(k+1), s)
+	}
+	return weights
+}
+
+// Read fills p with random bytes, so seeded UUIDs can be created with uuid.NewRandomFromReader
+func (r *Rand) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buf [8]byte
+	for i := 0; i < len(p); i += len(buf) {
+		binary.LittleEndian.PutUint64(buf[:], r.rng.Uint64())
+		copy(p[i:], buf[:])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRandIsReproducible(t *testing.T) {
+	draw := func(seed int64, stream string) []int {
+		r := NewRand(seed, stream)
+		numbers := make([]int, 20)
+		for i := range numbers {
+			numbers[i] = r.IntN(1000)
+		}
+		return numbers
+	}
+
+	first := draw(42, "account 1")
+	if second := draw(42, "account 1"); !slices.Equal(first, second) {
+		t.Errorf("same seed and stream should draw the same numbers, got %v and %v", first, second)
+	}
+	if other := draw(42, "account 2"); slices.Equal(first, other) {
+		t.Errorf("different streams should draw different numbers, both drew %v", first)
+	}
+	if other := draw(43, "account 1"); slices.Equal(first, other) {
+		t.Errorf("different seeds should draw different numbers, both drew %v", first)
+	}
+}
+
+func TestRandRead(t *testing.T) {
+	a := make([]byte, 19)
+	b := make([]byte, 19)
+	if _, err := NewRand(7, "ids").Read(a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewRand(7, "ids").Read(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("same seed and stream should read the same bytes, got %x and %x", a, b)
+	}
+	if a[18] == 0 && a[17] == 0 && a[16] == 0 {
+		t.Errorf("bytes past the last full word should be filled, got %x", a)
+	}
+}
+
+func TestRandZipf(t *testing.T) {
+	r := NewRand(1, "zipf")
+
+	counts := make([]int, 10)
+	for range 10000 {
+		counts[r.Zipf(len(counts), 1.2)]++
+	}
+	if counts[0] <= counts[1] || counts[1] <= counts[9] {
+		t.Errorf("lower ranks should be drawn more often, got %v", counts)
+	}
+
+	uniform := make([]int, 4)
+	for range 10000 {
+		uniform[r.Zipf(len(uniform), 0)]++
+	}
+	for rank, count := range uniform {
+		if count < 2200 || count > 2800 {
+			t.Errorf("an exponent of 0 should draw all ranks equally, rank %d was drawn %d times: %v", rank, count, uniform)
+		}
+	}
+
+	if rank := r.Zipf(1, 2); rank != 0 {
+		t.Errorf("a single rank should always be drawn, got %d", rank)
+	}
+}
+
+func TestRandZipfCache(t *testing.T) {
+	cached := NewRand(3, "zipf")
+	fresh := NewRand(3, "zipf")
+
+	// Draws for fewer ranks or another exponent reuse or replace the cached weights without changing the draws
+	draws := []struct {
+		n int
+		s float64
+	}{{100, 1.1}, {10, 1.1}, {200, 1.1}, {10, 0.5}, {50, 1.1}}
+	for _, draw := range draws {
+		fresh.zipfCDF = nil
+		want := fresh.Zipf(draw.n, draw.s)
+		if got := cached.Zipf(draw.n, draw.s); got != want {
+			t.Errorf("Zipf(%d, %v) drew %d with cached weights, want %d", draw.n, draw.s, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// findingKinds lists all finding kinds in the order they are summarized
+var findingKinds = []FindingKind{
+	FindingDuplicate,
+	FindingWrongShard,
+	FindingDivergence,
+	FindingOrphan,
+	FindingMissing,
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("could not encode report: %w", err)
+	}
+	return nil
+}
+
+// WriteText writes a human-readable summary of the report
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Consistency report generated at %s\n\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Accounts: %d\n", r.Accounts)
+	for _, s := range r.Stores {
+		if s.Missing {
+			fmt.Fprintf(&b, "Store %-8s missing\n", s.ID)
+			continue
+		}
+		fmt.Fprintf(&b, "Store %-8s %d notes\n", s.ID, s.Notes)
+	}
+	fmt.Fprintf(&b, "Notes in more than one store: %d\n\n", r.SharedNotes)
+
+	if r.OK() {
+		b.WriteString("No inconsistencies found.\n")
+	} else {
+		fmt.Fprintf(&b, "%d inconsistencies found:\n", len(r.Findings))
+		for _, kind := range findingKinds {
+			if r.Counts[kind] > 0 {
+				fmt.Fprintf(&b, "  %-12s %d\n", kind, r.Counts[kind])
+			}
+		}
+		b.WriteString("\n")
+
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tACCOUNT\tNOTE\tSTORES\tDETAIL")
+		for _, f := range r.Findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Kind, f.AccountID, f.NoteID, strings.Join(f.Stores, ","), f.Detail)
+		}
+		tw.Flush()
+	}
+
+	if len(r.Repair) > 0 {
+		fmt.Fprintf(&b, "\nRepair plan (%d actions, not applied):\n", len(r.Repair))
+		for i, a := range r.Repair {
+			switch a.Operation {
+			case RepairCopy:
+				fmt.Fprintf(&b, "  %3d. copy note %s from %s to %s (%s)\n", i+1, a.NoteID, a.Source, a.Target, a.Reason)
+			case RepairDelete:
+				fmt.Fprintf(&b, "  %3d. delete note %s from %s (%s)\n", i+1, a.NoteID, a.Target, a.Reason)
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+)
+
+// FindingKind classifies an inconsistency between the note stores
+type FindingKind string
+
+const (
+	// FindingDuplicate is a note present in more stores than its account's migration state allows
+	FindingDuplicate FindingKind = "duplicate"
+	// FindingWrongShard is a note stored in a shard other than its account's target shard
+	FindingWrongShard FindingKind = "wrong_shard"
+	// FindingDivergence is a note whose copies differ in content or timestamps
+	FindingDivergence FindingKind = "divergence"
+	// FindingOrphan is a note whose account no longer exists
+	FindingOrphan FindingKind = "orphan"
+	// FindingMissing is a note absent from a store its account's migration state requires
+	FindingMissing FindingKind = "missing"
+)
+
+// Finding describes a single inconsistency of a note
+type Finding struct {
+	Kind           FindingKind `json:"kind"`
+	AccountID      uuid.UUID   `json:"accountId"`
+	NoteID         uuid.UUID   `json:"noteId"`
+	Stores         []string    `json:"stores"`
+	ExpectedStores []string    `json:"expectedStores,omitempty"`
+	Detail         string      `json:"detail"`
+}
+
+// RepairOperation is the kind of change a repair action makes
+type RepairOperation string
+
+const (
+	RepairCopy   RepairOperation = "copy"
+	RepairDelete RepairOperation = "delete"
+)
+
+// RepairAction is a single step of a repair plan.
+// Copies overwrite the target with the source, deletes remove the note from the target.
+type RepairAction struct {
+	Operation RepairOperation `json:"operation"`
+	AccountID uuid.UUID       `json:"accountId"`
+	NoteID    uuid.UUID       `json:"noteId"`
+	Source    string          `json:"source,omitempty"`
+	Target    string          `json:"target"`
+	Reason    FindingKind     `json:"reason"`
+}
+
+// StoreSummary describes a note store as seen by the verifier
+type StoreSummary struct {
+	ID      string `json:"id"`
+	Notes   int    `json:"notes"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// Report is the result of a verification run
+type Report struct {
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Accounts    int                 `json:"accounts"`
+	Stores      []StoreSummary      `json:"stores"`
+	SharedNotes int                 `json:"sharedNotes"` // Notes present in more than one store, expected while migrating
+	Counts      map[FindingKind]int `json:"counts"`
+	Findings    []Finding           `json:"findings"`
+	Repair      []RepairAction      `json:"repair,omitempty"`
+}
+
+// OK reports whether no inconsistencies were found
+func (r *Report) OK() bool {
+	return len(r.Findings) == 0
+}
+
+// Options configures a verification run
+type Options struct {
+	// BasePath is the directory containing the .data directory, defaulting to the working directory
+	BasePath string
+	// Repair includes a repair plan in the report. The plan is never applied.
+	Repair bool
+	Logger *slog.Logger
+}
+
+// noteStoreIDs lists all note stores in the order they are reported
+func noteStoreIDs() []string {
+	return append([]string{constants.LegacyNoteStore}, constants.Shards...)
+}
+
+// Run opens the accounts database and all note stores read-only and diffs them
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	accountStore, err := store.NewAccountStore(readOnlyOptions(constants.AccountStore, opts.BasePath, logger))
+	if err != nil {
+		return nil, fmt.Errorf("could not open account store: %w", err)
+	}
+	defer accountStore.Close()
+
+	accounts, err := accountStore.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list accounts: %w", err)
+	}
+
+	accountsByID := make(map[uuid.UUID]*store.Account, len(accounts))
+	for i := range accounts {
+		accountsByID[accounts[i].ID] = &accounts[i]
+	}
+
+	report := &Report{
+		GeneratedAt: time.Now(),
+		Accounts:    len(accounts),
+		Counts:      make(map[FindingKind]int),
+		Findings:    []Finding{},
+	}
+
+	// Collect every copy of every note, keyed by note ID
+	copies := make(map[uuid.UUID]map[string]store.Note)
+	for _, storeID := range noteStoreIDs() {
+		summary, err := scanStore(ctx, storeID, opts.BasePath, logger, copies)
+		if err != nil {
+			return nil, err
+		}
+		report.Stores = append(report.Stores, summary)
+	}
+
+	noteIDs := make([]uuid.UUID, 0, len(copies))
+	for noteID := range copies {
+		noteIDs = append(noteIDs, noteID)
+	}
+	sort.Slice(noteIDs, func(i, j int) bool { return noteIDs[i].String() < noteIDs[j].String() })
+
+	for _, noteID := range noteIDs {
+		noteCopies := copies[noteID]
+		if len(noteCopies) > 1 {
+			report.SharedNotes++
+		}
+		checkNote(report, noteID, noteCopies, accountsByID, opts.Repair)
+	}
+
+	return report, nil
+}
+
+// readOnlyOptions returns store options opening an existing database without modifying it
+func readOnlyOptions(name, basePath string, logger *slog.Logger) store.StoreOptions {
+	opts := store.DefaultStoreOptions(name, logger)
+	opts.BasePath = basePath
+	opts.Config.ReadOnly = true
+	return opts
+}
+
+// scanStore adds all notes of a store to copies. Missing databases are reported, not treated as errors.
+func scanStore(ctx context.Context, storeID, basePath string, logger *slog.Logger, copies map[uuid.UUID]map[string]store.Note) (StoreSummary, error) {
+	summary := StoreSummary{ID: storeID}
+
+	noteStore, err := store.NewNoteStore(readOnlyOptions(storeID, basePath, logger))
+	if err != nil {
+		if errors.Is(err, store.ErrDatabaseNotFound) {
+			summary.Missing = true
+			return summary, nil
+		}
+		return summary, fmt.Errorf("could not open note store %q: %w", storeID, err)
+	}
+	defer noteStore.Close()
+
+	scanner, ok := noteStore.(store.NoteScanner)
+	if !ok {
+		return summary, fmt.Errorf("note store %q cannot be scanned", storeID)
+	}
+
+	err = scanner.ScanNotes(ctx, func(note store.Note) error {
+		summary.Notes++
+		if copies[note.ID] == nil {
+			copies[note.ID] = make(map[string]store.Note)
+		}
+		copies[note.ID][storeID] = note
+		return nil
+	})
+	if err != nil {
+		return summary, fmt.Errorf("could not scan note store %q: %w", storeID, err)
+	}
+
+	return summary, nil
+}
+
+// targetShard returns the shard an account's notes are migrated to
+func targetShard(account *store.Account) string {
+	if account.Shard != nil && *account.Shard != "" {
+		return *account.Shard
+	}
+	return constants.NewNoteStore
+}
+
+// expectedStores returns the stores a note must be in and the stores it may be in,
+// given the migration state and target shard of its account
+func expectedStores(account *store.Account) (required []string, allowed []string) {
+	shard := targetShard(account)
+
+	switch account.MigrationState.Normalize() {
+	case store.MigrationStateDualWrite, store.MigrationStateBackfilling, store.MigrationStateRollingBack:
+		// Shard copies are still being created or removed
+		return []string{constants.LegacyNoteStore}, []string{constants.LegacyNoteStore, shard}
+	case store.MigrationStateVerifying, store.MigrationStateReadFromNew:
+		return []string{constants.LegacyNoteStore, shard}, []string{constants.LegacyNoteStore, shard}
+	case store.MigrationStateCleanup:
+		// Legacy copies are still being removed
+		return []string{shard}, []string{constants.LegacyNoteStore, shard}
+	case store.MigrationStateDone:
+		return []string{shard}, []string{shard}
+	default:
+		return []string{constants.LegacyNoteStore}, []string{constants.LegacyNoteStore}
+	}
+}
+
+// sourceOfTruth returns the store whose copy of a note wins when repairing
+func sourceOfTruth(account *store.Account, noteCopies map[string]store.Note) string {
+	required, _ := expectedStores(account)
+	preferred := constants.LegacyNoteStore
+	if account.MigrationState.ReadsShard() {
+		preferred = targetShard(account)
+	}
+
+	if _, ok := noteCopies[preferred]; ok {
+		return preferred
+	}
+	for _, storeID := range required {
+		if _, ok := noteCopies[storeID]; ok {
+			return storeID
+		}
+	}
+
+	// Fall back to the most recently updated copy
+	var latest string
+	for _, storeID := range noteStoreIDs() {
+		note, ok := noteCopies[storeID]
+		if !ok {
+			continue
+		}
+		if latest == "" || note.UpdatedAt.After(noteCopies[latest].UpdatedAt) {
+			latest = storeID
+		}
+	}
+	return latest
+}
+
+// checkNote records all findings and repair actions for the copies of a single note
+func checkNote(report *Report, noteID uuid.UUID, noteCopies map[string]store.Note, accountsByID map[uuid.UUID]*store.Account, repair bool) {
+	var stores []string
+	for _, storeID := range noteStoreIDs() {
+		if _, ok := noteCopies[storeID]; ok {
+			stores = append(stores, storeID)
+		}
+	}
+	accountID := noteCopies[stores[0]].Creator
+
+	addFinding := func(kind FindingKind, expected []string, detail string) {
+		report.Counts[kind]++
+		report.Findings = append(report.Findings, Finding{
+			Kind:           kind,
+			AccountID:      accountID,
+			NoteID:         noteID,
+			Stores:         stores,
+			ExpectedStores: expected,
+			Detail:         detail,
+		})
+	}
+	addRepair := func(operation RepairOperation, source, target string, reason FindingKind) {
+		if !repair {
+			return
+		}
+		report.Repair = append(report.Repair, RepairAction{
+			Operation: operation,
+			AccountID: accountID,
+			NoteID:    noteID,
+			Source:    source,
+			Target:    target,
+			Reason:    reason,
+		})
+	}
+
+	account, ok := accountsByID[accountID]
+	if !ok {
+		addFinding(FindingOrphan, nil, "account does not exist")
+		for _, storeID := range stores {
+			addRepair(RepairDelete, "", storeID, FindingOrphan)
+		}
+		return
+	}
+
+	required, allowed := expectedStores(account)
+	source := sourceOfTruth(account, noteCopies)
+
+	// Copies in stores the migration state does not allow
+	var unexpected []string
+	for _, storeID := range stores {
+		if slices.Contains(allowed, storeID) {
+			continue
+		}
+		unexpected = append(unexpected, storeID)
+
+		if storeID != constants.LegacyNoteStore && storeID != targetShard(account) {
+			addFinding(FindingWrongShard, allowed, fmt.Sprintf("stored in %s, account shard is %s", storeID, targetShard(account)))
+		}
+	}
+
+	if len(stores) > 1 && len(unexpected) > 0 {
+		addFinding(FindingDuplicate, allowed, fmt.Sprintf("present in %d stores, account is %s", len(stores), account.MigrationState.Normalize()))
+	}
+
+	// Copies absent from required stores
+	for _, storeID := range required {
+		if _, ok := noteCopies[storeID]; ok {
+			continue
+		}
+		addFinding(FindingMissing, required, fmt.Sprintf("not in %s, account is %s", storeID, account.MigrationState.Normalize()))
+		addRepair(RepairCopy, source, storeID, FindingMissing)
+	}
+
+	for _, storeID := range unexpected {
+		reason := FindingDuplicate
+		if storeID != constants.LegacyNoteStore && storeID != targetShard(account) {
+			reason = FindingWrongShard
+		}
+		addRepair(RepairDelete, "", storeID, reason)
+	}
+
+	// Copies that diverge from the source of truth
+	expected := noteCopies[source]
+	for _, storeID := range stores {
+		if storeID == source || slices.Contains(unexpected, storeID) {
+			continue
+		}
+
+		actual := noteCopies[storeID]
+		if actual.Content == expected.Content && actual.CreatedAt.Equal(expected.CreatedAt) && actual.UpdatedAt.Equal(expected.UpdatedAt) {
+			continue
+		}
+
+		addFinding(FindingDivergence, nil, divergenceDetail(source, expected, storeID, actual))
+		addRepair(RepairCopy, source, storeID, FindingDivergence)
+	}
+}
+
+// divergenceDetail describes how two copies of a note differ
+func divergenceDetail(sourceID string, source store.Note, storeID string, actual store.Note) string {
+	switch {
+	case actual.Content != source.Content:
+		return fmt.Sprintf("content differs between %s and %s", sourceID, storeID)
+	case !actual.UpdatedAt.Equal(source.UpdatedAt):
+		return fmt.Sprintf("updatedAt %s in %s, %s in %s", source.UpdatedAt.Format(time.RFC3339Nano), sourceID, actual.UpdatedAt.Format(time.RFC3339Nano), storeID)
+	default:
+		return fmt.Sprintf("createdAt %s in %s, %s in %s", source.CreatedAt.Format(time.RFC3339Nano), sourceID, actual.CreatedAt.Format(time.RFC3339Nano), storeID)
+	}
+}
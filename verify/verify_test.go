@@ -0,0 +1,136 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoscheufler/gopherconuk25/constants"
+	"github.com/brunoscheufler/gopherconuk25/store"
+)
+
+func TestRunReportsInconsistencies(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	logger := slog.New(slog.DiscardHandler)
+
+	openStore := func(name string) store.StoreOptions {
+		opts := store.DefaultStoreOptions(name, logger)
+		opts.BasePath = basePath
+		return opts
+	}
+
+	accountStore, err := store.NewAccountStore(openStore(constants.AccountStore))
+	require.NoError(t, err)
+	legacy, err := store.NewNoteStore(openStore(constants.LegacyNoteStore))
+	require.NoError(t, err)
+	shard, err := store.NewNoteStore(openStore(constants.NewNoteStore))
+	require.NoError(t, err)
+	// The second shard is never created and must be reported as missing
+
+	now := time.Now().Truncate(time.Millisecond)
+	newNote := func(creator uuid.UUID, content string) store.Note {
+		return store.Note{ID: uuid.New(), Creator: creator, CreatedAt: now, UpdatedAt: now, Content: content}
+	}
+
+	// Legacy account with a consistent note and a stray copy in its shard
+	legacyAccount := store.Account{ID: uuid.New(), Name: "legacy"}
+	require.NoError(t, accountStore.CreateAccount(ctx, legacyAccount))
+	consistent := newNote(legacyAccount.ID, "consistent")
+	require.NoError(t, legacy.CreateNote(ctx, legacyAccount.ID, consistent))
+	stray := newNote(legacyAccount.ID, "stray")
+	require.NoError(t, legacy.CreateNote(ctx, legacyAccount.ID, stray))
+	require.NoError(t, shard.CreateNote(ctx, legacyAccount.ID, stray))
+
+	// Verifying account with a divergent copy and a note missing from its shard
+	second := constants.SecondShardStore
+	verifyingAccount := store.Account{ID: uuid.New(), Name: "verifying", MigrationState: store.MigrationStateVerifying}
+	require.NoError(t, accountStore.CreateAccount(ctx, verifyingAccount))
+	divergent := newNote(verifyingAccount.ID, "legacy content")
+	require.NoError(t, legacy.CreateNote(ctx, verifyingAccount.ID, divergent))
+	divergentCopy := divergent
+	divergentCopy.Content = "shard content"
+	require.NoError(t, shard.CreateNote(ctx, verifyingAccount.ID, divergentCopy))
+	missing := newNote(verifyingAccount.ID, "missing")
+	require.NoError(t, legacy.CreateNote(ctx, verifyingAccount.ID, missing))
+
+	// Migrated account whose note landed in a shard other than its own
+	doneAccount := store.Account{ID: uuid.New(), Name: "done", Shard: &second, MigrationState: store.MigrationStateDone}
+	require.NoError(t, accountStore.CreateAccount(ctx, doneAccount))
+	wrongShard := newNote(doneAccount.ID, "wrong shard")
+	require.NoError(t, shard.CreateNote(ctx, doneAccount.ID, wrongShard))
+
+	// Note of an account that no longer exists
+	orphan := newNote(uuid.New(), "orphan")
+	require.NoError(t, legacy.CreateNote(ctx, orphan.Creator, orphan))
+
+	require.NoError(t, accountStore.Close())
+	require.NoError(t, legacy.Close())
+	require.NoError(t, shard.Close())
+
+	report, err := Run(ctx, Options{BasePath: basePath, Repair: true, Logger: logger})
+	require.NoError(t, err)
+	require.False(t, report.OK())
+
+	require.Equal(t, 3, report.Accounts)
+	require.Equal(t, []StoreSummary{
+		{ID: constants.LegacyNoteStore, Notes: 5},
+		{ID: constants.NewNoteStore, Notes: 3},
+		{ID: constants.SecondShardStore, Missing: true},
+	}, report.Stores)
+	require.Equal(t, 2, report.SharedNotes)
+
+	findingsByNote := make(map[uuid.UUID][]FindingKind)
+	for _, f := range report.Findings {
+		findingsByNote[f.NoteID] = append(findingsByNote[f.NoteID], f.Kind)
+	}
+	require.NotContains(t, findingsByNote, consistent.ID)
+	require.Equal(t, []FindingKind{FindingDuplicate}, findingsByNote[stray.ID])
+	require.Equal(t, []FindingKind{FindingDivergence}, findingsByNote[divergent.ID])
+	require.Equal(t, []FindingKind{FindingMissing}, findingsByNote[missing.ID])
+	require.Equal(t, []FindingKind{FindingWrongShard, FindingMissing}, findingsByNote[wrongShard.ID])
+	require.Equal(t, []FindingKind{FindingOrphan}, findingsByNote[orphan.ID])
+
+	// The repair plan restores the wrong-shard note to the account's shard before removing the stray copy
+	var wrongShardRepair []RepairAction
+	for _, a := range report.Repair {
+		if a.NoteID == wrongShard.ID {
+			wrongShardRepair = append(wrongShardRepair, a)
+		}
+	}
+	require.Equal(t, []RepairAction{
+		{Operation: RepairCopy, AccountID: doneAccount.ID, NoteID: wrongShard.ID, Source: constants.NewNoteStore, Target: constants.SecondShardStore, Reason: FindingMissing},
+		{Operation: RepairDelete, AccountID: doneAccount.ID, NoteID: wrongShard.ID, Target: constants.NewNoteStore, Reason: FindingWrongShard},
+	}, wrongShardRepair)
+
+	// Legacy is the source of truth until reads are served from the shard
+	for _, a := range report.Repair {
+		if a.NoteID == divergent.ID {
+			require.Equal(t, RepairCopy, a.Operation)
+			require.Equal(t, constants.LegacyNoteStore, a.Source)
+			require.Equal(t, constants.NewNoteStore, a.Target)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded.Findings, len(report.Findings))
+
+	buf.Reset()
+	require.NoError(t, report.WriteText(&buf))
+	require.Contains(t, buf.String(), "inconsistencies found")
+	require.Contains(t, buf.String(), "Repair plan")
+}
+
+func TestRunRequiresAccountStore(t *testing.T) {
+	_, err := Run(context.Background(), Options{BasePath: t.TempDir()})
+	require.ErrorIs(t, err, store.ErrDatabaseNotFound)
+}